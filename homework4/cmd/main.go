@@ -1,59 +1,12 @@
 package main
 
 import (
-	"fmt"
-	"homework4/config"
-	"homework4/internal/api/route"
-	"homework4/internal/app/mysql"
-	"homework4/internal/app/redis"
-	"homework4/internal/common"
-	"homework4/pkg/logger"
-	"time"
-
-	_ "homework4/docs"
-	_ "homework4/internal/controller"
+	"homework4/internal/cli"
+	"os"
 )
 
-// @title Blog API
-// @version 1.0
-// @description 这是一个博客API系统,包含用户注册登录、文章管理、评论管理等功能
-// @termsOfService http://swagger.io/terms/
-
-// @contact.name API Support
-// @contact.url http://www.swagger.io/support
-// @contact.email support@swagger.io
-
-// @license.name Apache 2.0
-// @license.url http://www.apache.org/licenses/LICENSE-2.0.html
-
-// @host 127.0.0.1:9527
-// @BasePath /api/v1
-// @securityDefinitions.apikey Bearer
-// @in header
-// @name Authorization
-// @description 请输入JWT token,格式为Bearer {token}
+// 接口文档见internal/api/openapi/openapi.yaml 启动后访问/api/v1/openapi.json
 func main() {
-	// 初始化日志
-	logger.InitAppLog(
-		// logger.WithDisableConsole(),
-		logger.WithTimeLayout(time.DateTime),
-		logger.WithFileP(common.GetLogFile()),
-	)
-	//初始化配置
-	config.LoadConfig()
-
-	//初始化数据库
-	mysql.InitDB()
-	//初始化redis
-	redis.InitRedis()
-
-	//初始化路由
-	r := route.InitRoutes()
-
-	// 启动服务
-	port := fmt.Sprintf("%d", config.Cfg.App.Port)
-
-	r.Run(":" + port)
-
-	logger.AppLog.Info("服务启动成功,端口：" + port)
+	// 不指定命令时启动HTTP服务 其它命令见 go run cmd/main.go -h
+	os.Exit(cli.Run(os.Args[1:]))
 }
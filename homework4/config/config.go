@@ -1,88 +1,226 @@
 package config
 
 /**
- * @Description: 加载配置文件
+ * @Description: 配置定义 默认值见defaults.go 加载见loader.go 校验规则写在validate标签中
  */
 import (
 	"homework4/pkg/logger"
-	"path/filepath"
-	"runtime"
-
-	"github.com/spf13/viper"
 )
 
 type Config struct {
+	Profile  string         `yaml:"-" mapstructure:"-"` // 当前环境 dev/test/prod
 	App      AppConfig      `yaml:"app" mapstructure:"app"`
 	Database DatabaseConfig `yaml:"database" mapstructure:"database"`
 	Redis    RedisConfig    `yaml:"redis" mapstructure:"redis"`
 	JWT      JWTConfig      `yaml:"jwt" mapstructure:"jwt"`
+	Reaction ReactionConfig `yaml:"reaction" mapstructure:"reaction"`
+	Feed     FeedConfig     `yaml:"feed" mapstructure:"feed"`
+	View     ViewConfig     `yaml:"view" mapstructure:"view"`
+	Hot      HotConfig      `yaml:"hot" mapstructure:"hot"`
+	Health   HealthConfig   `yaml:"health" mapstructure:"health"`
+	Metrics  MetricsConfig  `yaml:"metrics" mapstructure:"metrics"`
+	Tracing  TracingConfig  `yaml:"tracing" mapstructure:"tracing"`
+	Cache    CacheConfig    `yaml:"cache" mapstructure:"cache"`
+	API      APIConfig      `yaml:"api" mapstructure:"api"`
+	GraphQL  GraphQLConfig  `yaml:"graphql" mapstructure:"graphql"`
+	GRPC     GRPCConfig     `yaml:"grpc" mapstructure:"grpc"`
+	OpenAPI  OpenAPIConfig  `yaml:"openapi" mapstructure:"openapi"`
+
+	//以下配置修改后不需要重启 见reload.go
+	Log         LogConfig         `yaml:"log" mapstructure:"log"`
+	CORS        CORSConfig        `yaml:"cors" mapstructure:"cors"`
+	RateLimit   RateLimitConfig   `yaml:"rateLimit" mapstructure:"rateLimit"`
+	Idempotency IdempotencyConfig `yaml:"idempotency" mapstructure:"idempotency"`
+	Validation  ValidationConfig  `yaml:"validation" mapstructure:"validation"`
+	Features    map[string]bool   `yaml:"features" mapstructure:"features"` // 功能开关 key为小写的功能名
 }
 
 type AppConfig struct {
-	Port int `yaml:"port" mapstructure:"port"`
+	Port              int `yaml:"port" mapstructure:"port" validate:"min=1,max=65535"`
+	ReadTimeout       int `yaml:"readTimeout" mapstructure:"readTimeout" validate:"gt=0"`             // 读取整个请求的超时时间 单位秒
+	ReadHeaderTimeout int `yaml:"readHeaderTimeout" mapstructure:"readHeaderTimeout" validate:"gt=0"` // 读取请求头的超时时间 单位秒
+	WriteTimeout      int `yaml:"writeTimeout" mapstructure:"writeTimeout" validate:"gt=0"`           // 写响应的超时时间 单位秒
+	IdleTimeout       int `yaml:"idleTimeout" mapstructure:"idleTimeout" validate:"gt=0"`             // keep-alive空闲连接超时时间 单位秒
+	ShutdownTimeout   int `yaml:"shutdownTimeout" mapstructure:"shutdownTimeout" validate:"gt=0"`     // 退出时等待处理中的请求和后台任务的最长时间 单位秒
+
+	WatchConfig bool `yaml:"watchConfig" mapstructure:"watchConfig"` // 配置文件修改后自动重新加载 关闭时可发送SIGHUP重新加载
+
+	TrustedProxies []string `yaml:"trustedProxies" mapstructure:"trustedProxies" validate:"dive,ip|cidr"` // 可信代理的IP或网段 只有来自这些地址的X-Forwarded-For才会用来取客户端IP 为空时不信任任何代理
 }
 
+const (
+	DriverMySQL    = "mysql"    // MySQL
+	DriverPostgres = "postgres" // PostgreSQL
+	DriverSQLite   = "sqlite"   // SQLite 不依赖外部服务 用于本地运行和测试
+)
+
 type DatabaseConfig struct {
-	Host     string `yaml:"host" mapstructure:"host"`
-	Port     int    `yaml:"port" mapstructure:"port"`
-	User     string `yaml:"user" mapstructure:"user"`
-	Password string `yaml:"password" mapstructure:"password"`
-	DBName   string `yaml:"dbname" mapstructure:"dbname"`
+	Driver   string `yaml:"driver" mapstructure:"driver" validate:"oneof=mysql postgres sqlite"` // 数据库驱动 mysql/postgres/sqlite 默认mysql
+	Host     string `yaml:"host" mapstructure:"host" validate:"required_unless=Driver sqlite"`
+	Port     int    `yaml:"port" mapstructure:"port" validate:"required_unless=Driver sqlite,max=65535"`
+	User     string `yaml:"user" mapstructure:"user" validate:"required_unless=Driver sqlite"`
+	Password string `yaml:"password" mapstructure:"password" validate:"required_unless=Driver sqlite"`
+	DBName   string `yaml:"dbname" mapstructure:"dbname" validate:"required"`                                                             // 数据库名 sqlite时为数据库文件路径 :memory:为内存数据库
+	SSLMode  string `yaml:"sslmode" mapstructure:"sslmode" validate:"omitempty,oneof=disable allow prefer require verify-ca verify-full"` // postgres的sslmode 默认disable
+
+	MigrateOnStart bool `yaml:"migrateOnStart" mapstructure:"migrateOnStart"` // 启动时自动执行未执行的迁移 默认只检查版本
+
+	//连接池 sqlite固定只有一个连接
+	MaxOpenConns    int `yaml:"maxOpenConns" mapstructure:"maxOpenConns" validate:"gte=0"`       // 最大打开连接数 0为不限制
+	MaxIdleConns    int `yaml:"maxIdleConns" mapstructure:"maxIdleConns" validate:"gte=0"`       // 最大空闲连接数
+	ConnMaxLifetime int `yaml:"connMaxLifetime" mapstructure:"connMaxLifetime" validate:"gte=0"` // 连接最长使用时间 单位秒 0为不限制
+	ConnMaxIdleTime int `yaml:"connMaxIdleTime" mapstructure:"connMaxIdleTime" validate:"gte=0"` // 连接最长空闲时间 单位秒 0为不限制
 }
 
 type RedisConfig struct {
-	Host     string `yaml:"host" mapstructure:"host"`
-	Port     int    `yaml:"port" mapstructure:"port"`
-	Password string `yaml:"password" mapstructure:"password"`
-	DB       int    `yaml:"db" mapstructure:"db"`
+	Host     string `yaml:"host" mapstructure:"host" validate:"required"`
+	Port     int    `yaml:"port" mapstructure:"port" validate:"min=1,max=65535"`
+	Password string `yaml:"password" mapstructure:"password"` // 密码 未开启认证时为空
+	DB       int    `yaml:"db" mapstructure:"db" validate:"gte=0"`
 }
 
 type JWTConfig struct {
-	Secret  string `yaml:"secret" mapstructure:"secret"`
-	Expires uint8  `yaml:"expires" mapstructure:"expires"`
+	Secret  string `yaml:"secret" mapstructure:"secret" validate:"min=32"` // 签名密钥 不写在配置文件中 通过APP_JWT_SECRET或APP_JWT_SECRET_FILE设置
+	Expires uint8  `yaml:"expires" mapstructure:"expires" validate:"gt=0"`
 }
 
-var Cfg *Config
+type ReactionConfig struct {
+	FlushInterval     int `yaml:"flushInterval" mapstructure:"flushInterval" validate:"gt=0"`         // redis计数刷入数据库间隔 单位秒
+	ReconcileInterval int `yaml:"reconcileInterval" mapstructure:"reconcileInterval" validate:"gt=0"` // 根据回应表重建计数间隔 单位秒
+}
 
-// 加载配置文件
-func LoadConfig() {
-	_, filename, _, _ := runtime.Caller(0)
-	configPath := filepath.Join(filepath.Dir(filename), "config.yaml")
-	viper.SetConfigFile(configPath)
+const (
+	FeedModeRead  = "read"  // 读扩散 查询时聚合关注用户的文章
+	FeedModeWrite = "write" // 写扩散 发布时写入粉丝的redis时间线
+)
 
-	if err := viper.ReadInConfig(); err != nil {
-		logger.AppLog.Fatal("加载配置文件失败", logger.WrapMeta(err)...)
-	}
-	Cfg = &Config{}
-	if err := viper.Unmarshal(&Cfg); err != nil {
-		logger.AppLog.Fatal("解析配置文件失败", logger.WrapMeta(err)...)
-	}
-	if Cfg.App.Port == 0 {
-		logger.AppLog.Fatal("配置信息应用端口为空，请检查配置文件")
-	}
-	if Cfg.Database.Host == "" {
-		logger.AppLog.Fatal("配置信息数据库主机为空，请检查配置文件")
-	}
-	if Cfg.Database.Port == 0 {
-		logger.AppLog.Fatal("配置信息数据库端口为空，请检查配置文件")
-	}
-	if Cfg.Database.User == "" {
-		logger.AppLog.Fatal("配置信息数据库用户名为空，请检查配置文件")
-	}
-	if Cfg.Database.Password == "" {
-		logger.AppLog.Fatal("配置信息数据库密码为空，请检查配置文件")
-	}
-	if Cfg.Database.DBName == "" {
-		logger.AppLog.Fatal("配置信息数据库名为空，请检查配置文件")
-	}
-	if Cfg.Redis.Host == "" {
-		logger.AppLog.Fatal("配置信息Redis主机为空，请检查配置文件")
-	}
-	if Cfg.Redis.Port == 0 {
-		logger.AppLog.Fatal("配置信息Redis端口为空，请检查配置文件")
-	}
-	if Cfg.Redis.Password == "" {
-		logger.AppLog.Fatal("配置信息Redis密码为空，请检查配置文件")
+type FeedConfig struct {
+	Mode      string `yaml:"mode" mapstructure:"mode" validate:"oneof=read write"` // 首页动态模式 read-读扩散 write-写扩散
+	MaxLength int    `yaml:"maxLength" mapstructure:"maxLength" validate:"gt=0"`   // 写扩散时每个用户时间线保留的最大文章数
+
+	TimelineTTL    int `yaml:"timelineTTL" mapstructure:"timelineTTL" validate:"gt=0"`       // 写扩散时间线的过期时间 单位秒 读取时续期
+	FanOutInterval int `yaml:"fanOutInterval" mapstructure:"fanOutInterval" validate:"gt=0"` // 执行写扩散任务的间隔 单位秒
+	FanOutRetries  int `yaml:"fanOutRetries" mapstructure:"fanOutRetries" validate:"gte=0"`  // 写扩散任务失败后的重试次数
+}
+
+type ViewConfig struct {
+	FlushInterval int `yaml:"flushInterval" mapstructure:"flushInterval" validate:"gt=0"` // 浏览量刷入数据库间隔 单位秒
+}
+
+type HotConfig struct {
+	RefreshInterval int     `yaml:"refreshInterval" mapstructure:"refreshInterval" validate:"gt=0"` // 热门排行刷新间隔 单位秒
+	WindowDays      int     `yaml:"windowDays" mapstructure:"windowDays" validate:"gt=0"`           // 参与热门排行的文章发布天数
+	Gravity         float64 `yaml:"gravity" mapstructure:"gravity" validate:"gt=0"`                 // 时间衰减系数 越大衰减越快
+	ViewWeight      float64 `yaml:"viewWeight" mapstructure:"viewWeight" validate:"gte=0"`          // 浏览量权重
+	LikeWeight      float64 `yaml:"likeWeight" mapstructure:"likeWeight" validate:"gte=0"`          // 点赞数权重
+	CommentWeight   float64 `yaml:"commentWeight" mapstructure:"commentWeight" validate:"gte=0"`    // 评论数权重
+}
+
+type HealthConfig struct {
+	CheckTimeout int `yaml:"checkTimeout" mapstructure:"checkTimeout" validate:"gt=0"` // 就绪检查中单个组件的超时时间 单位毫秒
+}
+
+type MetricsConfig struct {
+	Enabled   bool   `yaml:"enabled" mapstructure:"enabled"`                                             // 是否暴露/metrics
+	AdminPort int    `yaml:"adminPort" mapstructure:"adminPort" validate:"gte=0,max=65535"`              // 管理端口 只暴露/metrics 为0时在业务端口暴露并需要令牌
+	Token     string `yaml:"token" mapstructure:"token" validate:"required_if=Enabled true AdminPort 0"` // 业务端口访问/metrics的Bearer令牌 通过APP_METRICS_TOKEN设置
+}
+
+type TracingConfig struct {
+	Exporter    string  `yaml:"exporter" mapstructure:"exporter" validate:"oneof=none stdout otlp"`    // 导出方式 none-不导出 stdout-标准输出 otlp-OTLP/HTTP
+	Endpoint    string  `yaml:"endpoint" mapstructure:"endpoint" validate:"required_if=Exporter otlp"` // OTLP collector地址 如localhost:4318
+	Insecure    bool    `yaml:"insecure" mapstructure:"insecure"`                                      // OTLP使用http而不是https
+	ServiceName string  `yaml:"serviceName" mapstructure:"serviceName" validate:"required"`            // 服务名
+	SampleRatio float64 `yaml:"sampleRatio" mapstructure:"sampleRatio" validate:"gte=0,lte=1"`         // 采样比例 0-1
+}
+
+type CacheConfig struct {
+	Enabled  bool                         `yaml:"enabled" mapstructure:"enabled"`                   // 是否开启缓存 关闭时直接查询数据库
+	Entities map[string]CacheEntityConfig `yaml:"entities" mapstructure:"entities" validate:"dive"` // 各类数据的缓存配置 key为小写的数据名 未配置的不缓存
+}
+
+type CacheEntityConfig struct {
+	TTL       int     `yaml:"ttl" mapstructure:"ttl" validate:"gt=0"`                               // redis缓存过期时间 单位秒
+	Jitter    float64 `yaml:"jitter" mapstructure:"jitter" validate:"gte=0,lte=1"`                  // 过期时间随机增加的比例 避免同时过期
+	LocalTTL  int     `yaml:"localTTL" mapstructure:"localTTL" validate:"gte=0"`                    // 本机LRU过期时间 单位秒 为0时不使用本机缓存
+	LocalSize int     `yaml:"localSize" mapstructure:"localSize" validate:"required_with=LocalTTL"` // 本机LRU最多缓存的条数 使用本机缓存时必须配置
+	Metrics   bool    `yaml:"metrics" mapstructure:"metrics"`                                       // 是否统计命中率
+}
+
+type APIConfig struct {
+	V1Deprecation string `yaml:"v1Deprecation" mapstructure:"v1Deprecation" validate:"omitempty,datetime=2006-01-02"` // v1接口的弃用日期 设置后v1响应带Deprecation头
+	V1Sunset      string `yaml:"v1Sunset" mapstructure:"v1Sunset" validate:"omitempty,datetime=2006-01-02"`           // v1接口的下线日期 设置后v1响应带Sunset头
+}
+
+type GraphQLConfig struct {
+	Enabled       bool `yaml:"enabled" mapstructure:"enabled"`                             // 是否开启/api/graphql
+	MaxDepth      int  `yaml:"maxDepth" mapstructure:"maxDepth" validate:"gt=0"`           // 查询的最大嵌套层数
+	MaxComplexity int  `yaml:"maxComplexity" mapstructure:"maxComplexity" validate:"gt=0"` // 查询的最大复杂度 每个字段计1 分页字段的子字段乘以pageSize
+}
+
+type GRPCConfig struct {
+	Enabled bool `yaml:"enabled" mapstructure:"enabled"`                                         // 是否开启gRPC服务
+	Port    int  `yaml:"port" mapstructure:"port" validate:"required_if=Enabled true,max=65535"` // gRPC端口 不能与业务端口相同
+	Gateway bool `yaml:"gateway" mapstructure:"gateway"`                                         // 是否在业务端口通过/api/rpc/v1转发到gRPC服务
+}
+
+type OpenAPIConfig struct {
+	ValidateRequests  bool `yaml:"validateRequests" mapstructure:"validateRequests"`   // 按接口文档校验请求 不通过时返回参数错误
+	ValidateResponses bool `yaml:"validateResponses" mapstructure:"validateResponses"` // 按接口文档校验响应 不通过时返回500 响应需要缓存到校验结束 只在测试环境开启
+}
+
+type ValidationConfig struct {
+	BannedWords []string `yaml:"bannedWords" mapstructure:"bannedWords" validate:"dive,required"` // 违禁词 文章、评论、昵称和阅读清单中包含时校验不通过 不区分大小写
+}
+
+type IdempotencyConfig struct {
+	Enabled bool `yaml:"enabled" mapstructure:"enabled"`                 // 是否处理Idempotency-Key请求头
+	TTL     int  `yaml:"ttl" mapstructure:"ttl" validate:"gt=0"`         // 保存首次响应的时间 单位秒
+	LockTTL int  `yaml:"lockTTL" mapstructure:"lockTTL" validate:"gt=0"` // 处理中状态的过期时间 单位秒 超过后视为处理中断 允许重新请求
+	Wait    int  `yaml:"wait" mapstructure:"wait" validate:"gte=0"`      // 重复请求等待首次请求完成的时间 单位毫秒 超过后返回409
+}
+
+type LogConfig struct {
+	Level string `yaml:"level" mapstructure:"level" validate:"oneof=debug info warn error"` // 日志级别
+}
+
+type CORSConfig struct {
+	AllowOrigins     []string `yaml:"allowOrigins" mapstructure:"allowOrigins" validate:"min=1"` // 允许的来源 *为全部
+	AllowCredentials bool     `yaml:"allowCredentials" mapstructure:"allowCredentials"`          // 允许携带Cookie
+	MaxAge           int      `yaml:"maxAge" mapstructure:"maxAge" validate:"gte=0"`             // 预检请求缓存时间 单位秒
+}
+
+type RateLimitConfig struct {
+	Enabled  bool                       `yaml:"enabled" mapstructure:"enabled"`                   // 是否开启限流
+	Requests int                        `yaml:"requests" mapstructure:"requests" validate:"gt=0"` // 全局限流 每个IP在窗口内允许的请求数
+	Window   int                        `yaml:"window" mapstructure:"window" validate:"gt=0"`     // 全局限流窗口大小 单位秒
+	Policies map[string]RateLimitPolicy `yaml:"policies" mapstructure:"policies" validate:"dive"` // 路由组的限流策略 key为策略名 路由组通过策略名引用 未配置的策略不限流
+}
+
+const (
+	RateLimitKeyIP     = "ip"     // 按客户端IP计数
+	RateLimitKeyUser   = "user"   // 按登录用户计数 未登录时按IP
+	RateLimitKeyAPIKey = "apiKey" // 按X-API-Key请求头计数 没有时按IP
+)
+
+type RateLimitPolicy struct {
+	Key      string `yaml:"key" mapstructure:"key" validate:"oneof=ip user apiKey"` // 计数维度 ip、user或apiKey
+	Requests int    `yaml:"requests" mapstructure:"requests" validate:"gt=0"`       // 窗口内允许的请求数
+	Window   int    `yaml:"window" mapstructure:"window" validate:"gt=0"`           // 滑动窗口大小 单位秒
+}
+
+var Cfg *Config
+
+/**
+ * @Description: 加载配置 失败时输出所有错误并退出
+ * @param configPath 配置文件路径 为空时读取环境变量APP_CONFIG 都为空时使用config/config.yaml
+ * @param profile 环境 为空时读取环境变量APP_PROFILE 都为空时退出
+ */
+func LoadConfig(configPath string, profile string) {
+	cfg, err := Load(configPath, profile)
+	if err != nil {
+		logger.AppLog.Fatal("加载配置失败", logger.WrapMeta(err)...)
 	}
-	logger.AppLog.Info("配置文件加载成功")
+	Cfg = cfg
+	logger.AppLog.Info("配置文件加载成功", logger.WrapMeta(nil, logger.NewMeta("profile", cfg.Profile))...)
 }
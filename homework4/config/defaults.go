@@ -0,0 +1,70 @@
+package config
+
+import "github.com/spf13/viper"
+
+// 默认值 配置文件和环境变量都没有设置时使用
+func setDefaults(v *viper.Viper) {
+	v.SetDefault("app.port", 9527)
+	v.SetDefault("app.readTimeout", 15)
+	v.SetDefault("app.readHeaderTimeout", 5)
+	v.SetDefault("app.writeTimeout", 30)
+	v.SetDefault("app.idleTimeout", 60)
+	v.SetDefault("app.shutdownTimeout", 30)
+
+	v.SetDefault("database.driver", DriverMySQL)
+	v.SetDefault("database.sslmode", "disable")
+	v.SetDefault("database.maxOpenConns", 50)
+	v.SetDefault("database.maxIdleConns", 10)
+	v.SetDefault("database.connMaxLifetime", 3600)
+	v.SetDefault("database.connMaxIdleTime", 600)
+
+	v.SetDefault("redis.port", 6379)
+
+	v.SetDefault("jwt.expires", 24)
+
+	v.SetDefault("reaction.flushInterval", 10)
+	v.SetDefault("reaction.reconcileInterval", 3600)
+
+	v.SetDefault("feed.mode", FeedModeRead)
+	v.SetDefault("feed.maxLength", 1000)
+	v.SetDefault("feed.timelineTTL", 7*24*3600)
+	v.SetDefault("feed.fanOutInterval", 1)
+	v.SetDefault("feed.fanOutRetries", 3)
+
+	v.SetDefault("view.flushInterval", 60)
+
+	v.SetDefault("hot.refreshInterval", 300)
+	v.SetDefault("hot.windowDays", 7)
+	v.SetDefault("hot.gravity", 1.5)
+	v.SetDefault("hot.viewWeight", 1)
+	v.SetDefault("hot.likeWeight", 3)
+	v.SetDefault("hot.commentWeight", 5)
+
+	v.SetDefault("health.checkTimeout", 2000)
+
+	v.SetDefault("metrics.enabled", true)
+	v.SetDefault("metrics.adminPort", 9528)
+
+	v.SetDefault("tracing.exporter", "none")
+	v.SetDefault("tracing.serviceName", "homework4")
+	v.SetDefault("tracing.sampleRatio", 1)
+	v.SetDefault("cache.enabled", true)
+	v.SetDefault("graphql.enabled", true)
+	v.SetDefault("graphql.maxDepth", 6)
+	v.SetDefault("graphql.maxComplexity", 2000)
+	v.SetDefault("grpc.enabled", true)
+	v.SetDefault("grpc.port", 9090)
+	v.SetDefault("idempotency.enabled", true)
+	v.SetDefault("idempotency.ttl", 86400)
+	v.SetDefault("idempotency.lockTTL", 30)
+	v.SetDefault("idempotency.wait", 3000)
+
+	v.SetDefault("log.level", "info")
+	v.SetDefault("cors.allowOrigins", []string{"*"})
+	v.SetDefault("cors.allowCredentials", true)
+	v.SetDefault("cors.maxAge", 43200)
+	v.SetDefault("rateLimit.enabled", true)
+	v.SetDefault("rateLimit.requests", 300)
+	v.SetDefault("rateLimit.window", 60)
+	v.SetDefault("features.registration", true)
+}
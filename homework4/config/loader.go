@@ -0,0 +1,159 @@
+package config
+
+/**
+ * @Description: 加载配置 优先级从低到高: 默认值 < config.yaml < config.{profile}.yaml < APP_环境变量 < APP_*_FILE文件内容
+ */
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	EnvPrefix     = "APP"         // 环境变量前缀 如APP_DATABASE_HOST覆盖database.host
+	EnvConfigPath = "APP_CONFIG"  // 配置文件路径
+	EnvProfile    = "APP_PROFILE" // 环境
+	FileSuffix    = "_FILE"       // 从文件读取配置值的环境变量后缀 如APP_JWT_SECRET_FILE=/run/secrets/jwt
+
+	DefaultConfigPath = "config/config.yaml" // 相对于工作目录
+)
+
+const (
+	ProfileDev  = "dev"  // 本地开发
+	ProfileTest = "test" // 测试
+	ProfileProd = "prod" // 生产
+)
+
+/**
+ * @Description: 加载并校验配置 校验失败时返回所有错误
+ * @param configPath 基础配置文件路径 为空时读取APP_CONFIG 都为空时使用config/config.yaml
+ * @param profile 环境 为空时读取APP_PROFILE 都为空时返回错误 同目录下的config.{profile}.yaml覆盖基础配置 不存在时忽略
+ * @return (*Config, error)
+ */
+func Load(configPath string, profile string) (*Config, error) {
+	if configPath == "" {
+		configPath = os.Getenv(EnvConfigPath)
+	}
+	if configPath == "" {
+		configPath = DefaultConfigPath
+	}
+	if profile == "" {
+		profile = os.Getenv(EnvProfile)
+	}
+	//不使用默认环境 避免生产环境漏设环境变量时使用dev的sqlite配置正常启动
+	if profile == "" {
+		return nil, fmt.Errorf("没有指定环境，需要通过-profile参数或%s环境变量指定dev、test或prod", EnvProfile)
+	}
+	if profile != ProfileDev && profile != ProfileTest && profile != ProfileProd {
+		return nil, fmt.Errorf("环境错误，只支持dev、test或prod: %s", profile)
+	}
+
+	v := viper.New()
+	setDefaults(v)
+	v.SetConfigFile(configPath)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("读取配置文件%s失败: %w", configPath, err)
+	}
+	profilePath := ProfilePath(configPath, profile)
+	if _, err := os.Stat(profilePath); err == nil {
+		v.SetConfigFile(profilePath)
+		if err := v.MergeInConfig(); err != nil {
+			return nil, fmt.Errorf("读取配置文件%s失败: %w", profilePath, err)
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if err := bindEnv(v); err != nil {
+		return nil, err
+	}
+
+	cfg := &Config{}
+	if err := v.Unmarshal(cfg); err != nil {
+		return nil, fmt.Errorf("解析配置失败: %w", err)
+	}
+	cfg.Profile = profile
+	if err := Validate(cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+/**
+ * @Description: 环境配置文件路径 config/config.yaml的dev环境为config/config.dev.yaml
+ * @param configPath
+ * @param profile
+ * @return string
+ */
+func ProfilePath(configPath string, profile string) string {
+	ext := filepath.Ext(configPath)
+	return strings.TrimSuffix(configPath, ext) + "." + profile + ext
+}
+
+/**
+ * @Description: 环境变量名 database.maxOpenConns对应APP_DATABASE_MAXOPENCONNS
+ * @param key
+ * @return string
+ */
+func EnvName(key string) string {
+	return EnvPrefix + "_" + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+// 绑定所有配置项的环境变量 配置文件中没有的配置项也能通过环境变量设置
+func bindEnv(v *viper.Viper) error {
+	var errs []error
+	for _, key := range Keys() {
+		name := EnvName(key)
+		if err := v.BindEnv(key, name); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		file := os.Getenv(name + FileSuffix)
+		if file == "" {
+			continue
+		}
+		content, err := os.ReadFile(file)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s%s: %w", name, FileSuffix, err))
+			continue
+		}
+		v.Set(key, strings.TrimRight(string(content), "\r\n"))
+	}
+	return errors.Join(errs...)
+}
+
+/**
+ * @Description: 所有配置项的key 按mapstructure标签生成 如database.host
+ * @return []string
+ */
+func Keys() []string {
+	return structKeys(reflect.TypeOf(Config{}), "")
+}
+
+func structKeys(t reflect.Type, prefix string) []string {
+	var keys []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := field.Tag.Get("mapstructure")
+		if name == "" || name == "-" {
+			continue
+		}
+		if prefix != "" {
+			name = prefix + "." + name
+		}
+		switch field.Type.Kind() {
+		case reflect.Struct:
+			keys = append(keys, structKeys(field.Type, name)...)
+			continue
+		case reflect.Map:
+			//map的key不固定 不绑定环境变量
+			continue
+		}
+		keys = append(keys, name)
+	}
+	return keys
+}
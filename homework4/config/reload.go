@@ -0,0 +1,338 @@
+package config
+
+/**
+ * @Description: 运行时重新加载配置 收到SIGHUP或配置文件修改后重新加载
+ * 只有hotReloadKeys下的配置会生效并通知订阅者 其它配置的修改记录为需要重启
+ */
+import (
+	"context"
+	"fmt"
+	"homework4/pkg/logger"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// 可以不重启生效的配置
+var hotReloadKeys = []string{"log", "cors", "rateLimit", "idempotency", "validation", "features"}
+
+// 日志中需要隐藏值的配置
+var secretKeys = []string{"password", "secret"}
+
+// Change 配置变化
+type Change struct {
+	Key string
+	Old string
+	New string
+}
+
+// 订阅者
+type subscriber struct {
+	name   string
+	notify func(cfg *Config) error
+}
+
+type Reloader struct {
+	configPath string
+	profile    string
+	watch      bool
+
+	mu          sync.Mutex
+	current     *Config
+	subscribers []subscriber
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+/**
+ * @Description: 创建配置重新加载器
+ * @param configPath 和启动时加载的配置路径相同
+ * @param profile 和启动时的环境相同
+ * @param current 当前配置
+ * @return *Reloader
+ */
+func NewReloader(configPath string, profile string, current *Config) *Reloader {
+	return &Reloader{configPath: configPath, profile: profile, watch: current.App.WatchConfig, current: current}
+}
+
+/**
+ * @Description: 订阅配置变化 重新加载后按订阅顺序通知 返回错误时记录日志 不影响其它订阅者
+ * @param name 订阅者名称
+ * @param notify 参数为合并后的配置 只有可以热更新的配置是新值
+ */
+func (r *Reloader) Subscribe(name string, notify func(cfg *Config) error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.subscribers = append(r.subscribers, subscriber{name: name, notify: notify})
+}
+
+// Current 当前生效的配置
+func (r *Reloader) Current() *Config {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.current
+}
+
+/**
+ * @Description: 重新加载配置 加载或校验失败时保留当前配置
+ * @return ([]Change, error) 所有变化的配置 包括需要重启的
+ */
+func (r *Reloader) Reload() ([]Change, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	next, err := Load(r.configPath, r.profile)
+	if err != nil {
+		logger.AppLog.Error("重新加载配置失败 继续使用当前配置", logger.WrapMeta(err)...)
+		return nil, err
+	}
+	changes := Diff(r.current, next)
+	if len(changes) == 0 {
+		logger.AppLog.Info("重新加载配置完成 配置没有变化")
+		return nil, nil
+	}
+
+	var applied, restart []string
+	for _, change := range changes {
+		logger.AppLog.Info("配置变化", logger.WrapMeta(nil,
+			logger.NewMeta("key", change.Key),
+			logger.NewMeta("old", change.Old),
+			logger.NewMeta("new", change.New),
+			logger.NewMeta("hotReload", isHotReloadKey(change.Key)),
+		)...)
+		if isHotReloadKey(change.Key) {
+			applied = append(applied, change.Key)
+		} else {
+			restart = append(restart, change.Key)
+		}
+	}
+	if len(restart) > 0 {
+		logger.AppLog.Warn("部分配置需要重启才能生效", logger.WrapMeta(nil, logger.NewMeta("keys", restart))...)
+	}
+	if len(applied) == 0 {
+		return changes, nil
+	}
+
+	//只替换可以热更新的配置
+	merged := *r.current
+	merged.Log = next.Log
+	merged.CORS = next.CORS
+	merged.RateLimit = next.RateLimit
+	merged.Idempotency = next.Idempotency
+	merged.Validation = next.Validation
+	merged.Features = next.Features
+	r.current = &merged
+	for _, sub := range r.subscribers {
+		if err := sub.notify(&merged); err != nil {
+			logger.AppLog.Error("应用新配置失败", logger.WrapMeta(err, logger.NewMeta("subscriber", sub.name))...)
+		}
+	}
+	logger.AppLog.Info("重新加载配置完成", logger.WrapMeta(nil, logger.NewMeta("applied", applied))...)
+	return changes, nil
+}
+
+/**
+ * @Description: 开始监听SIGHUP 开启app.watchConfig时同时监听配置文件修改
+ * @param ctx 未使用 由Stop停止
+ * @return error
+ */
+func (r *Reloader) Start(_ context.Context) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+	r.done = make(chan struct{})
+
+	var fileEvents <-chan struct{}
+	if r.watch {
+		events, err := r.watchFiles(ctx)
+		if err != nil {
+			cancel()
+			return err
+		}
+		fileEvents = events
+	}
+
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go func() {
+		defer close(r.done)
+		defer signal.Stop(hup)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-hup:
+				logger.AppLog.Info("收到SIGHUP 重新加载配置")
+				r.Reload()
+			case <-fileEvents:
+				logger.AppLog.Info("配置文件已修改 重新加载配置")
+				r.Reload()
+			}
+		}
+	}()
+	return nil
+}
+
+// Stop 停止监听
+func (r *Reloader) Stop(ctx context.Context) error {
+	if r.cancel == nil {
+		return nil
+	}
+	r.cancel()
+	select {
+	case <-r.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// 监听配置文件所在目录 编辑器保存时可能先删除再创建文件 连续修改在500毫秒内只触发一次
+func (r *Reloader) watchFiles(ctx context.Context) (<-chan struct{}, error) {
+	configPath := r.configPath
+	if configPath == "" {
+		configPath = os.Getenv(EnvConfigPath)
+	}
+	if configPath == "" {
+		configPath = DefaultConfigPath
+	}
+	profile := r.current.Profile
+	files := map[string]bool{
+		filepath.Clean(configPath):                       true,
+		filepath.Clean(ProfilePath(configPath, profile)): true,
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	if err := watcher.Add(filepath.Dir(configPath)); err != nil {
+		watcher.Close()
+		return nil, err
+	}
+
+	events := make(chan struct{}, 1)
+	go func() {
+		defer watcher.Close()
+		var debounce <-chan time.Time
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event := <-watcher.Events:
+				if files[filepath.Clean(event.Name)] && event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) != 0 {
+					debounce = time.After(500 * time.Millisecond)
+				}
+			case err := <-watcher.Errors:
+				logger.AppLog.Error("监听配置文件失败", logger.WrapMeta(err)...)
+			case <-debounce:
+				debounce = nil
+				select {
+				case events <- struct{}{}:
+				default:
+				}
+			}
+		}
+	}()
+	return events, nil
+}
+
+/**
+ * @Description: 比较两份配置 密码和密钥的值会被隐藏
+ * @param old
+ * @param new
+ * @return []Change 按key排序
+ */
+func Diff(old *Config, new *Config) []Change {
+	oldValues, newValues := flatten(old), flatten(new)
+	keys := make(map[string]bool, len(oldValues))
+	for key := range oldValues {
+		keys[key] = true
+	}
+	for key := range newValues {
+		keys[key] = true
+	}
+
+	var changes []Change
+	for key := range keys {
+		oldValue, newValue := oldValues[key], newValues[key]
+		if oldValue == newValue {
+			continue
+		}
+		if isSecretKey(key) {
+			oldValue, newValue = mask(oldValue), mask(newValue)
+		}
+		changes = append(changes, Change{Key: key, Old: oldValue, New: newValue})
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Key < changes[j].Key
+	})
+	return changes
+}
+
+// 把配置展开为key和值 如database.host=127.0.0.1 features.registration=true
+func flatten(cfg *Config) map[string]string {
+	values := make(map[string]string)
+	flattenValue(reflect.ValueOf(*cfg), "", values)
+	return values
+}
+
+func flattenValue(v reflect.Value, prefix string, values map[string]string) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := field.Tag.Get("mapstructure")
+		if name == "" || name == "-" {
+			continue
+		}
+		if prefix != "" {
+			name = prefix + "." + name
+		}
+		value := v.Field(i)
+		switch value.Kind() {
+		case reflect.Struct:
+			flattenValue(value, name, values)
+		case reflect.Map:
+			for _, key := range value.MapKeys() {
+				values[name+"."+fmt.Sprint(key.Interface())] = fmt.Sprint(value.MapIndex(key).Interface())
+			}
+		default:
+			values[name] = fmt.Sprint(value.Interface())
+		}
+	}
+}
+
+func isHotReloadKey(key string) bool {
+	for _, prefix := range hotReloadKeys {
+		if key == prefix || strings.HasPrefix(key, prefix+".") {
+			return true
+		}
+	}
+	return false
+}
+
+func isSecretKey(key string) bool {
+	lower := strings.ToLower(key)
+	for _, secret := range secretKeys {
+		if strings.Contains(lower, secret) {
+			return true
+		}
+	}
+	return false
+}
+
+// 隐藏密钥 只显示是否为空
+func mask(value string) string {
+	if value == "" {
+		return ""
+	}
+	return "******"
+}
@@ -0,0 +1,80 @@
+package config
+
+import (
+	"homework4/pkg/logger"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// 环境配置 使用sqlite 只修改可以热更新的幂等和违禁词配置
+const reloadProfile = `
+database:
+  driver: sqlite
+  dbname: ":memory:"
+redis:
+  host: 127.0.0.1
+idempotency:
+  ttl: {ttl}
+validation:
+  bannedWords: [{words}]
+`
+
+func writeProfile(t *testing.T, path string, ttl string, words string) {
+	t.Helper()
+	content := strings.NewReplacer("{ttl}", ttl, "{words}", words).Replace(reloadProfile)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReloadNotifiesHotReloadConfig(t *testing.T) {
+	logger.InitAppLog()
+	t.Setenv("APP_JWT_SECRET", "0123456789abcdef0123456789abcdef")
+
+	base, err := os.ReadFile("config.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(configPath, base, 0644); err != nil {
+		t.Fatal(err)
+	}
+	profilePath := ProfilePath(configPath, ProfileTest)
+	writeProfile(t, profilePath, "60", "spam")
+
+	current, err := Load(configPath, ProfileTest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reloader := NewReloader(configPath, ProfileTest, current)
+	var notified *Config
+	reloader.Subscribe("test", func(cfg *Config) error {
+		notified = cfg
+		return nil
+	})
+
+	writeProfile(t, profilePath, "120", "spam, scam")
+	changes, err := reloader.Reload()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) == 0 {
+		t.Fatal("没有检测到配置变化")
+	}
+	if notified == nil {
+		t.Fatal("订阅者没有收到通知")
+	}
+	if notified.Idempotency.TTL != 120 {
+		t.Errorf("idempotency.ttl = %d, want 120", notified.Idempotency.TTL)
+	}
+	if want := []string{"spam", "scam"}; !reflect.DeepEqual(notified.Validation.BannedWords, want) {
+		t.Errorf("validation.bannedWords = %v, want %v", notified.Validation.BannedWords, want)
+	}
+	if reloader.Current() != notified {
+		t.Error("当前配置不是通知订阅者的配置")
+	}
+}
@@ -0,0 +1,96 @@
+package config
+
+/**
+ * @Description: 配置校验 规则写在结构体的validate标签中 一次返回所有错误
+ */
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// ValidationError 配置校验错误 包含所有不合法的配置项
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "配置校验失败: " + strings.Join(e.Problems, "; ")
+}
+
+var validate = newValidator()
+
+// 错误中的字段名使用配置项的key
+func newValidator() *validator.Validate {
+	v := validator.New(validator.WithRequiredStructEnabled())
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := field.Tag.Get("mapstructure")
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+	return v
+}
+
+/**
+ * @Description: 校验配置
+ * @param cfg
+ * @return error 不合法时为*ValidationError
+ */
+func Validate(cfg *Config) error {
+	err := validate.Struct(cfg)
+	if err == nil {
+		return nil
+	}
+	var fieldErrs validator.ValidationErrors
+	if !errors.As(err, &fieldErrs) {
+		return err
+	}
+	problems := make([]string, 0, len(fieldErrs))
+	for _, fieldErr := range fieldErrs {
+		//去掉根结构体名 Config.database.host -> database.host
+		key := fieldErr.Namespace()
+		if i := strings.Index(key, "."); i >= 0 {
+			key = key[i+1:]
+		}
+		//map中的配置项不绑定环境变量
+		if strings.Contains(key, "[") {
+			problems = append(problems, key+describe(fieldErr))
+			continue
+		}
+		problems = append(problems, fmt.Sprintf("%s(%s)%s", key, EnvName(key), describe(fieldErr)))
+	}
+	return &ValidationError{Problems: problems}
+}
+
+// 校验规则的中文说明
+func describe(fieldErr validator.FieldError) string {
+	isString := fieldErr.Kind() == reflect.String
+	switch fieldErr.Tag() {
+	case "required", "required_if", "required_unless", "required_with":
+		return "不能为空"
+	case "oneof":
+		return "只能是" + strings.ReplaceAll(fieldErr.Param(), " ", "、")
+	case "min":
+		if isString {
+			return "长度不能少于" + fieldErr.Param()
+		}
+		return "不能小于" + fieldErr.Param()
+	case "max":
+		return "不能大于" + fieldErr.Param()
+	case "gt":
+		return "必须大于" + fieldErr.Param()
+	case "gte":
+		return "不能小于" + fieldErr.Param()
+	case "datetime":
+		return "格式必须为" + fieldErr.Param()
+	case "ip|cidr":
+		return "必须是IP或网段"
+	default:
+		return fmt.Sprintf("不满足%s=%s", fieldErr.Tag(), fieldErr.Param())
+	}
+}
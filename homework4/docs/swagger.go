@@ -1,7 +0,0 @@
-package docs
-
-import _ "github.com/swaggo/swag"
-import _ "github.com/swaggo/gin-swagger"
-import _ "github.com/swaggo/files"
-
-
@@ -0,0 +1,72 @@
+package graphql
+
+/**
+ * @Description: 请求级的解析上下文 包含当前用户、提示语言和批量加载器
+ */
+import (
+	"context"
+	"homework4/internal/errcode"
+	"homework4/internal/service"
+	"sync"
+)
+
+type contextKey struct{}
+
+type requestInfo struct {
+	viewerID uint // 当前登录用户ID 未登录为0
+	lang     errcode.Lang
+	users    *loader[service.UserBriefResponse]
+	posts    *loader[service.PostResponse]
+
+	//文章评论按分页参数分组 相同分页参数的文章一次查询
+	commentsMu    sync.Mutex
+	comments      map[commentPageKey]*loader[service.CommentPage]
+	fetchComments func(page int, pageSize int) func(ctx context.Context, ids []uint) (map[uint]service.CommentPage, error)
+}
+
+type commentPageKey struct {
+	page     int
+	pageSize int
+}
+
+func newContext(ctx context.Context, info *requestInfo) context.Context {
+	return context.WithValue(ctx, contextKey{}, info)
+}
+
+// 没有请求信息时按未登录和默认语言处理
+func fromContext(ctx context.Context) *requestInfo {
+	if info, ok := ctx.Value(contextKey{}).(*requestInfo); ok {
+		return info
+	}
+	return &requestInfo{lang: errcode.LangZH}
+}
+
+/**
+ * @Description: 获取分页参数对应的评论加载器 不存在时创建
+ * @param page
+ * @param pageSize
+ * @return *loader[service.CommentPage]
+ */
+func (info *requestInfo) commentPages(page int, pageSize int) *loader[service.CommentPage] {
+	info.commentsMu.Lock()
+	defer info.commentsMu.Unlock()
+	key := commentPageKey{page: page, pageSize: pageSize}
+	if l, ok := info.comments[key]; ok {
+		return l
+	}
+	if info.comments == nil {
+		info.comments = make(map[commentPageKey]*loader[service.CommentPage])
+	}
+	l := newLoader(info.fetchComments(page, pageSize))
+	info.comments[key] = l
+	return l
+}
+
+// 需要登录的修改操作
+func requireViewer(ctx context.Context) (uint, error) {
+	viewerID := fromContext(ctx).viewerID
+	if viewerID == 0 {
+		return 0, errcode.ErrTokenMissing
+	}
+	return viewerID, nil
+}
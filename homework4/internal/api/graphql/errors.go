@@ -0,0 +1,84 @@
+package graphql
+
+/**
+ * @Description: 解析字段的错误转换为GraphQL错误 extensions.code为错误码 提示按Accept-Language本地化
+ * 参数校验错误在extensions.details中返回各字段的错误 没有错误码的错误只返回服务器内部错误
+ */
+import (
+	"context"
+	"errors"
+	"homework4/internal/errcode"
+	"homework4/internal/validation"
+	"homework4/pkg/logger"
+
+	"github.com/go-playground/validator/v10"
+	gql "github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/location"
+)
+
+// 带错误码的GraphQL错误
+type codedError struct {
+	message    string
+	extensions map[string]interface{}
+}
+
+func (e *codedError) Error() string {
+	return e.message
+}
+
+func (e *codedError) Extensions() map[string]interface{} {
+	return e.extensions
+}
+
+// 转换为带错误码的GraphQL错误
+func toError(ctx context.Context, err error) error {
+	lang := fromContext(ctx).lang
+
+	var fieldErrs validator.ValidationErrors
+	var fieldErr *validation.FieldError
+	if errors.As(err, &fieldErrs) || errors.As(err, &fieldErr) {
+		return &codedError{
+			message: errcode.ErrInvalidParams.Message(lang),
+			extensions: map[string]interface{}{
+				"code":    errcode.ErrInvalidParams.Code,
+				"details": validation.Details(err, lang),
+			},
+		}
+	}
+
+	codeErr, ok := errcode.From(err)
+	if !ok {
+		logger.FromContext(ctx).Error("GraphQL字段解析失败", logger.WrapMeta(err)...)
+	}
+	return &codedError{
+		message:    codeErr.Message(lang),
+		extensions: map[string]interface{}{"code": codeErr.Code},
+	}
+}
+
+// 包装字段解析函数 错误和延迟取值函数中的错误都转换为带错误码的GraphQL错误
+func resolver(fn gql.FieldResolveFn) gql.FieldResolveFn {
+	return func(p gql.ResolveParams) (interface{}, error) {
+		result, err := fn(p)
+		if err != nil {
+			return nil, toError(p.Context, err)
+		}
+		if thunk, ok := result.(func() (interface{}, error)); ok {
+			return func() (interface{}, error) {
+				value, err := thunk()
+				if err != nil {
+					return nil, toError(p.Context, err)
+				}
+				return value, nil
+			}, nil
+		}
+		return result, nil
+	}
+}
+
+// 执行前的错误 如超过嵌套层数或复杂度限制
+func limitError(lang errcode.Lang, codeErr *errcode.Error, extensions map[string]interface{}) *gql.Result {
+	extensions["code"] = codeErr.Code
+	return &gql.Result{Errors: []gqlerrors.FormattedError{{Message: codeErr.Message(lang), Locations: []location.SourceLocation{}, Extensions: extensions}}}
+}
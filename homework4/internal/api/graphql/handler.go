@@ -0,0 +1,141 @@
+package graphql
+
+/**
+ * @Description: GraphQL接口 查询文章、评论和用户 登录后可以创建和删除文章、评论
+ * 解析后先检查嵌套层数和复杂度 再校验和执行 执行错误和HTTP接口一样带错误码
+ */
+import (
+	"context"
+	"homework4/config"
+	"homework4/internal/errcode"
+	"homework4/internal/middleware/auth"
+	"homework4/internal/middleware/response"
+	"homework4/internal/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	gql "github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+)
+
+// Request GraphQL请求
+type Request struct {
+	Query         string                 `json:"query" binding:"required" example:"{ posts(pageSize: 5) { list { id title author { nickname } } total } }"` // 查询语句
+	Variables     map[string]interface{} `json:"variables"`                                                                                                 // 变量
+	OperationName string                 `json:"operationName"`                                                                                             // 有多个操作时要执行的操作名
+}
+
+// Response GraphQL响应
+type Response struct {
+	Data   interface{}                `json:"data,omitempty"`   // 查询结果
+	Errors []gqlerrors.FormattedError `json:"errors,omitempty"` // 错误 extensions.code为错误码
+}
+
+type Handler struct {
+	cfg      config.GraphQLConfig
+	schema   gql.Schema
+	posts    *service.PostService    // 批量加载文章
+	comments *service.CommentService // 批量加载文章评论
+	users    *service.UserService    // 批量加载用户
+}
+
+/**
+ * @Description: 创建GraphQL接口
+ * @param cfg
+ * @param posts
+ * @param comments
+ * @param users
+ * @return (*Handler, error)
+ */
+func NewHandler(cfg config.GraphQLConfig, posts *service.PostService, comments *service.CommentService, users *service.UserService) (*Handler, error) {
+	schema, err := newSchema(posts, comments, users)
+	if err != nil {
+		return nil, err
+	}
+	return &Handler{cfg: cfg, schema: schema, posts: posts, comments: comments, users: users}, nil
+}
+
+/**
+ * @Description: 执行GraphQL请求 查询错误和执行错误在响应的errors中返回 状态码为200
+ * @param c
+ * @return error
+ */
+func (h *Handler) Handle(c *gin.Context) error {
+	var req Request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		return response.NewValidationError(err)
+	}
+	result := h.execute(c, &req)
+	c.JSON(http.StatusOK, Response{Data: result.Data, Errors: result.Errors})
+	return nil
+}
+
+func (h *Handler) execute(c *gin.Context, req *Request) *gql.Result {
+	lang := errcode.ParseAcceptLanguage(c.GetHeader("Accept-Language"))
+
+	doc, err := parser.Parse(parser.ParseParams{Source: source.NewSource(&source.Source{Body: []byte(req.Query), Name: "GraphQL request"})})
+	if err != nil {
+		return &gql.Result{Errors: gqlerrors.FormatErrors(err)}
+	}
+
+	depth, complexity := measure(doc, req.OperationName, req.Variables)
+	if depth > h.cfg.MaxDepth {
+		return limitError(lang, errcode.ErrQueryTooDeep, map[string]interface{}{"limit": h.cfg.MaxDepth, "depth": depth})
+	}
+	if complexity > h.cfg.MaxComplexity {
+		return limitError(lang, errcode.ErrQueryTooComplex, map[string]interface{}{"limit": h.cfg.MaxComplexity, "complexity": complexity})
+	}
+
+	validation := gql.ValidateDocument(&h.schema, doc, nil)
+	if !validation.IsValid {
+		return &gql.Result{Errors: validation.Errors}
+	}
+
+	//查询登录信息 未登录时用户ID为0
+	authUser, _ := auth.GetOptionalAuthUser(c)
+	ctx := newContext(c.Request.Context(), h.newRequestInfo(authUser.UserID, lang))
+	return gql.Execute(gql.ExecuteParams{
+		Schema:        h.schema,
+		AST:           doc,
+		OperationName: req.OperationName,
+		Args:          req.Variables,
+		Context:       ctx,
+	})
+}
+
+// 每个请求创建新的加载器 缓存只在请求内有效
+func (h *Handler) newRequestInfo(viewerID uint, lang errcode.Lang) *requestInfo {
+	return &requestInfo{
+		viewerID: viewerID,
+		lang:     lang,
+		users: newLoader(func(ctx context.Context, ids []uint) (map[uint]service.UserBriefResponse, error) {
+			users, err := h.users.GetUsersByIDs(ctx, ids)
+			if err != nil {
+				return nil, err
+			}
+			userMap := make(map[uint]service.UserBriefResponse, len(users))
+			for _, user := range users {
+				userMap[user.ID] = user
+			}
+			return userMap, nil
+		}),
+		posts: newLoader(func(ctx context.Context, ids []uint) (map[uint]service.PostResponse, error) {
+			posts, err := h.posts.GetPostsByIDs(ctx, ids, viewerID)
+			if err != nil {
+				return nil, err
+			}
+			postMap := make(map[uint]service.PostResponse, len(posts))
+			for _, post := range posts {
+				postMap[post.ID] = post
+			}
+			return postMap, nil
+		}),
+		fetchComments: func(page int, pageSize int) func(ctx context.Context, ids []uint) (map[uint]service.CommentPage, error) {
+			return func(ctx context.Context, ids []uint) (map[uint]service.CommentPage, error) {
+				return h.comments.GetCommentPages(ctx, ids, page, pageSize, viewerID)
+			}
+		},
+	}
+}
@@ -0,0 +1,133 @@
+package graphql
+
+/**
+ * @Description: 查询的嵌套层数和复杂度限制 执行前按语法树计算 超过限制时不执行
+ * 每个字段复杂度计1 分页字段的子字段复杂度乘以pageSize 内省字段不计算
+ * 在校验文档之前计算 pageSize按最大每页数量截断 复杂度超过int范围时取最大值 避免溢出后绕过限制
+ */
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// 分页字段 子字段的复杂度乘以pageSize参数
+var paginatedFields = map[string]bool{"posts": true, "comments": true}
+
+type limitChecker struct {
+	fragments map[string]*ast.FragmentDefinition
+	variables map[string]interface{}
+}
+
+/**
+ * @Description: 计算要执行的操作的嵌套层数和复杂度 没有匹配的操作时返回0 由执行时报错
+ * @param doc
+ * @param operationName 为空时使用第一个操作
+ * @param variables
+ * @return (int, int) 嵌套层数和复杂度
+ */
+func measure(doc *ast.Document, operationName string, variables map[string]interface{}) (int, int) {
+	checker := &limitChecker{fragments: make(map[string]*ast.FragmentDefinition), variables: variables}
+	var operation *ast.OperationDefinition
+	for _, definition := range doc.Definitions {
+		switch definition := definition.(type) {
+		case *ast.FragmentDefinition:
+			checker.fragments[definition.Name.Value] = definition
+		case *ast.OperationDefinition:
+			if operation == nil && (operationName == "" || (definition.Name != nil && definition.Name.Value == operationName)) {
+				operation = definition
+			}
+		}
+	}
+	if operation == nil {
+		return 0, 0
+	}
+	return checker.selectionSet(operation.SelectionSet, map[string]bool{})
+}
+
+// 返回选择集的最大嵌套层数和复杂度 visiting用于跳过循环引用的片段
+func (l *limitChecker) selectionSet(set *ast.SelectionSet, visiting map[string]bool) (int, int) {
+	if set == nil {
+		return 0, 0
+	}
+	maxDepth, complexity := 0, 0
+	for _, selection := range set.Selections {
+		var depth, cost int
+		switch selection := selection.(type) {
+		case *ast.Field:
+			if strings.HasPrefix(selection.Name.Value, "__") {
+				continue
+			}
+			childDepth, childCost := l.selectionSet(selection.SelectionSet, visiting)
+			depth = childDepth + 1
+			cost = saturatingAdd(1, saturatingMul(childCost, l.multiplier(selection)))
+		case *ast.InlineFragment:
+			depth, cost = l.selectionSet(selection.SelectionSet, visiting)
+		case *ast.FragmentSpread:
+			name := selection.Name.Value
+			fragment, ok := l.fragments[name]
+			if !ok || visiting[name] {
+				continue
+			}
+			visiting[name] = true
+			depth, cost = l.selectionSet(fragment.SelectionSet, visiting)
+			delete(visiting, name)
+		}
+		maxDepth = max(maxDepth, depth)
+		complexity = saturatingAdd(complexity, cost)
+	}
+	return maxDepth, complexity
+}
+
+// 分页字段按pageSize参数计算 没有传入时为默认每页数量 超过最大每页数量时按最大值计算
+func (l *limitChecker) multiplier(field *ast.Field) int {
+	if !paginatedFields[field.Name.Value] {
+		return 1
+	}
+	for _, argument := range field.Arguments {
+		if argument.Name.Value != "pageSize" {
+			continue
+		}
+		switch value := argument.Value.(type) {
+		case *ast.IntValue:
+			//超出int范围的数字解析失败 同样按最大值计算
+			n, err := strconv.ParseInt(value.Value, 10, 64)
+			if err != nil && !strings.HasPrefix(value.Value, "-") {
+				return maxPageSize
+			}
+			if err == nil && n > 0 {
+				return int(min(n, maxPageSize))
+			}
+		case *ast.Variable:
+			switch n := l.variables[value.Name.Value].(type) {
+			case float64:
+				if n > 0 {
+					return int(min(n, maxPageSize))
+				}
+			case int:
+				if n > 0 {
+					return min(n, maxPageSize)
+				}
+			}
+		}
+	}
+	return defaultPageSize
+}
+
+// 加法 超过int范围时返回最大值 参数都不小于0
+func saturatingAdd(a int, b int) int {
+	if a > math.MaxInt-b {
+		return math.MaxInt
+	}
+	return a + b
+}
+
+// 乘法 超过int范围时返回最大值 参数都不小于0
+func saturatingMul(a int, b int) int {
+	if a != 0 && b > math.MaxInt/a {
+		return math.MaxInt
+	}
+	return a * b
+}
@@ -0,0 +1,96 @@
+package graphql
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/graphql-go/graphql/language/parser"
+)
+
+func TestMeasure(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		operationName  string
+		variables      map[string]interface{}
+		wantDepth      int
+		wantComplexity int
+	}{
+		{name: "默认每页数量", query: `{ posts { total } }`, wantDepth: 2, wantComplexity: 1 + 1*defaultPageSize},
+		{
+			name:           "嵌套字段",
+			query:          `{ posts(pageSize: 5) { list { id author { nickname } } } }`,
+			wantDepth:      4,
+			wantComplexity: 1 + (1+1+(1+1))*5,
+		},
+		{name: "超过最大每页数量", query: `{ posts(pageSize: 1000) { total } }`, wantDepth: 2, wantComplexity: 1 + maxPageSize},
+		{name: "超出int范围的每页数量", query: `{ posts(pageSize: 99999999999999999999) { total } }`, wantDepth: 2, wantComplexity: 1 + maxPageSize},
+		{name: "负数每页数量", query: `{ posts(pageSize: -5) { total } }`, wantDepth: 2, wantComplexity: 1 + defaultPageSize},
+		{
+			name:           "变量中的每页数量",
+			query:          `query Q($n: Int) { posts(pageSize: $n) { total } }`,
+			variables:      map[string]interface{}{"n": float64(3)},
+			wantDepth:      2,
+			wantComplexity: 1 + 3,
+		},
+		{
+			name:           "变量超过最大每页数量",
+			query:          `query Q($n: Int) { posts(pageSize: $n) { total } }`,
+			variables:      map[string]interface{}{"n": float64(500)},
+			wantDepth:      2,
+			wantComplexity: 1 + maxPageSize,
+		},
+		{
+			name:           "没有传入的变量",
+			query:          `query Q($n: Int) { posts(pageSize: $n) { total } }`,
+			wantDepth:      2,
+			wantComplexity: 1 + defaultPageSize,
+		},
+		{
+			name:           "片段",
+			query:          `{ post(id: 1) { ...F } } fragment F on Post { id comments(pageSize: 2) { total } }`,
+			wantDepth:      3,
+			wantComplexity: 1 + 1 + (1 + 1*2),
+		},
+		{
+			name:           "内联片段",
+			query:          `{ post(id: 1) { ... on Post { id author { id } } } }`,
+			wantDepth:      3,
+			wantComplexity: 1 + 1 + (1 + 1),
+		},
+		{
+			name:           "循环引用的片段",
+			query:          `{ post(id: 1) { ...A } } fragment A on Post { id ...B } fragment B on Post { title ...A }`,
+			wantDepth:      2,
+			wantComplexity: 1 + 1 + 1,
+		},
+		{name: "内省字段不计算", query: `{ __schema { types { name } } }`, wantDepth: 0, wantComplexity: 0},
+		{
+			name:           "按操作名选择",
+			query:          `query A { me { id } } query B { posts { total } }`,
+			operationName:  "B",
+			wantDepth:      2,
+			wantComplexity: 1 + defaultPageSize,
+		},
+		{name: "没有匹配的操作", query: `query A { me { id } }`, operationName: "C", wantDepth: 0, wantComplexity: 0},
+		{
+			name:           "复杂度超过int范围",
+			query:          "{ " + strings.Repeat("posts(pageSize: 100) { ", 12) + "total" + strings.Repeat(" }", 12) + " }",
+			wantDepth:      13,
+			wantComplexity: math.MaxInt,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := parser.Parse(parser.ParseParams{Source: tt.query})
+			if err != nil {
+				t.Fatal(err)
+			}
+			depth, complexity := measure(doc, tt.operationName, tt.variables)
+			if depth != tt.wantDepth || complexity != tt.wantComplexity {
+				t.Errorf("measure() = %d, %d, want %d, %d", depth, complexity, tt.wantDepth, tt.wantComplexity)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package graphql
+
+/**
+ * @Description: 按ID批量加载 同一层的字段先登记ID 第一次取值时一次查询所有登记的ID 避免列表中每一项单独查询
+ * 依赖graphql-go按层处理返回的函数 每个请求创建新的加载器 请求内相同的ID只查询一次
+ */
+import (
+	"context"
+	"sync"
+)
+
+type loader[V any] struct {
+	fetch func(ctx context.Context, ids []uint) (map[uint]V, error)
+
+	mu      sync.Mutex
+	pending []uint
+	loaded  map[uint]bool
+	values  map[uint]V
+	errs    map[uint]error
+}
+
+func newLoader[V any](fetch func(ctx context.Context, ids []uint) (map[uint]V, error)) *loader[V] {
+	return &loader[V]{
+		fetch:  fetch,
+		loaded: make(map[uint]bool),
+		values: make(map[uint]V),
+		errs:   make(map[uint]error),
+	}
+}
+
+/**
+ * @Description: 登记ID 返回取值函数 作为字段的解析结果 不存在时取值为nil
+ * @param ctx
+ * @param id
+ * @return func() (interface{}, error)
+ */
+func (l *loader[V]) load(ctx context.Context, id uint) func() (interface{}, error) {
+	l.mu.Lock()
+	if !l.loaded[id] {
+		l.pending = append(l.pending, id)
+	}
+	l.mu.Unlock()
+
+	return func() (interface{}, error) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		if !l.loaded[id] {
+			l.flush(ctx)
+		}
+		if err := l.errs[id]; err != nil {
+			return nil, err
+		}
+		value, ok := l.values[id]
+		if !ok {
+			return nil, nil
+		}
+		return value, nil
+	}
+}
+
+// 一次查询所有登记的ID 调用时持有锁
+func (l *loader[V]) flush(ctx context.Context) {
+	ids := make([]uint, 0, len(l.pending))
+	for _, id := range l.pending {
+		if !l.loaded[id] {
+			l.loaded[id] = true
+			ids = append(ids, id)
+		}
+	}
+	l.pending = nil
+	if len(ids) == 0 {
+		return
+	}
+
+	values, err := l.fetch(ctx, ids)
+	for _, id := range ids {
+		if err != nil {
+			l.errs[id] = err
+			continue
+		}
+		if value, ok := values[id]; ok {
+			l.values[id] = value
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"homework4/internal/service"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestLoaderBatchesPendingIDs(t *testing.T) {
+	var calls [][]uint
+	l := newLoader(func(ctx context.Context, ids []uint) (map[uint]string, error) {
+		calls = append(calls, slices.Clone(ids))
+		values := make(map[uint]string)
+		for _, id := range ids {
+			if id != 3 {
+				values[id] = "value"
+			}
+		}
+		return values, nil
+	})
+	ctx := context.Background()
+
+	//同一层的字段先登记ID 相同的ID只查询一次
+	thunks := []func() (interface{}, error){l.load(ctx, 1), l.load(ctx, 2), l.load(ctx, 1), l.load(ctx, 3)}
+	var got []interface{}
+	for _, thunk := range thunks {
+		value, err := thunk()
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, value)
+	}
+	if want := []interface{}{"value", "value", "value", nil}; !reflect.DeepEqual(got, want) {
+		t.Errorf("values = %v, want %v 不存在的ID为nil", got, want)
+	}
+	if want := [][]uint{{1, 2, 3}}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("查询 = %v, want %v", calls, want)
+	}
+
+	//已加载的ID不再查询
+	if value, _ := l.load(ctx, 2)(); value != "value" {
+		t.Errorf("value = %v", value)
+	}
+	if _, err := l.load(ctx, 4)(); err != nil {
+		t.Fatal(err)
+	}
+	if want := [][]uint{{1, 2, 3}, {4}}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("查询 = %v, want %v", calls, want)
+	}
+}
+
+func TestLoaderReturnsFetchError(t *testing.T) {
+	fetchErr := errors.New("查询失败")
+	calls := 0
+	l := newLoader(func(ctx context.Context, ids []uint) (map[uint]int, error) {
+		calls++
+		return nil, fetchErr
+	})
+	ctx := context.Background()
+
+	first, second := l.load(ctx, 1), l.load(ctx, 2)
+	for _, thunk := range []func() (interface{}, error){first, second} {
+		if value, err := thunk(); !errors.Is(err, fetchErr) || value != nil {
+			t.Errorf("thunk() = %v, %v, want nil, %v", value, err, fetchErr)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("查询次数 = %d, want 1", calls)
+	}
+}
+
+func TestCommentPagesGroupedByPageArgs(t *testing.T) {
+	var calls []commentPageKey
+	info := &requestInfo{
+		fetchComments: func(page int, pageSize int) func(ctx context.Context, ids []uint) (map[uint]service.CommentPage, error) {
+			return func(ctx context.Context, ids []uint) (map[uint]service.CommentPage, error) {
+				calls = append(calls, commentPageKey{page: page, pageSize: pageSize})
+				return map[uint]service.CommentPage{}, nil
+			}
+		},
+	}
+	ctx := context.Background()
+
+	//相同分页参数的文章共用加载器 一次查询
+	thunks := []func() (interface{}, error){
+		info.commentPages(1, 10).load(ctx, 1),
+		info.commentPages(1, 10).load(ctx, 2),
+		info.commentPages(2, 10).load(ctx, 1),
+	}
+	for _, thunk := range thunks {
+		if _, err := thunk(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if want := []commentPageKey{{page: 1, pageSize: 10}, {page: 2, pageSize: 10}}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("查询 = %v, want %v", calls, want)
+	}
+}
@@ -0,0 +1,444 @@
+package graphql
+
+/**
+ * @Description: GraphQL类型和字段 字段解析调用现有service 关联的用户和文章通过批量加载器按ID加载
+ */
+import (
+	"context"
+	"homework4/internal/errcode"
+	"homework4/internal/service"
+	"strconv"
+
+	"github.com/gin-gonic/gin/binding"
+	gql "github.com/graphql-go/graphql"
+)
+
+const (
+	defaultPageSize = 10  // 默认每页数量 与service一致
+	maxPageSize     = 100 // 最大每页数量 与service的校验规则一致
+)
+
+// 分页结果
+type connection struct {
+	List     interface{} `json:"list"`
+	Total    int64       `json:"total"`
+	Page     int         `json:"page"`
+	PageSize int         `json:"pageSize"`
+}
+
+type resolvers struct {
+	posts    *service.PostService
+	comments *service.CommentService
+	users    *service.UserService
+}
+
+/**
+ * @Description: 创建GraphQL schema
+ * @param posts
+ * @param comments
+ * @param users
+ * @return (gql.Schema, error)
+ */
+func newSchema(posts *service.PostService, comments *service.CommentService, users *service.UserService) (gql.Schema, error) {
+	r := &resolvers{posts: posts, comments: comments, users: users}
+
+	userType := gql.NewObject(gql.ObjectConfig{
+		Name:        "User",
+		Description: "用户公开信息",
+		Fields: gql.Fields{
+			"id":        &gql.Field{Type: gql.NewNonNull(gql.ID), Description: "用户ID"},
+			"username":  &gql.Field{Type: gql.NewNonNull(gql.String), Description: "用户名"},
+			"nickname":  &gql.Field{Type: gql.NewNonNull(gql.String), Description: "昵称"},
+			"postCount": &gql.Field{Type: gql.NewNonNull(gql.Int), Description: "文章数"},
+			"createdAt": &gql.Field{Type: gql.NewNonNull(gql.String), Description: "注册时间"},
+		},
+	})
+
+	//文章和评论互相引用 字段延迟定义
+	var postType, commentType *gql.Object
+	commentConnectionType := gql.NewObject(gql.ObjectConfig{
+		Name: "CommentConnection",
+		Fields: gql.FieldsThunk(func() gql.Fields {
+			return connectionFields(commentType)
+		}),
+	})
+	postType = gql.NewObject(gql.ObjectConfig{
+		Name:        "Post",
+		Description: "文章",
+		Fields: gql.FieldsThunk(func() gql.Fields {
+			return gql.Fields{
+				"id":           &gql.Field{Type: gql.NewNonNull(gql.ID), Description: "文章ID"},
+				"title":        &gql.Field{Type: gql.NewNonNull(gql.String), Description: "标题"},
+				"content":      &gql.Field{Type: gql.NewNonNull(gql.String), Description: "内容"},
+				"createdAt":    &gql.Field{Type: gql.NewNonNull(gql.String), Description: "创建时间"},
+				"updatedAt":    &gql.Field{Type: gql.NewNonNull(gql.String), Description: "更新时间"},
+				"viewCount":    &gql.Field{Type: gql.NewNonNull(gql.Int), Description: "浏览量"},
+				"commentCount": &gql.Field{Type: gql.NewNonNull(gql.Int), Description: "评论数"},
+				"likeCount":    &gql.Field{Type: gql.NewNonNull(gql.Int), Description: "点赞数"},
+				"liked":        &gql.Field{Type: gql.NewNonNull(gql.Boolean), Description: "当前用户是否点赞 未登录为false"},
+				"author": &gql.Field{
+					Type:        userType,
+					Description: "作者 已删除的用户为null",
+					Resolve: resolver(func(p gql.ResolveParams) (interface{}, error) {
+						return fromContext(p.Context).users.load(p.Context, p.Source.(service.PostResponse).UserID), nil
+					}),
+				},
+				"comments": &gql.Field{
+					Type:        gql.NewNonNull(commentConnectionType),
+					Description: "评论分页",
+					Args:        pageArgs(),
+					Resolve:     resolver(r.postComments),
+				},
+			}
+		}),
+	})
+	commentType = gql.NewObject(gql.ObjectConfig{
+		Name:        "Comment",
+		Description: "评论",
+		Fields: gql.FieldsThunk(func() gql.Fields {
+			return gql.Fields{
+				"id":        &gql.Field{Type: gql.NewNonNull(gql.ID), Description: "评论ID"},
+				"content":   &gql.Field{Type: gql.NewNonNull(gql.String), Description: "评论内容"},
+				"createdAt": &gql.Field{Type: gql.NewNonNull(gql.String), Description: "创建时间"},
+				"likeCount": &gql.Field{Type: gql.NewNonNull(gql.Int), Description: "点赞数"},
+				"liked":     &gql.Field{Type: gql.NewNonNull(gql.Boolean), Description: "当前用户是否点赞 未登录为false"},
+				"user": &gql.Field{
+					Type:        userType,
+					Description: "评论用户 已删除的用户为null",
+					Resolve: resolver(func(p gql.ResolveParams) (interface{}, error) {
+						return fromContext(p.Context).users.load(p.Context, p.Source.(service.CommentWithUserResponse).UserID), nil
+					}),
+				},
+				"post": &gql.Field{
+					Type:        postType,
+					Description: "所属文章 已删除的文章为null",
+					Resolve: resolver(func(p gql.ResolveParams) (interface{}, error) {
+						return fromContext(p.Context).posts.load(p.Context, p.Source.(service.CommentWithUserResponse).PostID), nil
+					}),
+				},
+			}
+		}),
+	})
+	postConnectionType := gql.NewObject(gql.ObjectConfig{
+		Name:   "PostConnection",
+		Fields: connectionFields(postType),
+	})
+
+	postSortType := gql.NewEnum(gql.EnumConfig{
+		Name:        "PostSort",
+		Description: "文章排序",
+		Values: gql.EnumValueConfigMap{
+			"LATEST":         &gql.EnumValueConfig{Value: service.PostSortLatest, Description: "按发布时间倒序"},
+			"HOT":            &gql.EnumValueConfig{Value: service.PostSortHot, Description: "按热度倒序"},
+			"MOST_COMMENTED": &gql.EnumValueConfig{Value: service.PostSortMostCommented, Description: "按评论数倒序"},
+		},
+	})
+
+	postsArgs := pageArgs()
+	postsArgs["sort"] = &gql.ArgumentConfig{Type: postSortType, DefaultValue: service.PostSortLatest, Description: "排序"}
+	query := gql.NewObject(gql.ObjectConfig{
+		Name: "Query",
+		Fields: gql.Fields{
+			"posts": &gql.Field{
+				Type:        gql.NewNonNull(postConnectionType),
+				Description: "文章分页",
+				Args:        postsArgs,
+				Resolve:     resolver(r.postList),
+			},
+			"post": &gql.Field{
+				Type:        postType,
+				Description: "文章详情 不记录浏览量",
+				Args:        idArgs("id"),
+				Resolve:     resolver(r.post),
+			},
+			"user": &gql.Field{
+				Type:        userType,
+				Description: "用户公开信息 不存在时为null",
+				Args:        idArgs("id"),
+				Resolve: resolver(func(p gql.ResolveParams) (interface{}, error) {
+					userID, err := parseID(p.Args["id"])
+					if err != nil {
+						return nil, err
+					}
+					return fromContext(p.Context).users.load(p.Context, userID), nil
+				}),
+			},
+			"me": &gql.Field{
+				Type:        userType,
+				Description: "当前登录用户 未登录为null",
+				Resolve: resolver(func(p gql.ResolveParams) (interface{}, error) {
+					info := fromContext(p.Context)
+					if info.viewerID == 0 {
+						return nil, nil
+					}
+					return info.users.load(p.Context, info.viewerID), nil
+				}),
+			},
+		},
+	})
+
+	mutation := gql.NewObject(gql.ObjectConfig{
+		Name: "Mutation",
+		Fields: gql.Fields{
+			"createPost": &gql.Field{
+				Type:        gql.NewNonNull(postType),
+				Description: "创建文章 需要登录",
+				Args: gql.FieldConfigArgument{
+					"title":   &gql.ArgumentConfig{Type: gql.NewNonNull(gql.String), Description: "标题"},
+					"content": &gql.ArgumentConfig{Type: gql.NewNonNull(gql.String), Description: "内容"},
+				},
+				Resolve: resolver(r.createPost),
+			},
+			"updatePost": &gql.Field{
+				Type:        gql.NewNonNull(postType),
+				Description: "更新文章 需要登录 只能更新自己的文章 只更新传入的字段",
+				Args: gql.FieldConfigArgument{
+					"id":      &gql.ArgumentConfig{Type: gql.NewNonNull(gql.ID), Description: "文章ID"},
+					"title":   &gql.ArgumentConfig{Type: gql.String, Description: "标题"},
+					"content": &gql.ArgumentConfig{Type: gql.String, Description: "内容"},
+				},
+				Resolve: resolver(r.updatePost),
+			},
+			"deletePost": &gql.Field{
+				Type:        gql.NewNonNull(gql.Boolean),
+				Description: "删除文章 需要登录 文章作者和管理员可以删除",
+				Args:        idArgs("id"),
+				Resolve:     resolver(r.deletePost),
+			},
+			"createComment": &gql.Field{
+				Type:        gql.NewNonNull(commentType),
+				Description: "创建评论 需要登录",
+				Args: gql.FieldConfigArgument{
+					"postId":  &gql.ArgumentConfig{Type: gql.NewNonNull(gql.ID), Description: "文章ID"},
+					"content": &gql.ArgumentConfig{Type: gql.NewNonNull(gql.String), Description: "评论内容"},
+				},
+				Resolve: resolver(r.createComment),
+			},
+			"deleteComment": &gql.Field{
+				Type:        gql.NewNonNull(gql.Boolean),
+				Description: "删除评论 需要登录 文章作者、评论作者和管理员可以删除",
+				Args:        idArgs("id"),
+				Resolve:     resolver(r.deleteComment),
+			},
+		},
+	})
+
+	return gql.NewSchema(gql.SchemaConfig{Query: query, Mutation: mutation})
+}
+
+// 分页结果的字段
+func connectionFields(itemType *gql.Object) gql.Fields {
+	return gql.Fields{
+		"list":     &gql.Field{Type: gql.NewNonNull(gql.NewList(gql.NewNonNull(itemType))), Description: "当前页数据"},
+		"total":    &gql.Field{Type: gql.NewNonNull(gql.Int), Description: "总数"},
+		"page":     &gql.Field{Type: gql.NewNonNull(gql.Int), Description: "页码"},
+		"pageSize": &gql.Field{Type: gql.NewNonNull(gql.Int), Description: "每页数量"},
+	}
+}
+
+// 分页参数
+func pageArgs() gql.FieldConfigArgument {
+	return gql.FieldConfigArgument{
+		"page":     &gql.ArgumentConfig{Type: gql.Int, DefaultValue: 1, Description: "页码"},
+		"pageSize": &gql.ArgumentConfig{Type: gql.Int, DefaultValue: defaultPageSize, Description: "每页数量 最大100"},
+	}
+}
+
+// 必填的ID参数
+func idArgs(name string) gql.FieldConfigArgument {
+	return gql.FieldConfigArgument{
+		name: &gql.ArgumentConfig{Type: gql.NewNonNull(gql.ID)},
+	}
+}
+
+// ID参数转换为数字ID
+func parseID(value interface{}) (uint, error) {
+	id, err := strconv.ParseUint(value.(string), 10, 64)
+	if err != nil || id == 0 {
+		return 0, errcode.ErrInvalidParams
+	}
+	return uint(id), nil
+}
+
+// 可选的字符串参数 未传入时为空
+func stringArg(args map[string]interface{}, name string) string {
+	value, _ := args[name].(string)
+	return value
+}
+
+// 可选的整数参数 未传入时为0
+func intArg(args map[string]interface{}, name string) int {
+	value, _ := args[name].(int)
+	return value
+}
+
+// 按请求结构体的binding规则校验参数 与HTTP接口一致
+func validate(req interface{}) error {
+	return binding.Validator.ValidateStruct(req)
+}
+
+func (r *resolvers) postList(p gql.ResolveParams) (interface{}, error) {
+	req := service.GetPostListRequest{
+		Page:     intArg(p.Args, "page"),
+		PageSize: intArg(p.Args, "pageSize"),
+		Sort:     stringArg(p.Args, "sort"),
+	}
+	if err := validate(&req); err != nil {
+		return nil, err
+	}
+
+	posts, total, err := r.posts.GetPostList(p.Context, &req, fromContext(p.Context).viewerID)
+	if err != nil {
+		return nil, err
+	}
+	return &connection{List: posts, Total: total, Page: req.Page, PageSize: req.PageSize}, nil
+}
+
+func (r *resolvers) postComments(p gql.ResolveParams) (interface{}, error) {
+	req := service.GetCommentListRequest{
+		PostID:   p.Source.(service.PostResponse).ID,
+		Page:     intArg(p.Args, "page"),
+		PageSize: intArg(p.Args, "pageSize"),
+	}
+	if err := validate(&req); err != nil {
+		return nil, err
+	}
+
+	//列表中每篇文章的评论一起查询 没有评论的文章返回空列表
+	load := fromContext(p.Context).commentPages(req.Page, req.PageSize).load(p.Context, req.PostID)
+	return func() (interface{}, error) {
+		value, err := load()
+		if err != nil {
+			return nil, err
+		}
+		commentPage := service.CommentPage{List: []service.CommentWithUserResponse{}}
+		if value != nil {
+			commentPage = value.(service.CommentPage)
+		}
+		return &connection{List: commentPage.List, Total: commentPage.Total, Page: req.Page, PageSize: req.PageSize}, nil
+	}, nil
+}
+
+func (r *resolvers) post(p gql.ResolveParams) (interface{}, error) {
+	postID, err := parseID(p.Args["id"])
+	if err != nil {
+		return nil, err
+	}
+	return r.findPost(p.Context, postID)
+}
+
+func (r *resolvers) createPost(p gql.ResolveParams) (interface{}, error) {
+	viewerID, err := requireViewer(p.Context)
+	if err != nil {
+		return nil, err
+	}
+	req := service.CreatePostRequest{
+		Title:   stringArg(p.Args, "title"),
+		Content: stringArg(p.Args, "content"),
+	}
+	if err := validate(&req); err != nil {
+		return nil, err
+	}
+
+	post, err := r.posts.CreatePost(p.Context, &req, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	return *post, nil
+}
+
+func (r *resolvers) updatePost(p gql.ResolveParams) (interface{}, error) {
+	viewerID, err := requireViewer(p.Context)
+	if err != nil {
+		return nil, err
+	}
+	postID, err := parseID(p.Args["id"])
+	if err != nil {
+		return nil, err
+	}
+	req := service.UpdatePostRequest{
+		PostID:  postID,
+		Title:   stringArg(p.Args, "title"),
+		Content: stringArg(p.Args, "content"),
+	}
+	if err := validate(&req); err != nil {
+		return nil, err
+	}
+
+	if _, err := r.posts.UpdatePost(p.Context, &req, viewerID); err != nil {
+		return nil, err
+	}
+	return r.findPost(p.Context, postID)
+}
+
+func (r *resolvers) deletePost(p gql.ResolveParams) (interface{}, error) {
+	viewerID, err := requireViewer(p.Context)
+	if err != nil {
+		return nil, err
+	}
+	postID, err := parseID(p.Args["id"])
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.posts.DeletePost(p.Context, &service.DeletePostRequest{PostID: postID}, viewerID); err != nil {
+		return nil, err
+	}
+	return true, nil
+}
+
+func (r *resolvers) createComment(p gql.ResolveParams) (interface{}, error) {
+	viewerID, err := requireViewer(p.Context)
+	if err != nil {
+		return nil, err
+	}
+	postID, err := parseID(p.Args["postId"])
+	if err != nil {
+		return nil, err
+	}
+	req := service.CreateCommentRequest{
+		PostID:  postID,
+		Content: stringArg(p.Args, "content"),
+	}
+	if err := validate(&req); err != nil {
+		return nil, err
+	}
+
+	comment, err := r.comments.CreateComment(p.Context, &req, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	//评论用户通过加载器获取 这里只需要评论本身的字段
+	return service.CommentWithUserResponse{
+		ID:        comment.ID,
+		PostID:    comment.PostID,
+		UserID:    comment.UserID,
+		Content:   comment.Content,
+		CreatedAt: comment.CreatedAt,
+	}, nil
+}
+
+func (r *resolvers) deleteComment(p gql.ResolveParams) (interface{}, error) {
+	viewerID, err := requireViewer(p.Context)
+	if err != nil {
+		return nil, err
+	}
+	commentID, err := parseID(p.Args["id"])
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.comments.DeleteComment(p.Context, &service.DeleteCommentRequest{CommentID: commentID}, viewerID); err != nil {
+		return nil, err
+	}
+	return true, nil
+}
+
+// 文章不存在时返回null和错误
+func (r *resolvers) findPost(ctx context.Context, postID uint) (interface{}, error) {
+	post, err := r.posts.GetPost(ctx, postID, fromContext(ctx).viewerID)
+	if err != nil {
+		return nil, err
+	}
+	return *post, nil
+}
@@ -0,0 +1,64 @@
+package openapi
+
+/**
+ * @Description: 检查注册的路由和接口文档是否一致 文档中的接口都要注册 basePath下注册的路由都要写在文档中
+ */
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+/**
+ * @Description: 检查路由和接口文档是否一致
+ * @param routes gin注册的路由
+ * @param ignore 不写在文档中的路由前缀 如gRPC gateway
+ * @return error 不一致时列出缺少的接口和未写入文档的路由
+ */
+func (s *Spec) CheckRoutes(routes gin.RoutesInfo, ignore ...string) error {
+	registered := make(map[string]bool, len(routes))
+	var undocumented []string
+	for _, r := range routes {
+		if ignored(r.Path, ignore) {
+			continue
+		}
+		path, ok := s.specPath(r.Path)
+		if !ok {
+			continue
+		}
+		key := routeKey(r.Method, path)
+		registered[key] = true
+		if _, ok := s.routes[key]; !ok {
+			undocumented = append(undocumented, key)
+		}
+	}
+	var unregistered []string
+	for key := range s.routes {
+		if !registered[key] {
+			unregistered = append(unregistered, key)
+		}
+	}
+
+	var errs []error
+	if len(undocumented) > 0 {
+		sort.Strings(undocumented)
+		errs = append(errs, fmt.Errorf("路由没有写在接口文档中: %s", strings.Join(undocumented, ", ")))
+	}
+	if len(unregistered) > 0 {
+		sort.Strings(unregistered)
+		errs = append(errs, fmt.Errorf("接口文档中的接口没有注册路由: %s", strings.Join(unregistered, ", ")))
+	}
+	return errors.Join(errs...)
+}
+
+func ignored(path string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,141 @@
+package openapi
+
+/**
+ * @Description: 文档校验错误转换为字段错误 能对应到validator规则的使用相同的规则名和提示 其余返回schema规则和校验器的原始提示
+ */
+import (
+	"errors"
+	"homework4/internal/validation"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+)
+
+// JSON Schema校验错误的格式 如at '/title': minLength: got 0, want 1
+var schemaReason = regexp.MustCompile(`at '([^']*)': (.+)$`)
+
+// 校验错误对应的validator规则 第一个分组为规则参数
+var schemaRules = []struct {
+	pattern *regexp.Regexp
+	rule    string
+	kind    reflect.Kind
+}{
+	{regexp.MustCompile(`^missing propert(?:y|ies) '([^']+)'`), "required", reflect.String},
+	{regexp.MustCompile(`^minLength: got \d+, want (\d+)$`), "min", reflect.String},
+	{regexp.MustCompile(`^maxLength: got \d+, want (\d+)$`), "max", reflect.String},
+	{regexp.MustCompile(`^minItems: got \d+, want (\d+)$`), "min", reflect.Slice},
+	{regexp.MustCompile(`^maxItems: got \d+, want (\d+)$`), "max", reflect.Slice},
+	{regexp.MustCompile(`^minimum: got \S+, want (\S+)$`), "min", reflect.Float64},
+	{regexp.MustCompile(`^maximum: got \S+, want (\S+)$`), "max", reflect.Float64},
+	{regexp.MustCompile(`^value must be one of (.+)$`), "oneof", reflect.String},
+	{regexp.MustCompile(`^got \S+, want (\S+)$`), validation.RuleType, reflect.String},
+}
+
+/**
+ * @Description: 请求校验错误转换为字段错误 只返回第一个错误
+ * @param err ValidateRequest返回的错误
+ * @return *validation.FieldError
+ */
+func requestFieldError(err error) *validation.FieldError {
+	var reqErr *openapi3filter.RequestError
+	if !errors.As(err, &reqErr) {
+		return validation.NewFieldError(validation.FieldBody, validation.RuleSchema, err.Error(), reflect.Invalid)
+	}
+
+	field := validation.FieldBody
+	if reqErr.Parameter != nil {
+		field = reqErr.Parameter.Name
+	}
+	var parseErr *openapi3filter.ParseError
+	switch {
+	case errors.Is(reqErr.Err, openapi3filter.ErrInvalidRequired):
+		return validation.NewFieldError(field, "required", "", reflect.String)
+	case errors.As(reqErr.Err, &parseErr):
+		if reqErr.Parameter == nil {
+			return validation.NewFieldError(field, validation.RuleJSON, "", reflect.Invalid)
+		}
+		return validation.NewFieldError(field, validation.RuleType, parameterType(reqErr.Parameter), reflect.Invalid)
+	}
+
+	var schemaErr *openapi3.SchemaError
+	if !errors.As(reqErr.Err, &schemaErr) {
+		return validation.NewFieldError(field, validation.RuleSchema, reqErr.Error(), reflect.Invalid)
+	}
+	pointer, message := schemaMessage(schemaErr)
+	//请求体中的字段 JSON指针转换为点分隔的路径
+	if pointer = strings.Trim(pointer, "/"); pointer != "" {
+		if reqErr.Parameter != nil {
+			field += "." + strings.ReplaceAll(pointer, "/", ".")
+		} else {
+			field = strings.ReplaceAll(pointer, "/", ".")
+		}
+	}
+	for _, r := range schemaRules {
+		match := r.pattern.FindStringSubmatch(message)
+		if match == nil {
+			continue
+		}
+		param := match[1]
+		switch r.rule {
+		case "required":
+			//缺少的字段在错误位置的下一级
+			if field == validation.FieldBody {
+				field = param
+			} else {
+				field += "." + param
+			}
+			param = ""
+		case "oneof":
+			param = strings.NewReplacer("'", "", ", ", " ").Replace(param)
+		}
+		return validation.NewFieldError(field, r.rule, param, r.kind)
+	}
+	return validation.NewFieldError(field, validation.RuleSchema, message, reflect.Invalid)
+}
+
+/**
+ * @Description: 校验错误的简短说明 用于日志
+ * @param err ValidateRequest或ValidateResponse返回的错误
+ * @return string
+ */
+func reason(err error) string {
+	var schemaErr *openapi3.SchemaError
+	if !errors.As(err, &schemaErr) {
+		return err.Error()
+	}
+	pointer, message := schemaMessage(schemaErr)
+	if pointer == "" {
+		return message
+	}
+	return pointer + ": " + message
+}
+
+// 取出第一个具体的校验错误 返回出错的JSON指针和提示
+func schemaMessage(err *openapi3.SchemaError) (string, string) {
+	for {
+		var causes openapi3.MultiError
+		if err.Origin == nil || !errors.As(err.Origin, &causes) || len(causes) == 0 {
+			break
+		}
+		var cause *openapi3.SchemaError
+		if !errors.As(causes[0], &cause) {
+			break
+		}
+		err = cause
+	}
+	if match := schemaReason.FindStringSubmatch(err.Reason); match != nil {
+		return match[1], match[2]
+	}
+	return "", err.Reason
+}
+
+// 参数的类型 用于类型错误的提示
+func parameterType(parameter *openapi3.Parameter) string {
+	if parameter.Schema == nil || parameter.Schema.Value == nil {
+		return ""
+	}
+	return strings.Join(parameter.Schema.Value.Type.Slice(), "|")
+}
@@ -0,0 +1,114 @@
+package openapi
+
+/**
+ * @Description: OpenAPI 3.1接口文档 openapi.yaml是HTTP接口的唯一定义 新增或修改接口时先修改文档
+ * 启动时加载并校验文档 /api/v1/openapi.json返回JSON格式 请求和响应校验见validator.go 与路由的一致性检查见check.go
+ */
+import (
+	_ "embed"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/gin-gonic/gin"
+)
+
+//go:embed openapi.yaml
+var specYAML []byte
+
+type Spec struct {
+	doc      *openapi3.T
+	json     []byte                    // JSON格式的文档
+	basePath string                    // servers中的路径前缀 如/api
+	routes   map[string]*routers.Route // key为方法和文档中的路径 如GET /v2/posts/{id}
+}
+
+/**
+ * @Description: 加载并校验内嵌的接口文档
+ * @return (*Spec, error)
+ */
+func Load() (*Spec, error) {
+	loader := openapi3.NewLoader()
+	doc, err := loader.LoadFromData(specYAML)
+	if err != nil {
+		return nil, fmt.Errorf("解析接口文档失败: %w", err)
+	}
+	if err := doc.Validate(loader.Context); err != nil {
+		return nil, fmt.Errorf("接口文档不合法: %w", err)
+	}
+	data, err := doc.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Spec{doc: doc, json: data, routes: make(map[string]*routers.Route)}
+	var server *openapi3.Server
+	if len(doc.Servers) > 0 {
+		server = doc.Servers[0]
+		serverURL, err := url.Parse(server.URL)
+		if err != nil {
+			return nil, fmt.Errorf("接口文档servers地址错误: %w", err)
+		}
+		s.basePath = strings.TrimSuffix(serverURL.Path, "/")
+	}
+	for path, item := range doc.Paths.Map() {
+		for method, operation := range item.Operations() {
+			s.routes[routeKey(method, path)] = &routers.Route{
+				Spec:      doc,
+				Server:    server,
+				Path:      path,
+				PathItem:  item,
+				Method:    method,
+				Operation: operation,
+			}
+		}
+	}
+	return s, nil
+}
+
+/**
+ * @Description: 返回JSON格式的接口文档
+ * @return gin.HandlerFunc
+ */
+func (s *Spec) Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Data(http.StatusOK, "application/json; charset=utf-8", s.json)
+	}
+}
+
+/**
+ * @Description: 根据gin的路由查找文档中的接口
+ * @param method
+ * @param fullPath gin注册的路由 如/api/v2/posts/:id
+ * @return (*routers.Route, bool) 不在basePath下或文档中没有时返回false
+ */
+func (s *Spec) route(method string, fullPath string) (*routers.Route, bool) {
+	path, ok := s.specPath(fullPath)
+	if !ok {
+		return nil, false
+	}
+	route, ok := s.routes[routeKey(method, path)]
+	return route, ok
+}
+
+// gin的路由转换为文档中的路径 去掉basePath 路径参数:id和*path转换为{id}和{path}
+func (s *Spec) specPath(fullPath string) (string, bool) {
+	path, ok := strings.CutPrefix(fullPath, s.basePath)
+	if !ok || !strings.HasPrefix(path, "/") {
+		return "", false
+	}
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/"), true
+}
+
+func routeKey(method string, path string) string {
+	return strings.ToUpper(method) + " " + path
+}
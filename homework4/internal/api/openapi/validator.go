@@ -0,0 +1,141 @@
+package openapi
+
+/**
+ * @Description: 按接口文档校验请求和响应 文档中没有的路由不校验
+ * 请求不通过时返回参数错误 响应不通过时记录日志并返回500 响应校验需要缓存整个响应 只在测试环境开启
+ */
+import (
+	"bytes"
+	"homework4/config"
+	"homework4/internal/errcode"
+	"homework4/internal/middleware/response"
+	"homework4/pkg/logger"
+	"net/http"
+
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+/**
+ * @Description: 校验中间件 需要放在错误处理中间件之前 才能校验错误响应
+ * @param cfg
+ * @return gin.HandlerFunc
+ */
+func (s *Spec) Middleware(cfg config.OpenAPIConfig) gin.HandlerFunc {
+	options := &openapi3filter.Options{
+		AuthenticationFunc:    openapi3filter.NoopAuthenticationFunc, // 登录认证由auth中间件处理
+		SkipSettingDefaults:   true,                                  // 不修改请求 默认值由controller处理
+		IncludeResponseStatus: true,                                  // 响应状态码需要在文档中定义
+	}
+	return func(c *gin.Context) {
+		route, ok := s.route(c.Request.Method, c.FullPath())
+		if !ok {
+			c.Next()
+			return
+		}
+		ctx := c.Request.Context()
+		pathParams := make(map[string]string, len(c.Params))
+		for _, param := range c.Params {
+			pathParams[param.Key] = param.Value
+		}
+		input := &openapi3filter.RequestValidationInput{Request: c.Request, PathParams: pathParams, Route: route, Options: options}
+
+		if cfg.ValidateRequests {
+			if err := openapi3filter.ValidateRequest(ctx, input); err != nil {
+				response.SendErrorJSON(c, response.NewValidationError(requestFieldError(err)))
+				c.Abort()
+				return
+			}
+		}
+		if !cfg.ValidateResponses {
+			c.Next()
+			return
+		}
+
+		writer := &bufferedWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		c.Next()
+		c.Writer = writer.ResponseWriter
+
+		output := &openapi3filter.ResponseValidationInput{
+			RequestValidationInput: input,
+			Status:                 writer.Status(),
+			Header:                 writer.Header(),
+			Options:                options,
+		}
+		output.SetBodyBytes(writer.body.Bytes())
+		if err := openapi3filter.ValidateResponse(ctx, output); err != nil {
+			logger.FromContext(ctx).Error("响应与接口文档不一致",
+				zap.String("reason", reason(err)),
+				zap.Int("status", writer.Status()),
+				zap.String("path", c.Request.URL.Path),
+				zap.String("method", c.Request.Method),
+			)
+			response.SendErrorJSON(c, errcode.ErrResponseMismatch)
+			return
+		}
+		writer.flush()
+	}
+}
+
+// 缓存状态码和响应体 校验通过后再写出
+type bufferedWriter struct {
+	gin.ResponseWriter
+	status  int
+	written bool
+	body    bytes.Buffer
+}
+
+func (w *bufferedWriter) WriteHeader(code int) {
+	if code > 0 {
+		w.status = code
+	}
+}
+
+func (w *bufferedWriter) WriteHeaderNow() {
+	w.written = true
+}
+
+func (w *bufferedWriter) Write(data []byte) (int, error) {
+	w.written = true
+	return w.body.Write(data)
+}
+
+func (w *bufferedWriter) WriteString(s string) (int, error) {
+	w.written = true
+	return w.body.WriteString(s)
+}
+
+// Flush 缓存期间不向客户端发送
+func (w *bufferedWriter) Flush() {}
+
+func (w *bufferedWriter) Status() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
+
+func (w *bufferedWriter) Size() int {
+	if !w.written {
+		return -1
+	}
+	return w.body.Len()
+}
+
+func (w *bufferedWriter) Written() bool {
+	return w.written
+}
+
+// 写出缓存的状态码和响应体
+func (w *bufferedWriter) flush() {
+	w.ResponseWriter.WriteHeader(w.Status())
+	if !w.written {
+		return
+	}
+	w.ResponseWriter.WriteHeaderNow()
+	if w.body.Len() > 0 {
+		w.ResponseWriter.Write(w.body.Bytes())
+	}
+}
@@ -1,227 +1,198 @@
 package route
 
 /**
- * @Description: 路由管理
+ * @Description: 路由管理 /api下的接口都要写在接口文档internal/api/openapi/openapi.yaml中 用openapi check命令检查
  */
 import (
-	"homework4/internal/controller"
-	"homework4/internal/middleware/auth"
+	"homework4/internal/app"
+	"homework4/internal/metrics"
+	"homework4/internal/middleware/deprecation"
 	"homework4/internal/middleware/logger"
+	"homework4/internal/middleware/requestid"
 	"homework4/internal/middleware/response"
-	"time"
-
-	"github.com/gin-contrib/cors"
 
 	"github.com/gin-gonic/gin"
-	swaggerFiles "github.com/swaggo/files"
-	ginSwagger "github.com/swaggo/gin-swagger"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
-var userController *controller.UserController
-var postController *controller.PostController
-var commentController *controller.CommentController
-
-// Register godoc
-// @Summary 用户注册
-// @Description 创建新用户账号
-// @Tags 用户管理
-// @Accept json
-// @Produce json
-// @Param request body service.RegisterRequest true "注册信息"
-// @Success 200 {object} response.Response{data=map[string]interface{}} "注册成功"
-// @Failure 400 {object} response.Response "参数错误"
-// @Router /user/register [post]
-func RegisterHandler(c *gin.Context) {
-	response.WrapHandler(userController.Register)(c)
-}
-
-// Login godoc
-// @Summary 用户登录
-// @Description 用户登录获取JWT token
-// @Tags 用户管理
-// @Accept json
-// @Produce json
-// @Param request body service.LoginRequest true "登录信息"
-// @Success 200 {object} response.Response{data=service.LoginResponse} "登录成功"
-// @Failure 400 {object} response.Response "参数错误或登录失败"
-// @Router /user/login [post]
-func LoginHandler(c *gin.Context) {
-	response.WrapHandler(userController.Login)(c)
-}
-
-// CreatePost godoc
-// @Summary 创建文章
-// @Description 创建新文章,需要登录
-// @Tags 文章管理
-// @Accept json
-// @Produce json
-// @Param request body service.CreatePostRequest true "文章信息"
-// @Security Bearer
-// @Success 200 {object} response.Response{data=service.PostResponse} "创建成功"
-// @Failure 400 {object} response.Response "参数错误"
-// @Failure 401 {object} response.Response "未授权"
-// @Router /post/create [post]
-func CreatePostHandler(c *gin.Context) {
-	response.WrapHandler(postController.CreatePost)(c)
-}
-
-// UpdatePost godoc
-// @Summary 更新文章
-// @Description 更新文章信息,需要登录
-// @Tags 文章管理
-// @Accept json
-// @Produce json
-// @Param request body service.UpdatePostRequest true "文章信息"
-// @Security Bearer
-// @Success 200 {object} response.Response{data=service.PostResponse} "更新成功"
-// @Failure 400 {object} response.Response "参数错误"
-// @Failure 401 {object} response.Response "未授权"
-// @Router /post/update [put]
-func UpdatePostHandler(c *gin.Context) {
-	response.WrapHandler(postController.UpdatePost)(c)
-}
-
-// DeletePost godoc
-// @Summary 删除文章
-// @Description 删除文章,需要登录
-// @Tags 文章管理
-// @Accept json
-// @Produce json
-// @Param request body service.DeletePostRequest true "文章ID"
-// @Security Bearer
-// @Success 200 {object} response.Response{data=map[string]interface{}} "删除成功"
-// @Failure 400 {object} response.Response "参数错误"
-// @Failure 401 {object} response.Response "未授权"
-// @Router /post/delete [delete]
-func DeletePostHandler(c *gin.Context) {
-	response.WrapHandler(postController.DeletePost)(c)
-}
-
-// GetPostList godoc
-// @Summary 获取文章列表
-// @Description 分页获取文章列表,不需要登录
-// @Tags 文章管理
-// @Accept json
-// @Produce json
-// @Param page query int false "页码" default(1)
-// @Param pageSize query int false "每页数量" default(10)
-// @Success 200 {object} response.Response{data=map[string]interface{}} "获取成功"
-// @Failure 400 {object} response.Response "参数错误"
-// @Router /post/list [get]
-func GetPostListHandler(c *gin.Context) {
-	response.WrapHandler(postController.GetPostList)(c)
-}
+// UndocumentedPrefixes 不写在接口文档中的路由 gRPC gateway的接口定义见proto目录
+var UndocumentedPrefixes = []string{"/api/rpc/"}
 
-// CreateComment godoc
-// @Summary 创建评论
-// @Description 为文章创建评论,需要登录
-// @Tags 评论管理
-// @Accept json
-// @Produce json
-// @Param request body service.CreateCommentRequest true "评论信息"
-// @Security Bearer
-// @Success 200 {object} response.Response{data=service.CommentResponse} "创建成功"
-// @Failure 400 {object} response.Response "参数错误"
-// @Failure 401 {object} response.Response "未授权"
-// @Router /comment/create [post]
-func CreateCommentHandler(c *gin.Context) {
-	response.WrapHandler(commentController.CreateComment)(c)
-}
-
-// GetCommentList godoc
-// @Summary 获取评论列表
-// @Description 分页获取文章评论列表,不需要登录
-// @Tags 评论管理
-// @Accept json
-// @Produce json
-// @Param postId query int true "文章ID"
-// @Param page query int false "页码" default(1)
-// @Param pageSize query int false "每页数量" default(10)
-// @Success 200 {object} response.Response{data=map[string]interface{}} "获取成功"
-// @Failure 400 {object} response.Response "参数错误"
-// @Router /comment/list [get]
-func GetCommentListHandler(c *gin.Context) {
-	response.WrapHandler(commentController.GetCommentList)(c)
-}
-
-func InitRoutes() *gin.Engine {
+// InitRoutes 根据应用容器注册路由
+func InitRoutes(container *app.Container) *gin.Engine {
 	// 注册路由
 	r := gin.Default()
+	//只信任配置的代理 否则客户端可以伪造X-Forwarded-For绕过按IP限流
+	//格式已经在配置校验中检查 设置失败说明代码有误
+	if err := r.SetTrustedProxies(container.Config.App.TrustedProxies); err != nil {
+		panic(err)
+	}
 
-	//配置跨域
-	r.Use(cors.New(cors.Config{
-		// 当前设置允许全部
-		AllowOrigins:     []string{"*"},
-		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}, // 允许的请求方法
-		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"}, // 允许的请求头
-		ExposeHeaders:    []string{"Content-Length"},
-		AllowCredentials: true,           // 允许携带 Cookie
-		MaxAge:           12 * time.Hour, // 预检请求缓存时间
-	}))
-
-	//初始化gin路径
-	// 使用中间件
+	//链路追踪 解析traceparent请求头 每个请求一个span
+	r.Use(otelgin.Middleware(container.Config.Tracing.ServiceName))
+	//请求ID 放入响应头和请求上下文的日志记录器
+	r.Use(requestid.Middleware())
+	//统计请求数和耗时
+	r.Use(metrics.Middleware())
+	//请求日志 需要在跨域和限流之前 被拒绝的请求也记录
 	r.Use(logger.LoggerMiddleware())
+	//配置跨域 规则由cors配置决定 重新加载配置后生效
+	r.Use(container.CORS.Middleware())
+	//全局按IP限流 路由组按rateLimit.policies中的策略限流
+	rateLimiter := container.RateLimiter
+	r.Use(rateLimiter.Middleware())
+	//创建接口携带Idempotency-Key时只处理一次 需要放在登录认证之后
+	idempotent := container.Idempotency.Middleware()
+
+	//按接口文档校验请求和响应 需要在错误处理之前 才能校验错误响应
+	if cfg := container.Config.OpenAPI; cfg.ValidateRequests || cfg.ValidateResponses {
+		r.Use(container.OpenAPI.Middleware(cfg))
+	}
 	r.Use(response.ErrorHandlerMiddleware())
 	r.Use(gin.Recovery())
 
-	// 初始化服务
-	userController = controller.NewUserController()
-	postController = controller.NewPostController()
-	commentController = controller.NewCommentController()
+	// 使用容器中的认证
+	authenticator := container.Auth
 
-	// API路由组
+	//没有管理端口时在业务端口暴露指标 需要令牌
+	if cfg := container.Config.Metrics; cfg.Enabled && cfg.AdminPort == 0 {
+		r.GET("/metrics", metrics.ProtectedHandler(cfg.Token))
+	}
+
+	// 存活和就绪检查 不在/api/v1下 供负载均衡和容器编排探测
+	r.GET("/livez", response.WrapHandler(container.HealthController.Livez))
+	r.GET("/readyz", response.WrapHandler(container.HealthController.Readyz))
+
+	// API路由组 v1已弃用 响应带弃用和下线日期
 	api := r.Group("/api/v1")
+	api.Use(deprecation.Middleware(container.Config.API.V1Deprecation, container.Config.API.V1Sunset, "/api/v2"))
 	{
 		// 用户路由
 		userGroup := api.Group("/user")
+		userGroup.Use(rateLimiter.Policy("auth"))
+		{
+			userGroup.POST("/register", response.WrapHandler(container.UserController.Register))
+			userGroup.POST("/login", response.WrapHandler(container.UserController.Login))
+		}
+		// 用户公开资料路由不需要登录的 携带token时返回是否已关注
+		userProfileGroup := api.Group("/user")
+		userProfileGroup.Use(authenticator.OptionalAuthMiddleware())
 		{
-			userGroup.POST("/register", RegisterHandler)
-			userGroup.POST("/login", LoginHandler)
+			userProfileGroup.GET("/profile", response.WrapHandler(container.UserController.GetProfile))
+			userProfileGroup.GET("/followers", response.WrapHandler(container.FollowController.GetFollowers))
+			userProfileGroup.GET("/following", response.WrapHandler(container.FollowController.GetFollowing))
 		}
 
+		// 关注路由需要登录的
+		followGroup := api.Group("/follow")
+		followGroup.Use(authenticator.AuthMiddleware(), rateLimiter.Policy("follow"))
+		{
+			followGroup.POST("/add", response.WrapHandler(container.FollowController.Follow))
+			followGroup.DELETE("/remove", response.WrapHandler(container.FollowController.Unfollow))
+		}
+
+		// 首页动态需要登录
+		api.GET("/feed", authenticator.AuthMiddleware(), response.WrapHandler(container.PostController.GetFeed))
+
 		// 文章路由需要登录的
 		articleGroupNeedLogin := api.Group("/post")
-		articleGroupNeedLogin.Use(auth.AuthMiddleware())
+		articleGroupNeedLogin.Use(authenticator.AuthMiddleware(), rateLimiter.Policy("post"))
 		{
-			articleGroupNeedLogin.POST("/create", CreatePostHandler)
-			articleGroupNeedLogin.PUT("/update", UpdatePostHandler)
-			articleGroupNeedLogin.DELETE("/delete", DeletePostHandler)
+			articleGroupNeedLogin.POST("/create", idempotent, response.WrapHandler(container.PostController.CreatePost))
+			articleGroupNeedLogin.PUT("/update", response.WrapHandler(container.PostController.UpdatePost))
+			articleGroupNeedLogin.DELETE("/delete", response.WrapHandler(container.PostController.DeletePost))
+			articleGroupNeedLogin.DELETE("/batchDelete", response.WrapHandler(container.PostController.BatchDeletePosts))
 		}
-		// 文章路由不需要登录的
+		// 文章路由不需要登录的 携带token时返回当前用户的点赞状态
 		articleGroup := api.Group("/post")
+		articleGroup.Use(authenticator.OptionalAuthMiddleware())
 		{
-			articleGroup.GET("/list", GetPostListHandler)
+			articleGroup.GET("/list", response.WrapHandler(container.PostController.GetPostList))
+			articleGroup.GET("/hot", response.WrapHandler(container.PostController.GetHotPostList))
+			articleGroup.GET("/detail", response.WrapHandler(container.PostController.GetPostDetail))
 		}
 
 		// 评论路由需要登录的
 		commentGroupNeedLogin := api.Group("/comment")
-		commentGroupNeedLogin.Use(auth.AuthMiddleware())
+		commentGroupNeedLogin.Use(authenticator.AuthMiddleware(), rateLimiter.Policy("comment"))
 		{
-			commentGroupNeedLogin.POST("/create", CreateCommentHandler)
+			commentGroupNeedLogin.POST("/create", idempotent, response.WrapHandler(container.CommentController.CreateComment))
+			commentGroupNeedLogin.DELETE("/delete", response.WrapHandler(container.CommentController.DeleteComment))
+			commentGroupNeedLogin.DELETE("/batchDelete", response.WrapHandler(container.CommentController.BatchDeleteComments))
 		}
-		// 评论路由不需要登录的
+		// 评论路由不需要登录的 携带token时返回当前用户的点赞状态
 		commentGroup := api.Group("/comment")
+		commentGroup.Use(authenticator.OptionalAuthMiddleware())
+		{
+			commentGroup.GET("/list", response.WrapHandler(container.CommentController.GetCommentList))
+		}
+
+		// 点赞/表情回应路由需要登录的
+		reactionGroup := api.Group("/reaction")
+		reactionGroup.Use(authenticator.AuthMiddleware(), rateLimiter.Policy("reaction"))
+		{
+			reactionGroup.POST("/add", response.WrapHandler(container.ReactionController.AddReaction))
+			reactionGroup.DELETE("/remove", response.WrapHandler(container.ReactionController.RemoveReaction))
+		}
+
+		// 收藏路由需要登录的
+		bookmarkGroup := api.Group("/bookmark")
+		bookmarkGroup.Use(authenticator.AuthMiddleware())
 		{
-			commentGroup.GET("/list", GetCommentListHandler)
+			bookmarkGroup.POST("/add", response.WrapHandler(container.BookmarkController.AddBookmark))
+			bookmarkGroup.DELETE("/remove", response.WrapHandler(container.BookmarkController.RemoveBookmark))
+			bookmarkGroup.PUT("/reorder", response.WrapHandler(container.BookmarkController.ReorderBookmarks))
+			bookmarkGroup.GET("/list", response.WrapHandler(container.BookmarkController.GetBookmarkList))
 		}
 
-		// 健康检查
-		// @Summary 健康检查
-		// @Description 检查服务是否正常运行
-		// @Tags 系统管理
-		// @Produce json
-		// @Success 200 {object} map[string]string "服务正常"
-		// @Router /health [get]
-		api.GET("/health", func(c *gin.Context) {
-			c.JSON(200, gin.H{
-				"status":  "ok",
-				"message": "Blog API is running",
-			})
-		})
-
-		// Swagger文档路由
-		api.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+		// 阅读清单路由需要登录的
+		readingListGroupNeedLogin := api.Group("/readingList")
+		readingListGroupNeedLogin.Use(authenticator.AuthMiddleware())
+		{
+			readingListGroupNeedLogin.POST("/create", idempotent, response.WrapHandler(container.BookmarkController.CreateReadingList))
+			readingListGroupNeedLogin.PUT("/update", response.WrapHandler(container.BookmarkController.UpdateReadingList))
+			readingListGroupNeedLogin.DELETE("/delete", response.WrapHandler(container.BookmarkController.DeleteReadingList))
+			readingListGroupNeedLogin.GET("/mine", response.WrapHandler(container.BookmarkController.GetMyReadingLists))
+		}
+		// 阅读清单路由不需要登录的 携带token时可以查看自己的非公开清单
+		readingListGroup := api.Group("/readingList")
+		readingListGroup.Use(authenticator.OptionalAuthMiddleware())
+		{
+			readingListGroup.GET("/bookmarks", response.WrapHandler(container.BookmarkController.GetReadingListBookmarks))
+		}
+
+		// 兼容旧的健康检查地址 返回就绪检查结果
+		api.GET("/health", response.WrapHandler(container.HealthController.Readyz))
+
+		// OpenAPI接口文档
+		api.GET("/openapi.json", container.OpenAPI.Handler())
+	}
+
+	// v2路由 资源地址中带ID 与v1共用service
+	postV2Controller := container.PostV2Controller
+	v2 := r.Group("/api/v2")
+	{
+		posts := v2.Group("/posts")
+		posts.GET("", authenticator.OptionalAuthMiddleware(), response.WrapHandler(postV2Controller.ListPosts))
+		posts.POST("", authenticator.AuthMiddleware(), rateLimiter.Policy("post"), idempotent, response.WrapHandler(postV2Controller.CreatePost))
+		posts.GET("/:id", authenticator.OptionalAuthMiddleware(), response.WrapHandler(postV2Controller.GetPost))
+		posts.PATCH("/:id", authenticator.AuthMiddleware(), rateLimiter.Policy("post"), response.WrapHandler(postV2Controller.PatchPost))
+		posts.DELETE("/:id", authenticator.AuthMiddleware(), rateLimiter.Policy("post"), response.WrapHandler(postV2Controller.DeletePost))
+		posts.GET("/:id/comments", authenticator.OptionalAuthMiddleware(), response.WrapHandler(postV2Controller.ListComments))
+		posts.POST("/:id/comments", authenticator.AuthMiddleware(), rateLimiter.Policy("comment"), idempotent, response.WrapHandler(postV2Controller.CreateComment))
+
+		comments := v2.Group("/comments")
+		comments.GET("/:id", authenticator.OptionalAuthMiddleware(), response.WrapHandler(postV2Controller.GetComment))
+	}
+
+	// GraphQL 携带token时可以执行修改操作
+	if container.Config.GraphQL.Enabled {
+		r.POST("/api/graphql", authenticator.OptionalAuthMiddleware(), rateLimiter.Policy("graphql"), response.WrapHandler(container.GraphQLHandler.Handle))
+	}
+
+	// gRPC gateway 登录认证和错误转换由gRPC拦截器处理 接口定义见proto目录
+	if container.GRPCGateway != nil {
+		r.Any("/api/rpc/*path", gin.WrapH(container.GRPCGateway))
 	}
 
 	return r
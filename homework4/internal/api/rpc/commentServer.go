@@ -0,0 +1,88 @@
+package rpc
+
+import (
+	"context"
+	"homework4/internal/api/rpc/pb"
+	"homework4/internal/service"
+
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+/**
+ * @Description: 评论gRPC服务 与CommentController共用service
+ */
+type commentServer struct {
+	pb.UnimplementedCommentServiceServer
+	commentService *service.CommentService
+}
+
+func (s *commentServer) ListComments(ctx context.Context, req *pb.ListCommentsRequest) (*pb.ListCommentsResponse, error) {
+	listReq := service.GetCommentListRequest{
+		PostID:   uint(req.GetPostId()),
+		Page:     int(req.GetPage()),
+		PageSize: int(req.GetPageSize()),
+	}
+	if err := validate(&listReq); err != nil {
+		return nil, err
+	}
+
+	comments, total, err := s.commentService.GetCommentList(ctx, &listReq, viewerID(ctx))
+	if err != nil {
+		return nil, err
+	}
+	resp := &pb.ListCommentsResponse{List: make([]*pb.Comment, len(comments)), Total: total}
+	for i, comment := range comments {
+		resp.List[i] = &pb.Comment{
+			Id:        uint64(comment.ID),
+			PostId:    uint64(comment.PostID),
+			UserId:    uint64(comment.UserID),
+			Username:  comment.Username,
+			Nickname:  comment.Nickname,
+			Content:   comment.Content,
+			CreatedAt: comment.CreatedAt,
+			LikeCount: comment.LikeCount,
+			Liked:     comment.Liked,
+			Reactions: comment.Reactions,
+		}
+	}
+	return resp, nil
+}
+
+func (s *commentServer) CreateComment(ctx context.Context, req *pb.CreateCommentRequest) (*pb.Comment, error) {
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	createReq := service.CreateCommentRequest{PostID: uint(req.GetPostId()), Content: req.GetContent()}
+	if err := validate(&createReq); err != nil {
+		return nil, err
+	}
+
+	comment, err := s.commentService.CreateComment(ctx, &createReq, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.Comment{
+		Id:        uint64(comment.ID),
+		PostId:    uint64(comment.PostID),
+		UserId:    uint64(comment.UserID),
+		Content:   comment.Content,
+		CreatedAt: comment.CreatedAt,
+	}, nil
+}
+
+func (s *commentServer) DeleteComment(ctx context.Context, req *pb.DeleteCommentRequest) (*emptypb.Empty, error) {
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	deleteReq := service.DeleteCommentRequest{CommentID: uint(req.GetCommentId())}
+	if err := validate(&deleteReq); err != nil {
+		return nil, err
+	}
+
+	if err := s.commentService.DeleteComment(ctx, &deleteReq, userID); err != nil {
+		return nil, err
+	}
+	return &emptypb.Empty{}, nil
+}
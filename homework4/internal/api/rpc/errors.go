@@ -0,0 +1,94 @@
+package rpc
+
+/**
+ * @Description: 业务错误转换为gRPC状态 错误码放在ErrorInfo的reason中 提示按metadata中的accept-language本地化
+ * 参数校验错误在BadRequest中返回各字段的错误 没有错误码的错误只返回服务器内部错误
+ */
+import (
+	"context"
+	"errors"
+	"homework4/internal/errcode"
+	"homework4/internal/validation"
+	"homework4/pkg/logger"
+
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// ErrorDomain ErrorInfo中的错误来源
+const ErrorDomain = "homework4"
+
+var kindCodes = map[errcode.Kind]codes.Code{
+	errcode.KindInternal:        codes.Internal,
+	errcode.KindValidation:      codes.InvalidArgument,
+	errcode.KindUnauthorized:    codes.Unauthenticated,
+	errcode.KindForbidden:       codes.PermissionDenied,
+	errcode.KindNotFound:        codes.NotFound,
+	errcode.KindConflict:        codes.AlreadyExists,
+	errcode.KindUnprocessable:   codes.FailedPrecondition,
+	errcode.KindTooManyRequests: codes.ResourceExhausted,
+}
+
+// 转换为gRPC状态 已经是gRPC状态的错误原样返回
+func toStatus(ctx context.Context, err error) error {
+	if _, ok := err.(interface{ GRPCStatus() *status.Status }); ok {
+		return err
+	}
+	lang := language(ctx)
+	log := logger.FromContext(ctx)
+
+	var fieldErrs validator.ValidationErrors
+	var fieldErr *validation.FieldError
+	if errors.As(err, &fieldErrs) || errors.As(err, &fieldErr) {
+		badRequest := &errdetails.BadRequest{}
+		for _, detail := range validation.Details(err, lang) {
+			badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field:       detail.Field,
+				Description: detail.Message,
+				Reason:      detail.Rule,
+			})
+		}
+		return withDetails(status.New(codes.InvalidArgument, errcode.ErrInvalidParams.Message(lang)), errcode.ErrInvalidParams.Code, badRequest)
+	}
+
+	codeErr, ok := errcode.From(err)
+	if ok {
+		log.Error("业务错误", zap.String("error_code", codeErr.Code), zap.String("message", codeErr.Error()))
+	} else {
+		log.Error("服务器内部错误", logger.WrapMeta(err)...)
+	}
+	code, ok := kindCodes[codeErr.Kind]
+	if !ok {
+		code = codes.Internal
+	}
+	return withDetails(status.New(code, codeErr.Message(lang)), codeErr.Code)
+}
+
+// 附加错误码和其它详情 附加失败时只返回状态码和提示
+func withDetails(st *status.Status, code string, details ...*errdetails.BadRequest) error {
+	withInfo, err := st.WithDetails(&errdetails.ErrorInfo{Reason: code, Domain: ErrorDomain})
+	if err != nil {
+		return st.Err()
+	}
+	for _, detail := range details {
+		if withDetail, err := withInfo.WithDetails(detail); err == nil {
+			withInfo = withDetail
+		}
+	}
+	return withInfo.Err()
+}
+
+// 提示语言 通过gateway转发时请求头带grpcgateway-前缀
+func language(ctx context.Context) errcode.Lang {
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, key := range []string{"accept-language", "grpcgateway-accept-language"} {
+		if values := md.Get(key); len(values) > 0 {
+			return errcode.ParseAcceptLanguage(values[0])
+		}
+	}
+	return errcode.LangZH
+}
@@ -0,0 +1,78 @@
+package rpc
+
+/**
+ * @Description: grpc-gateway 在业务端口把/api/rpc/v1的JSON请求转发到gRPC服务 经过gRPC的拦截器 与内部服务共用处理方法
+ * Authorization和Accept-Language请求头由gateway转发 X-Request-ID转发为x-request-id 与HTTP日志使用同一个请求ID
+ */
+import (
+	"context"
+	"homework4/internal/api/rpc/pb"
+	"net/http"
+	"strings"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+type Gateway struct {
+	http.Handler
+	conn *grpc.ClientConn
+}
+
+/**
+ * @Description: 创建gateway 连接在第一次请求时建立
+ * @param endpoint gRPC服务地址 如127.0.0.1:9090
+ * @return (*Gateway, error)
+ */
+func NewGateway(endpoint string) (*Gateway, error) {
+	conn, err := grpc.NewClient(endpoint, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, err
+	}
+	mux := runtime.NewServeMux(
+		runtime.WithIncomingHeaderMatcher(headerMatcher),
+		runtime.WithErrorHandler(errorHandler),
+	)
+	ctx := context.Background()
+	for _, register := range []func(context.Context, *runtime.ServeMux, *grpc.ClientConn) error{
+		pb.RegisterUserServiceHandler,
+		pb.RegisterPostServiceHandler,
+		pb.RegisterCommentServiceHandler,
+	} {
+		if err := register(ctx, mux, conn); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	return &Gateway{Handler: mux, conn: conn}, nil
+}
+
+// 关闭到gRPC服务的连接
+func (g *Gateway) Close() error {
+	return g.conn.Close()
+}
+
+// 默认错误处理器把提示放在WWW-Authenticate中 提示可能是中文 改为标准的Bearer
+func errorHandler(ctx context.Context, mux *runtime.ServeMux, marshaler runtime.Marshaler, w http.ResponseWriter, r *http.Request, err error) {
+	runtime.DefaultHTTPErrorHandler(ctx, mux, marshaler, &challengeWriter{ResponseWriter: w}, r, err)
+}
+
+type challengeWriter struct {
+	http.ResponseWriter
+}
+
+func (w *challengeWriter) WriteHeader(statusCode int) {
+	if w.Header().Get("WWW-Authenticate") != "" {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+	}
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+// 请求ID不是标准请求头 默认不转发
+func headerMatcher(key string) (string, bool) {
+	if strings.EqualFold(key, "X-Request-ID") {
+		return requestIDKey, true
+	}
+	return runtime.DefaultHeaderMatcher(key)
+}
@@ -0,0 +1,206 @@
+package rpc
+
+/**
+ * @Description: gRPC拦截器 按顺序为请求ID和日志、指标、错误转换、异常恢复、登录认证
+ * 与HTTP中间件一致 处理方法只返回业务错误 由错误转换拦截器统一转换为gRPC状态
+ */
+import (
+	"context"
+	"fmt"
+	"homework4/internal/errcode"
+	"homework4/internal/middleware/auth"
+	"homework4/internal/middleware/requestid"
+	"homework4/pkg/logger"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// 请求ID的metadata key 与HTTP的X-Request-ID一致
+const requestIDKey = "x-request-id"
+
+// 替换流的上下文
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+// 沿用metadata中的请求ID 日志记录器追加request_id和method
+func newRequestContext(ctx context.Context, method string) (context.Context, string) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	var id string
+	if values := md.Get(requestIDKey); len(values) > 0 {
+		id = values[0]
+	}
+	id = requestid.Resolve(id)
+	ctx = requestid.NewContext(ctx, id)
+	return logger.With(ctx, zap.String("request_id", id), zap.String("route", method)), id
+}
+
+func logRequest(ctx context.Context, method string, start time.Time, err error) {
+	var addr string
+	if p, ok := peer.FromContext(ctx); ok {
+		addr = p.Addr.String()
+	}
+	var message string
+	if err != nil {
+		message = status.Convert(err).Message()
+	}
+	logger.FromContext(ctx).Info("gRPC请求",
+		zap.String("method", method),
+		zap.String("code", status.Code(err).String()),
+		zap.String("peer", addr),
+		zap.String("errors", message),
+		zap.Duration("cost", time.Since(start)),
+	)
+}
+
+func loggingUnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		ctx, id := newRequestContext(ctx, info.FullMethod)
+		_ = grpc.SetHeader(ctx, metadata.Pairs(requestIDKey, id))
+		resp, err := handler(ctx, req)
+		logRequest(ctx, info.FullMethod, start, err)
+		return resp, err
+	}
+}
+
+func loggingStreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		ctx, id := newRequestContext(ss.Context(), info.FullMethod)
+		_ = ss.SetHeader(metadata.Pairs(requestIDKey, id))
+		err := handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+		logRequest(ctx, info.FullMethod, start, err)
+		return err
+	}
+}
+
+func errorUnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		resp, err := handler(ctx, req)
+		if err != nil {
+			return nil, toStatus(ctx, err)
+		}
+		return resp, nil
+	}
+}
+
+func errorStreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := handler(srv, ss); err != nil {
+			return toStatus(ss.Context(), err)
+		}
+		return nil
+	}
+}
+
+// 异常转换为错误 与HTTP的WrapHandler一致
+func recoverError(r interface{}) error {
+	if err, ok := r.(error); ok {
+		return err
+	}
+	return fmt.Errorf("panic: %v", r)
+}
+
+func recoveryUnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				logger.FromContext(ctx).Error("gRPC处理异常", zap.Any("panic", r), zap.Stack("stack"))
+				resp, err = nil, recoverError(r)
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
+
+func recoveryStreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				logger.FromContext(ss.Context()).Error("gRPC处理异常", zap.Any("panic", r), zap.Stack("stack"))
+				err = recoverError(r)
+			}
+		}()
+		return handler(srv, ss)
+	}
+}
+
+/**
+ * @Description: 登录认证 metadata的authorization为Bearer {token}
+ * 需要登录的方法没有token或token无效时返回错误 其它方法token无效时按未登录处理
+ */
+type authInterceptor struct {
+	authenticator *auth.Authenticator
+	protected     map[string]bool // 需要登录的完整方法名
+}
+
+func (a *authInterceptor) authenticate(ctx context.Context, method string) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	var token string
+	if values := md.Get("authorization"); len(values) > 0 {
+		token = strings.TrimPrefix(values[0], "Bearer ")
+	}
+	if token == "" {
+		if a.protected[method] {
+			return nil, errcode.ErrTokenMissing
+		}
+		return ctx, nil
+	}
+
+	user, err := a.authenticator.Verify(ctx, token)
+	if err != nil {
+		if a.protected[method] {
+			return nil, err
+		}
+		return ctx, nil
+	}
+	ctx = auth.NewContext(ctx, user)
+	return logger.With(ctx, zap.Uint("user_id", user.UserID)), nil
+}
+
+func (a *authInterceptor) unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := a.authenticate(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+func (a *authInterceptor) stream() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := a.authenticate(ss.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// 当前登录用户ID 未登录为0
+func viewerID(ctx context.Context) uint {
+	user, _ := auth.FromContext(ctx)
+	return user.UserID
+}
+
+// 需要登录的方法中获取当前用户ID 认证拦截器已保证已登录
+func currentUserID(ctx context.Context) (uint, error) {
+	user, ok := auth.FromContext(ctx)
+	if !ok {
+		return 0, errcode.ErrTokenMissing
+	}
+	return user.UserID, nil
+}
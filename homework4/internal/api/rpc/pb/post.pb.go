@@ -0,0 +1,802 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.9
+// 	protoc        v5.29.3
+// source: blog/v1/post.proto
+
+// 文章服务 字段与service中的请求和响应一致
+
+package pb
+
+import (
+	_ "google.golang.org/genproto/googleapis/api/annotations"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// 文章排序
+type PostSort int32
+
+const (
+	PostSort_POST_SORT_UNSPECIFIED    PostSort = 0 // 默认按发布时间倒序
+	PostSort_POST_SORT_LATEST         PostSort = 1 // 按发布时间倒序
+	PostSort_POST_SORT_HOT            PostSort = 2 // 按热度倒序
+	PostSort_POST_SORT_MOST_COMMENTED PostSort = 3 // 按评论数倒序
+)
+
+// Enum value maps for PostSort.
+var (
+	PostSort_name = map[int32]string{
+		0: "POST_SORT_UNSPECIFIED",
+		1: "POST_SORT_LATEST",
+		2: "POST_SORT_HOT",
+		3: "POST_SORT_MOST_COMMENTED",
+	}
+	PostSort_value = map[string]int32{
+		"POST_SORT_UNSPECIFIED":    0,
+		"POST_SORT_LATEST":         1,
+		"POST_SORT_HOT":            2,
+		"POST_SORT_MOST_COMMENTED": 3,
+	}
+)
+
+func (x PostSort) Enum() *PostSort {
+	p := new(PostSort)
+	*p = x
+	return p
+}
+
+func (x PostSort) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (PostSort) Descriptor() protoreflect.EnumDescriptor {
+	return file_blog_v1_post_proto_enumTypes[0].Descriptor()
+}
+
+func (PostSort) Type() protoreflect.EnumType {
+	return &file_blog_v1_post_proto_enumTypes[0]
+}
+
+func (x PostSort) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use PostSort.Descriptor instead.
+func (PostSort) EnumDescriptor() ([]byte, []int) {
+	return file_blog_v1_post_proto_rawDescGZIP(), []int{0}
+}
+
+type Post struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	UserId        uint64                 `protobuf:"varint,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Title         string                 `protobuf:"bytes,3,opt,name=title,proto3" json:"title,omitempty"`
+	Content       string                 `protobuf:"bytes,4,opt,name=content,proto3" json:"content,omitempty"`
+	CreatedAt     string                 `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt     string                 `protobuf:"bytes,6,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	ViewCount     uint64                 `protobuf:"varint,7,opt,name=view_count,json=viewCount,proto3" json:"view_count,omitempty"`                                                           // 浏览量
+	CommentCount  uint32                 `protobuf:"varint,8,opt,name=comment_count,json=commentCount,proto3" json:"comment_count,omitempty"`                                                  // 评论数
+	HasComments   bool                   `protobuf:"varint,9,opt,name=has_comments,json=hasComments,proto3" json:"has_comments,omitempty"`                                                     // 是否有评论
+	LikeCount     int64                  `protobuf:"varint,10,opt,name=like_count,json=likeCount,proto3" json:"like_count,omitempty"`                                                          // 点赞数
+	Liked         bool                   `protobuf:"varint,11,opt,name=liked,proto3" json:"liked,omitempty"`                                                                                   // 当前用户是否点赞 未登录为false
+	Reactions     map[string]int64       `protobuf:"bytes,12,rep,name=reactions,proto3" json:"reactions,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"` // 各表情回应数
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Post) Reset() {
+	*x = Post{}
+	mi := &file_blog_v1_post_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Post) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Post) ProtoMessage() {}
+
+func (x *Post) ProtoReflect() protoreflect.Message {
+	mi := &file_blog_v1_post_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Post.ProtoReflect.Descriptor instead.
+func (*Post) Descriptor() ([]byte, []int) {
+	return file_blog_v1_post_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Post) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Post) GetUserId() uint64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *Post) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *Post) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+func (x *Post) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+func (x *Post) GetUpdatedAt() string {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return ""
+}
+
+func (x *Post) GetViewCount() uint64 {
+	if x != nil {
+		return x.ViewCount
+	}
+	return 0
+}
+
+func (x *Post) GetCommentCount() uint32 {
+	if x != nil {
+		return x.CommentCount
+	}
+	return 0
+}
+
+func (x *Post) GetHasComments() bool {
+	if x != nil {
+		return x.HasComments
+	}
+	return false
+}
+
+func (x *Post) GetLikeCount() int64 {
+	if x != nil {
+		return x.LikeCount
+	}
+	return 0
+}
+
+func (x *Post) GetLiked() bool {
+	if x != nil {
+		return x.Liked
+	}
+	return false
+}
+
+func (x *Post) GetReactions() map[string]int64 {
+	if x != nil {
+		return x.Reactions
+	}
+	return nil
+}
+
+type ListPostsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Page          int32                  `protobuf:"varint,1,opt,name=page,proto3" json:"page,omitempty"`                         // 页码 默认1
+	PageSize      int32                  `protobuf:"varint,2,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"` // 每页数量 默认10 最大100
+	Sort          PostSort               `protobuf:"varint,3,opt,name=sort,proto3,enum=blog.v1.PostSort" json:"sort,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListPostsRequest) Reset() {
+	*x = ListPostsRequest{}
+	mi := &file_blog_v1_post_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListPostsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPostsRequest) ProtoMessage() {}
+
+func (x *ListPostsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_blog_v1_post_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPostsRequest.ProtoReflect.Descriptor instead.
+func (*ListPostsRequest) Descriptor() ([]byte, []int) {
+	return file_blog_v1_post_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ListPostsRequest) GetPage() int32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *ListPostsRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListPostsRequest) GetSort() PostSort {
+	if x != nil {
+		return x.Sort
+	}
+	return PostSort_POST_SORT_UNSPECIFIED
+}
+
+type ListPostsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	List          []*Post                `protobuf:"bytes,1,rep,name=list,proto3" json:"list,omitempty"`
+	Total         int64                  `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListPostsResponse) Reset() {
+	*x = ListPostsResponse{}
+	mi := &file_blog_v1_post_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListPostsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPostsResponse) ProtoMessage() {}
+
+func (x *ListPostsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_blog_v1_post_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPostsResponse.ProtoReflect.Descriptor instead.
+func (*ListPostsResponse) Descriptor() ([]byte, []int) {
+	return file_blog_v1_post_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ListPostsResponse) GetList() []*Post {
+	if x != nil {
+		return x.List
+	}
+	return nil
+}
+
+func (x *ListPostsResponse) GetTotal() int64 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+type StreamPostsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PageSize      int32                  `protobuf:"varint,1,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"` // 每次查询的数量 默认10 最大100
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamPostsRequest) Reset() {
+	*x = StreamPostsRequest{}
+	mi := &file_blog_v1_post_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamPostsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamPostsRequest) ProtoMessage() {}
+
+func (x *StreamPostsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_blog_v1_post_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamPostsRequest.ProtoReflect.Descriptor instead.
+func (*StreamPostsRequest) Descriptor() ([]byte, []int) {
+	return file_blog_v1_post_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *StreamPostsRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+type GetPostRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PostId        uint64                 `protobuf:"varint,1,opt,name=post_id,json=postId,proto3" json:"post_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetPostRequest) Reset() {
+	*x = GetPostRequest{}
+	mi := &file_blog_v1_post_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetPostRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPostRequest) ProtoMessage() {}
+
+func (x *GetPostRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_blog_v1_post_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPostRequest.ProtoReflect.Descriptor instead.
+func (*GetPostRequest) Descriptor() ([]byte, []int) {
+	return file_blog_v1_post_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetPostRequest) GetPostId() uint64 {
+	if x != nil {
+		return x.PostId
+	}
+	return 0
+}
+
+type BatchGetPostsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PostIds       []uint64               `protobuf:"varint,1,rep,packed,name=post_ids,json=postIds,proto3" json:"post_ids,omitempty"` // 最多100个
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchGetPostsRequest) Reset() {
+	*x = BatchGetPostsRequest{}
+	mi := &file_blog_v1_post_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchGetPostsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchGetPostsRequest) ProtoMessage() {}
+
+func (x *BatchGetPostsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_blog_v1_post_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchGetPostsRequest.ProtoReflect.Descriptor instead.
+func (*BatchGetPostsRequest) Descriptor() ([]byte, []int) {
+	return file_blog_v1_post_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *BatchGetPostsRequest) GetPostIds() []uint64 {
+	if x != nil {
+		return x.PostIds
+	}
+	return nil
+}
+
+type BatchGetPostsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Posts         []*Post                `protobuf:"bytes,1,rep,name=posts,proto3" json:"posts,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchGetPostsResponse) Reset() {
+	*x = BatchGetPostsResponse{}
+	mi := &file_blog_v1_post_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchGetPostsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchGetPostsResponse) ProtoMessage() {}
+
+func (x *BatchGetPostsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_blog_v1_post_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchGetPostsResponse.ProtoReflect.Descriptor instead.
+func (*BatchGetPostsResponse) Descriptor() ([]byte, []int) {
+	return file_blog_v1_post_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *BatchGetPostsResponse) GetPosts() []*Post {
+	if x != nil {
+		return x.Posts
+	}
+	return nil
+}
+
+type CreatePostRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Title         string                 `protobuf:"bytes,1,opt,name=title,proto3" json:"title,omitempty"`     // 1-20个字符
+	Content       string                 `protobuf:"bytes,2,opt,name=content,proto3" json:"content,omitempty"` // 1-200个字符
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreatePostRequest) Reset() {
+	*x = CreatePostRequest{}
+	mi := &file_blog_v1_post_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreatePostRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreatePostRequest) ProtoMessage() {}
+
+func (x *CreatePostRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_blog_v1_post_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreatePostRequest.ProtoReflect.Descriptor instead.
+func (*CreatePostRequest) Descriptor() ([]byte, []int) {
+	return file_blog_v1_post_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *CreatePostRequest) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *CreatePostRequest) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+type UpdatePostRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PostId        uint64                 `protobuf:"varint,1,opt,name=post_id,json=postId,proto3" json:"post_id,omitempty"`
+	Title         string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`     // 为空时不更新
+	Content       string                 `protobuf:"bytes,3,opt,name=content,proto3" json:"content,omitempty"` // 为空时不更新
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdatePostRequest) Reset() {
+	*x = UpdatePostRequest{}
+	mi := &file_blog_v1_post_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdatePostRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdatePostRequest) ProtoMessage() {}
+
+func (x *UpdatePostRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_blog_v1_post_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdatePostRequest.ProtoReflect.Descriptor instead.
+func (*UpdatePostRequest) Descriptor() ([]byte, []int) {
+	return file_blog_v1_post_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *UpdatePostRequest) GetPostId() uint64 {
+	if x != nil {
+		return x.PostId
+	}
+	return 0
+}
+
+func (x *UpdatePostRequest) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *UpdatePostRequest) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+type DeletePostRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PostId        uint64                 `protobuf:"varint,1,opt,name=post_id,json=postId,proto3" json:"post_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeletePostRequest) Reset() {
+	*x = DeletePostRequest{}
+	mi := &file_blog_v1_post_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeletePostRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeletePostRequest) ProtoMessage() {}
+
+func (x *DeletePostRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_blog_v1_post_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeletePostRequest.ProtoReflect.Descriptor instead.
+func (*DeletePostRequest) Descriptor() ([]byte, []int) {
+	return file_blog_v1_post_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *DeletePostRequest) GetPostId() uint64 {
+	if x != nil {
+		return x.PostId
+	}
+	return 0
+}
+
+var File_blog_v1_post_proto protoreflect.FileDescriptor
+
+const file_blog_v1_post_proto_rawDesc = "" +
+	"\n" +
+	"\x12blog/v1/post.proto\x12\ablog.v1\x1a\x1cgoogle/api/annotations.proto\x1a\x1bgoogle/protobuf/empty.proto\"\xb3\x03\n" +
+	"\x04Post\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\x04R\x06userId\x12\x14\n" +
+	"\x05title\x18\x03 \x01(\tR\x05title\x12\x18\n" +
+	"\acontent\x18\x04 \x01(\tR\acontent\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x05 \x01(\tR\tcreatedAt\x12\x1d\n" +
+	"\n" +
+	"updated_at\x18\x06 \x01(\tR\tupdatedAt\x12\x1d\n" +
+	"\n" +
+	"view_count\x18\a \x01(\x04R\tviewCount\x12#\n" +
+	"\rcomment_count\x18\b \x01(\rR\fcommentCount\x12!\n" +
+	"\fhas_comments\x18\t \x01(\bR\vhasComments\x12\x1d\n" +
+	"\n" +
+	"like_count\x18\n" +
+	" \x01(\x03R\tlikeCount\x12\x14\n" +
+	"\x05liked\x18\v \x01(\bR\x05liked\x12:\n" +
+	"\treactions\x18\f \x03(\v2\x1c.blog.v1.Post.ReactionsEntryR\treactions\x1a<\n" +
+	"\x0eReactionsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x03R\x05value:\x028\x01\"j\n" +
+	"\x10ListPostsRequest\x12\x12\n" +
+	"\x04page\x18\x01 \x01(\x05R\x04page\x12\x1b\n" +
+	"\tpage_size\x18\x02 \x01(\x05R\bpageSize\x12%\n" +
+	"\x04sort\x18\x03 \x01(\x0e2\x11.blog.v1.PostSortR\x04sort\"L\n" +
+	"\x11ListPostsResponse\x12!\n" +
+	"\x04list\x18\x01 \x03(\v2\r.blog.v1.PostR\x04list\x12\x14\n" +
+	"\x05total\x18\x02 \x01(\x03R\x05total\"1\n" +
+	"\x12StreamPostsRequest\x12\x1b\n" +
+	"\tpage_size\x18\x01 \x01(\x05R\bpageSize\")\n" +
+	"\x0eGetPostRequest\x12\x17\n" +
+	"\apost_id\x18\x01 \x01(\x04R\x06postId\"1\n" +
+	"\x14BatchGetPostsRequest\x12\x19\n" +
+	"\bpost_ids\x18\x01 \x03(\x04R\apostIds\"<\n" +
+	"\x15BatchGetPostsResponse\x12#\n" +
+	"\x05posts\x18\x01 \x03(\v2\r.blog.v1.PostR\x05posts\"C\n" +
+	"\x11CreatePostRequest\x12\x14\n" +
+	"\x05title\x18\x01 \x01(\tR\x05title\x12\x18\n" +
+	"\acontent\x18\x02 \x01(\tR\acontent\"\\\n" +
+	"\x11UpdatePostRequest\x12\x17\n" +
+	"\apost_id\x18\x01 \x01(\x04R\x06postId\x12\x14\n" +
+	"\x05title\x18\x02 \x01(\tR\x05title\x12\x18\n" +
+	"\acontent\x18\x03 \x01(\tR\acontent\",\n" +
+	"\x11DeletePostRequest\x12\x17\n" +
+	"\apost_id\x18\x01 \x01(\x04R\x06postId*l\n" +
+	"\bPostSort\x12\x19\n" +
+	"\x15POST_SORT_UNSPECIFIED\x10\x00\x12\x14\n" +
+	"\x10POST_SORT_LATEST\x10\x01\x12\x11\n" +
+	"\rPOST_SORT_HOT\x10\x02\x12\x1c\n" +
+	"\x18POST_SORT_MOST_COMMENTED\x10\x032\xb6\x05\n" +
+	"\vPostService\x12]\n" +
+	"\tListPosts\x12\x19.blog.v1.ListPostsRequest\x1a\x1a.blog.v1.ListPostsResponse\"\x19\x82\xd3\xe4\x93\x02\x13\x12\x11/api/rpc/v1/posts\x12]\n" +
+	"\vStreamPosts\x12\x1b.blog.v1.StreamPostsRequest\x1a\r.blog.v1.Post\" \x82\xd3\xe4\x93\x02\x1a\x12\x18/api/rpc/v1/posts:stream0\x01\x12V\n" +
+	"\aGetPost\x12\x17.blog.v1.GetPostRequest\x1a\r.blog.v1.Post\"#\x82\xd3\xe4\x93\x02\x1d\x12\x1b/api/rpc/v1/posts/{post_id}\x12r\n" +
+	"\rBatchGetPosts\x12\x1d.blog.v1.BatchGetPostsRequest\x1a\x1e.blog.v1.BatchGetPostsResponse\"\"\x82\xd3\xe4\x93\x02\x1c\x12\x1a/api/rpc/v1/posts:batchGet\x12U\n" +
+	"\n" +
+	"CreatePost\x12\x1a.blog.v1.CreatePostRequest\x1a\r.blog.v1.Post\"\x1c\x82\xd3\xe4\x93\x02\x16:\x01*\"\x11/api/rpc/v1/posts\x12_\n" +
+	"\n" +
+	"UpdatePost\x12\x1a.blog.v1.UpdatePostRequest\x1a\r.blog.v1.Post\"&\x82\xd3\xe4\x93\x02 :\x01*2\x1b/api/rpc/v1/posts/{post_id}\x12e\n" +
+	"\n" +
+	"DeletePost\x12\x1a.blog.v1.DeletePostRequest\x1a\x16.google.protobuf.Empty\"#\x82\xd3\xe4\x93\x02\x1d*\x1b/api/rpc/v1/posts/{post_id}B\"Z homework4/internal/api/rpc/pb;pbb\x06proto3"
+
+var (
+	file_blog_v1_post_proto_rawDescOnce sync.Once
+	file_blog_v1_post_proto_rawDescData []byte
+)
+
+func file_blog_v1_post_proto_rawDescGZIP() []byte {
+	file_blog_v1_post_proto_rawDescOnce.Do(func() {
+		file_blog_v1_post_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_blog_v1_post_proto_rawDesc), len(file_blog_v1_post_proto_rawDesc)))
+	})
+	return file_blog_v1_post_proto_rawDescData
+}
+
+var file_blog_v1_post_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_blog_v1_post_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
+var file_blog_v1_post_proto_goTypes = []any{
+	(PostSort)(0),                 // 0: blog.v1.PostSort
+	(*Post)(nil),                  // 1: blog.v1.Post
+	(*ListPostsRequest)(nil),      // 2: blog.v1.ListPostsRequest
+	(*ListPostsResponse)(nil),     // 3: blog.v1.ListPostsResponse
+	(*StreamPostsRequest)(nil),    // 4: blog.v1.StreamPostsRequest
+	(*GetPostRequest)(nil),        // 5: blog.v1.GetPostRequest
+	(*BatchGetPostsRequest)(nil),  // 6: blog.v1.BatchGetPostsRequest
+	(*BatchGetPostsResponse)(nil), // 7: blog.v1.BatchGetPostsResponse
+	(*CreatePostRequest)(nil),     // 8: blog.v1.CreatePostRequest
+	(*UpdatePostRequest)(nil),     // 9: blog.v1.UpdatePostRequest
+	(*DeletePostRequest)(nil),     // 10: blog.v1.DeletePostRequest
+	nil,                           // 11: blog.v1.Post.ReactionsEntry
+	(*emptypb.Empty)(nil),         // 12: google.protobuf.Empty
+}
+var file_blog_v1_post_proto_depIdxs = []int32{
+	11, // 0: blog.v1.Post.reactions:type_name -> blog.v1.Post.ReactionsEntry
+	0,  // 1: blog.v1.ListPostsRequest.sort:type_name -> blog.v1.PostSort
+	1,  // 2: blog.v1.ListPostsResponse.list:type_name -> blog.v1.Post
+	1,  // 3: blog.v1.BatchGetPostsResponse.posts:type_name -> blog.v1.Post
+	2,  // 4: blog.v1.PostService.ListPosts:input_type -> blog.v1.ListPostsRequest
+	4,  // 5: blog.v1.PostService.StreamPosts:input_type -> blog.v1.StreamPostsRequest
+	5,  // 6: blog.v1.PostService.GetPost:input_type -> blog.v1.GetPostRequest
+	6,  // 7: blog.v1.PostService.BatchGetPosts:input_type -> blog.v1.BatchGetPostsRequest
+	8,  // 8: blog.v1.PostService.CreatePost:input_type -> blog.v1.CreatePostRequest
+	9,  // 9: blog.v1.PostService.UpdatePost:input_type -> blog.v1.UpdatePostRequest
+	10, // 10: blog.v1.PostService.DeletePost:input_type -> blog.v1.DeletePostRequest
+	3,  // 11: blog.v1.PostService.ListPosts:output_type -> blog.v1.ListPostsResponse
+	1,  // 12: blog.v1.PostService.StreamPosts:output_type -> blog.v1.Post
+	1,  // 13: blog.v1.PostService.GetPost:output_type -> blog.v1.Post
+	7,  // 14: blog.v1.PostService.BatchGetPosts:output_type -> blog.v1.BatchGetPostsResponse
+	1,  // 15: blog.v1.PostService.CreatePost:output_type -> blog.v1.Post
+	1,  // 16: blog.v1.PostService.UpdatePost:output_type -> blog.v1.Post
+	12, // 17: blog.v1.PostService.DeletePost:output_type -> google.protobuf.Empty
+	11, // [11:18] is the sub-list for method output_type
+	4,  // [4:11] is the sub-list for method input_type
+	4,  // [4:4] is the sub-list for extension type_name
+	4,  // [4:4] is the sub-list for extension extendee
+	0,  // [0:4] is the sub-list for field type_name
+}
+
+func init() { file_blog_v1_post_proto_init() }
+func file_blog_v1_post_proto_init() {
+	if File_blog_v1_post_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_blog_v1_post_proto_rawDesc), len(file_blog_v1_post_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   11,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_blog_v1_post_proto_goTypes,
+		DependencyIndexes: file_blog_v1_post_proto_depIdxs,
+		EnumInfos:         file_blog_v1_post_proto_enumTypes,
+		MessageInfos:      file_blog_v1_post_proto_msgTypes,
+	}.Build()
+	File_blog_v1_post_proto = out.File
+	file_blog_v1_post_proto_goTypes = nil
+	file_blog_v1_post_proto_depIdxs = nil
+}
@@ -0,0 +1,195 @@
+package rpc
+
+import (
+	"context"
+	"homework4/internal/api/rpc/pb"
+	"homework4/internal/service"
+
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+/**
+ * @Description: 文章gRPC服务 与PostController共用service
+ */
+type postServer struct {
+	pb.UnimplementedPostServiceServer
+	postService *service.PostService
+}
+
+// 默认每次查询的数量 与service一致
+const defaultPageSize = 10
+
+// 批量获取文章请求
+type batchGetPostsRequest struct {
+	PostIDs []uint64 `json:"postIds" binding:"required,min=1,max=100"` // 文章ID列表
+}
+
+// 逐条返回文章请求
+type streamPostsRequest struct {
+	PageSize int `json:"pageSize" binding:"omitempty,min=1,max=100"` // 每次查询的数量
+}
+
+var postSorts = map[pb.PostSort]string{
+	pb.PostSort_POST_SORT_UNSPECIFIED:    service.PostSortLatest,
+	pb.PostSort_POST_SORT_LATEST:         service.PostSortLatest,
+	pb.PostSort_POST_SORT_HOT:            service.PostSortHot,
+	pb.PostSort_POST_SORT_MOST_COMMENTED: service.PostSortMostCommented,
+}
+
+func (s *postServer) ListPosts(ctx context.Context, req *pb.ListPostsRequest) (*pb.ListPostsResponse, error) {
+	listReq := service.GetPostListRequest{
+		Page:     int(req.GetPage()),
+		PageSize: int(req.GetPageSize()),
+		Sort:     postSorts[req.GetSort()],
+	}
+	if err := validate(&listReq); err != nil {
+		return nil, err
+	}
+
+	posts, total, err := s.postService.GetPostList(ctx, &listReq, viewerID(ctx))
+	if err != nil {
+		return nil, err
+	}
+	return &pb.ListPostsResponse{List: toPosts(posts), Total: total}, nil
+}
+
+func (s *postServer) StreamPosts(req *pb.StreamPostsRequest, stream pb.PostService_StreamPostsServer) error {
+	streamReq := streamPostsRequest{PageSize: int(req.GetPageSize())}
+	if err := validate(&streamReq); err != nil {
+		return err
+	}
+
+	if streamReq.PageSize == 0 {
+		streamReq.PageSize = defaultPageSize
+	}
+
+	//按发布时间倒序分页查询 直到最后一页或客户端取消
+	ctx := stream.Context()
+	listReq := service.GetPostListRequest{Page: 1, PageSize: streamReq.PageSize, Sort: service.PostSortLatest}
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		posts, total, err := s.postService.GetPostList(ctx, &listReq, viewerID(ctx))
+		if err != nil {
+			return err
+		}
+		for i := range posts {
+			if err := stream.Send(toPost(&posts[i])); err != nil {
+				return err
+			}
+		}
+		if len(posts) < listReq.PageSize || int64(listReq.Page*listReq.PageSize) >= total {
+			return nil
+		}
+		listReq.Page++
+	}
+}
+
+func (s *postServer) GetPost(ctx context.Context, req *pb.GetPostRequest) (*pb.Post, error) {
+	uri := service.PostIDRequest{PostID: uint(req.GetPostId())}
+	if err := validate(&uri); err != nil {
+		return nil, err
+	}
+
+	post, err := s.postService.GetPost(ctx, uri.PostID, viewerID(ctx))
+	if err != nil {
+		return nil, err
+	}
+	return toPost(post), nil
+}
+
+func (s *postServer) BatchGetPosts(ctx context.Context, req *pb.BatchGetPostsRequest) (*pb.BatchGetPostsResponse, error) {
+	batchReq := batchGetPostsRequest{PostIDs: req.GetPostIds()}
+	if err := validate(&batchReq); err != nil {
+		return nil, err
+	}
+
+	posts, err := s.postService.GetPostsByIDs(ctx, toUintIDs(batchReq.PostIDs), viewerID(ctx))
+	if err != nil {
+		return nil, err
+	}
+	return &pb.BatchGetPostsResponse{Posts: toPosts(posts)}, nil
+}
+
+func (s *postServer) CreatePost(ctx context.Context, req *pb.CreatePostRequest) (*pb.Post, error) {
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	createReq := service.CreatePostRequest{Title: req.GetTitle(), Content: req.GetContent()}
+	if err := validate(&createReq); err != nil {
+		return nil, err
+	}
+
+	post, err := s.postService.CreatePost(ctx, &createReq, userID)
+	if err != nil {
+		return nil, err
+	}
+	return toPost(post), nil
+}
+
+func (s *postServer) UpdatePost(ctx context.Context, req *pb.UpdatePostRequest) (*pb.Post, error) {
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	updateReq := service.UpdatePostRequest{
+		PostID:  uint(req.GetPostId()),
+		Title:   req.GetTitle(),
+		Content: req.GetContent(),
+	}
+	if err := validate(&updateReq); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.postService.UpdatePost(ctx, &updateReq, userID); err != nil {
+		return nil, err
+	}
+	post, err := s.postService.GetPost(ctx, updateReq.PostID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return toPost(post), nil
+}
+
+func (s *postServer) DeletePost(ctx context.Context, req *pb.DeletePostRequest) (*emptypb.Empty, error) {
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	deleteReq := service.DeletePostRequest{PostID: uint(req.GetPostId())}
+	if err := validate(&deleteReq); err != nil {
+		return nil, err
+	}
+
+	if err := s.postService.DeletePost(ctx, &deleteReq, userID); err != nil {
+		return nil, err
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func toPost(post *service.PostResponse) *pb.Post {
+	return &pb.Post{
+		Id:           uint64(post.ID),
+		UserId:       uint64(post.UserID),
+		Title:        post.Title,
+		Content:      post.Content,
+		CreatedAt:    post.CreatedAt,
+		UpdatedAt:    post.UpdatedAt,
+		ViewCount:    post.ViewCount,
+		CommentCount: post.CommentCount,
+		HasComments:  post.HasComments,
+		LikeCount:    post.LikeCount,
+		Liked:        post.Liked,
+		Reactions:    post.Reactions,
+	}
+}
+
+func toPosts(posts []service.PostResponse) []*pb.Post {
+	list := make([]*pb.Post, len(posts))
+	for i := range posts {
+		list[i] = toPost(&posts[i])
+	}
+	return list
+}
@@ -0,0 +1,69 @@
+package rpc
+
+/**
+ * @Description: gRPC服务 供内部服务调用用户、文章和评论接口 与HTTP服务共用service和登录认证 使用单独的端口
+ */
+import (
+	"homework4/internal/api/rpc/pb"
+	"homework4/internal/metrics"
+	"homework4/internal/middleware/auth"
+	"homework4/internal/service"
+
+	"github.com/gin-gonic/gin/binding"
+	"google.golang.org/grpc"
+)
+
+// 需要登录的方法
+var protectedMethods = map[string]bool{
+	pb.PostService_CreatePost_FullMethodName:       true,
+	pb.PostService_UpdatePost_FullMethodName:       true,
+	pb.PostService_DeletePost_FullMethodName:       true,
+	pb.CommentService_CreateComment_FullMethodName: true,
+	pb.CommentService_DeleteComment_FullMethodName: true,
+}
+
+/**
+ * @Description: 创建gRPC服务 注册用户、文章和评论服务
+ * @param authenticator
+ * @param users
+ * @param posts
+ * @param comments
+ * @return *grpc.Server
+ */
+func NewServer(authenticator *auth.Authenticator, users *service.UserService, posts *service.PostService, comments *service.CommentService) *grpc.Server {
+	authn := &authInterceptor{authenticator: authenticator, protected: protectedMethods}
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(
+			loggingUnaryInterceptor(),
+			metrics.UnaryServerInterceptor(),
+			errorUnaryInterceptor(),
+			recoveryUnaryInterceptor(),
+			authn.unary(),
+		),
+		grpc.ChainStreamInterceptor(
+			loggingStreamInterceptor(),
+			metrics.StreamServerInterceptor(),
+			errorStreamInterceptor(),
+			recoveryStreamInterceptor(),
+			authn.stream(),
+		),
+	)
+	pb.RegisterUserServiceServer(server, &userServer{userService: users, authenticator: authenticator})
+	pb.RegisterPostServiceServer(server, &postServer{postService: posts})
+	pb.RegisterCommentServiceServer(server, &commentServer{commentService: comments})
+	return server
+}
+
+// 按请求结构体的binding规则校验参数 与HTTP接口一致
+func validate(req interface{}) error {
+	return binding.Validator.ValidateStruct(req)
+}
+
+// 转换为service使用的ID类型
+func toUintIDs(ids []uint64) []uint {
+	result := make([]uint, len(ids))
+	for i, id := range ids {
+		result[i] = uint(id)
+	}
+	return result
+}
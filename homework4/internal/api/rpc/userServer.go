@@ -0,0 +1,127 @@
+package rpc
+
+import (
+	"context"
+	"homework4/internal/api/rpc/pb"
+	"homework4/internal/errcode"
+	"homework4/internal/feature"
+	"homework4/internal/middleware/auth"
+	"homework4/internal/service"
+)
+
+/**
+ * @Description: 用户gRPC服务 与UserController共用service
+ */
+type userServer struct {
+	pb.UnimplementedUserServiceServer
+	userService   *service.UserService
+	authenticator *auth.Authenticator
+}
+
+// 批量获取用户请求
+type batchGetUsersRequest struct {
+	UserIDs []uint64 `json:"userIds" binding:"required,min=1,max=100"` // 用户ID列表
+}
+
+func (s *userServer) Register(ctx context.Context, req *pb.RegisterRequest) (*pb.RegisterResponse, error) {
+	if !feature.Enabled(feature.Registration) {
+		return nil, errcode.ErrRegistrationDisabled
+	}
+	registerReq := service.RegisterRequest{
+		Username: req.GetUsername(),
+		Nickname: req.GetNickname(),
+		Password: req.GetPassword(),
+		Email:    req.GetEmail(),
+	}
+	if err := validate(&registerReq); err != nil {
+		return nil, err
+	}
+
+	user, err := s.userService.Register(ctx, &registerReq)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.RegisterResponse{
+		UserId:   uint64(user.ID),
+		Username: user.Username,
+		Nickname: user.Nickname,
+		Email:    user.Email,
+	}, nil
+}
+
+func (s *userServer) Login(ctx context.Context, req *pb.LoginRequest) (*pb.LoginResponse, error) {
+	loginReq := service.LoginRequest{Username: req.GetUsername(), Password: req.GetPassword()}
+	if err := validate(&loginReq); err != nil {
+		return nil, err
+	}
+
+	resp, err := s.userService.Login(ctx, &loginReq)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.LoginResponse{
+		Token:    resp.Token,
+		UserId:   uint64(resp.UserID),
+		Username: resp.Username,
+		Nickname: resp.Nickname,
+	}, nil
+}
+
+func (s *userServer) GetProfile(ctx context.Context, req *pb.GetProfileRequest) (*pb.Profile, error) {
+	profileReq := service.GetProfileRequest{UserID: uint(req.GetUserId())}
+	if err := validate(&profileReq); err != nil {
+		return nil, err
+	}
+
+	profile, err := s.userService.GetProfile(ctx, &profileReq, viewerID(ctx))
+	if err != nil {
+		return nil, err
+	}
+	return &pb.Profile{
+		UserId:         uint64(profile.UserID),
+		Username:       profile.Username,
+		Nickname:       profile.Nickname,
+		FollowerCount:  profile.FollowerCount,
+		FollowingCount: profile.FollowingCount,
+		Followed:       profile.Followed,
+		CreatedAt:      profile.CreatedAt,
+	}, nil
+}
+
+func (s *userServer) BatchGetUsers(ctx context.Context, req *pb.BatchGetUsersRequest) (*pb.BatchGetUsersResponse, error) {
+	batchReq := batchGetUsersRequest{UserIDs: req.GetUserIds()}
+	if err := validate(&batchReq); err != nil {
+		return nil, err
+	}
+
+	users, err := s.userService.GetUsersByIDs(ctx, toUintIDs(batchReq.UserIDs))
+	if err != nil {
+		return nil, err
+	}
+	resp := &pb.BatchGetUsersResponse{Users: make([]*pb.User, len(users))}
+	for i, user := range users {
+		resp.Users[i] = &pb.User{
+			Id:        uint64(user.ID),
+			Username:  user.Username,
+			Nickname:  user.Nickname,
+			PostCount: user.PostCount,
+			CreatedAt: user.CreatedAt,
+		}
+	}
+	return resp, nil
+}
+
+func (s *userServer) VerifyToken(ctx context.Context, req *pb.VerifyTokenRequest) (*pb.AuthUser, error) {
+	if req.GetToken() == "" {
+		return nil, errcode.ErrTokenMissing
+	}
+	user, err := s.authenticator.Verify(ctx, req.GetToken())
+	if err != nil {
+		return nil, err
+	}
+	return &pb.AuthUser{
+		UserId:   uint64(user.UserID),
+		Username: user.Username,
+		Nickname: user.Nickname,
+	}, nil
+}
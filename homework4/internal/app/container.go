@@ -0,0 +1,239 @@
+package app
+
+/**
+ * @Description: 应用依赖容器 统一创建数据访问、service和controller 通过构造函数显式注入依赖
+ */
+import (
+	"errors"
+	"fmt"
+	"homework4/config"
+	"homework4/internal/api/graphql"
+	"homework4/internal/api/openapi"
+	"homework4/internal/api/rpc"
+	"homework4/internal/app/database"
+	"homework4/internal/cache"
+	"homework4/internal/controller"
+	"homework4/internal/feature"
+	"homework4/internal/health"
+	"homework4/internal/middleware/auth"
+	"homework4/internal/middleware/cors"
+	"homework4/internal/middleware/idempotency"
+	"homework4/internal/middleware/ratelimit"
+	"homework4/internal/repository"
+	"homework4/internal/service"
+	"homework4/internal/validation"
+	"homework4/pkg/logger"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+	"google.golang.org/grpc"
+	"gorm.io/gorm"
+)
+
+// Repositories 数据访问实现 生产环境使用gorm和redis 测试时可替换为内存实现
+type Repositories struct {
+	Posts    repository.PostRepository
+	Comments repository.CommentRepository
+	Users    repository.UserRepository
+	Tokens   repository.TokenStore
+}
+
+/**
+ * @Description: 基于gorm和redis的数据访问实现
+ * @param db
+ * @param rdb
+ * @return Repositories
+ */
+func GormRepositories(db *gorm.DB, rdb *goredis.Client) Repositories {
+	return Repositories{
+		Posts:    repository.NewGormPostRepository(db),
+		Comments: repository.NewGormCommentRepository(db),
+		Users:    repository.NewGormUserRepository(db),
+		Tokens:   repository.NewRedisTokenStore(rdb),
+	}
+}
+
+/**
+ * @Description: 基于内存的数据访问实现 用于本地调试和测试
+ * @return Repositories
+ */
+func MemoryRepositories() Repositories {
+	store := repository.NewMemoryStore()
+	return Repositories{
+		Posts:    store.Posts(),
+		Comments: store.Comments(),
+		Users:    store.Users(),
+		Tokens:   repository.NewMemoryTokenStore(),
+	}
+}
+
+/**
+ * @Description: 文章、评论和用户的数据访问加上缓存 缓存未开启时直接访问
+ * @param manager
+ * @return Repositories
+ */
+func (r Repositories) WithCache(manager *cache.Manager) Repositories {
+	return Repositories{
+		Posts:    repository.NewCachedPostRepository(r.Posts, manager),
+		Comments: repository.NewCachedCommentRepository(r.Comments, manager),
+		Users:    repository.NewCachedUserRepository(r.Users, manager),
+		Tokens:   r.Tokens,
+	}
+}
+
+type Container struct {
+	Config *config.Config
+	DB     *gorm.DB
+	Redis  *goredis.Client
+	Cache  *cache.Manager // 启动服务时订阅失效通知
+	Repos  Repositories   // 文章、评论和用户经过缓存包装
+	Auth   *auth.Authenticator
+
+	Lifecycle *Lifecycle       // 后台任务的启动和停止钩子
+	Health    *health.Registry // 就绪检查项 后台任务启动时注册心跳检查
+
+	//配置重新加载后更新
+	CORS        *cors.CORS
+	RateLimiter *ratelimit.Limiter
+	Idempotency *idempotency.Idempotency
+
+	ReactionService *service.ReactionService
+	FeedService     *service.FeedService
+	ViewService     *service.ViewService
+	HotService      *service.HotService
+	FollowService   *service.FollowService
+	BookmarkService *service.BookmarkService
+	CounterService  *service.CounterService
+	PostService     *service.PostService
+	CommentService  *service.CommentService
+	UserService     *service.UserService
+
+	UserController     *controller.UserController
+	PostController     *controller.PostController
+	PostV2Controller   *controller.PostV2Controller
+	CommentController  *controller.CommentController
+	ReactionController *controller.ReactionController
+	BookmarkController *controller.BookmarkController
+	FollowController   *controller.FollowController
+	HealthController   *controller.HealthController
+
+	OpenAPI        *openapi.Spec // 接口文档 校验请求和响应
+	GraphQLHandler *graphql.Handler
+	GRPCServer     *grpc.Server // grpc.enabled为false时为nil
+	GRPCGateway    *rpc.Gateway // grpc.gateway为false时为nil
+}
+
+/**
+ * @Description: 创建应用容器
+ * @param cfg
+ * @param db
+ * @param rdb
+ * @param repos
+ * @return *Container
+ */
+func NewContainer(cfg *config.Config, db *gorm.DB, rdb *goredis.Client, repos Repositories) *Container {
+	c := &Container{Config: cfg, DB: db, Redis: rdb, Lifecycle: NewLifecycle()}
+	c.Cache = cache.NewManager(cfg.Cache, rdb)
+	repos = repos.WithCache(c.Cache)
+	c.Repos = repos
+	c.Health = newHealthRegistry(cfg, db, rdb)
+	c.Auth = auth.NewAuthenticator(repos.Tokens, cfg.JWT)
+	c.RateLimiter = ratelimit.NewLimiter(cfg.RateLimit, rdb)
+	c.Idempotency = idempotency.New(cfg.Idempotency, rdb)
+	corsHandler, err := cors.New(cfg.CORS)
+	if err != nil {
+		logger.AppLog.Fatal("跨域配置错误", logger.WrapMeta(err)...)
+	}
+	c.CORS = corsHandler
+	feature.Set(cfg.Features)
+	validation.Setup(cfg.Validation)
+
+	counterCache := repository.NewCounterCache(c.Cache)
+	c.ReactionService = service.NewReactionService(db, rdb, counterCache)
+	c.FeedService = service.NewFeedService(db, rdb, cfg.Feed)
+	c.ViewService = service.NewViewService(db, rdb, counterCache)
+	c.HotService = service.NewHotService(db, rdb, cfg.Hot)
+	c.FollowService = service.NewFollowService(db, c.FeedService)
+	c.BookmarkService = service.NewBookmarkService(db)
+	c.CounterService = service.NewCounterService(db, counterCache)
+	c.PostService = service.NewPostService(repos.Posts, repos.Users, c.ReactionService, c.FeedService, c.ViewService, c.HotService)
+	c.CommentService = service.NewCommentService(repos.Comments, repos.Posts, repos.Users, c.ReactionService)
+	c.UserService = service.NewUserService(repos.Users, c.FollowService, c.Auth)
+
+	c.UserController = controller.NewUserController(c.UserService)
+	c.PostController = controller.NewPostController(c.PostService)
+	c.PostV2Controller = controller.NewPostV2Controller(c.PostService, c.CommentService)
+	c.CommentController = controller.NewCommentController(c.CommentService)
+	c.ReactionController = controller.NewReactionController(c.ReactionService)
+	c.BookmarkController = controller.NewBookmarkController(c.BookmarkService)
+	c.FollowController = controller.NewFollowController(c.FollowService)
+	c.HealthController = controller.NewHealthController(c.Health)
+
+	spec, err := openapi.Load()
+	if err != nil {
+		logger.AppLog.Fatal("接口文档错误", logger.WrapMeta(err)...)
+	}
+	c.OpenAPI = spec
+
+	graphqlHandler, err := graphql.NewHandler(cfg.GraphQL, c.PostService, c.CommentService, c.UserService)
+	if err != nil {
+		logger.AppLog.Fatal("GraphQL schema错误", logger.WrapMeta(err)...)
+	}
+	c.GraphQLHandler = graphqlHandler
+
+	if cfg.GRPC.Enabled {
+		c.GRPCServer = rpc.NewServer(c.Auth, c.UserService, c.PostService, c.CommentService)
+		if cfg.GRPC.Gateway {
+			gateway, err := rpc.NewGateway(fmt.Sprintf("127.0.0.1:%d", cfg.GRPC.Port))
+			if err != nil {
+				logger.AppLog.Fatal("gRPC gateway创建失败", logger.WrapMeta(err)...)
+			}
+			c.GRPCGateway = gateway
+		}
+	}
+	return c
+}
+
+// 注册数据库、redis和迁移版本检查 连接为空时不注册
+func newHealthRegistry(cfg *config.Config, db *gorm.DB, rdb *goredis.Client) *health.Registry {
+	registry := health.NewRegistry(time.Duration(cfg.Health.CheckTimeout) * time.Millisecond)
+	if db != nil {
+		registry.Register("database", 0, health.DBCheck(db))
+		migrator, err := database.NewMigrator(db, cfg.Database)
+		if err != nil {
+			logger.AppLog.Error("读取迁移文件失败 不检查迁移版本", logger.WrapMeta(err)...)
+		} else {
+			registry.Register("migration", 0, health.MigrationCheck(migrator))
+		}
+	}
+	if rdb != nil {
+		registry.Register("redis", 0, health.RedisCheck(rdb))
+	}
+	return registry
+}
+
+/**
+ * @Description: 关闭gateway到gRPC服务的连接、数据库连接池和redis连接 需要在后台任务停止后调用
+ * @return error
+ */
+func (c *Container) Close() error {
+	var errs []error
+	if c.GRPCGateway != nil {
+		if err := c.GRPCGateway.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if c.DB != nil {
+		if sqlDB, err := c.DB.DB(); err != nil {
+			errs = append(errs, err)
+		} else if err := sqlDB.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if c.Redis != nil {
+		if err := c.Redis.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
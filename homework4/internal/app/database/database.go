@@ -0,0 +1,165 @@
+package database
+
+/**
+ * @Description: 初始化数据库连接 根据database.driver选择mysql、postgres或sqlite
+ */
+import (
+	"context"
+	"fmt"
+	"homework4/config"
+	"homework4/internal/metrics"
+	"homework4/internal/migrate"
+	"homework4/migrations"
+	"homework4/pkg/logger"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	gomysql "github.com/go-sql-driver/mysql"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+	gormtracing "gorm.io/plugin/opentelemetry/tracing"
+)
+
+/**
+ * @Description: 连接数据库并检查数据库结构版本 失败或版本落后时直接退出
+ * @param cfg
+ * @return *gorm.DB
+ */
+func InitDB(cfg config.DatabaseConfig) *gorm.DB {
+	db, err := Open(cfg)
+	if err != nil {
+		logger.AppLog.Fatal("初始化数据库连接失败", logger.WrapMeta(err, logger.NewMeta("driver", cfg.Driver))...)
+	}
+	logger.AppLog.Info("数据库连接成功", logger.WrapMeta(nil, logger.NewMeta("driver", cfg.Driver))...)
+
+	migrator, err := NewMigrator(db, cfg)
+	if err != nil {
+		logger.AppLog.Fatal("读取迁移文件失败", logger.WrapMeta(err)...)
+	}
+	ctx := context.Background()
+	if cfg.MigrateOnStart {
+		applied, err := migrator.Up(ctx, 0)
+		if err != nil {
+			logger.AppLog.Fatal("执行迁移失败", logger.WrapMeta(err)...)
+		}
+		logger.AppLog.Info("执行迁移完成", logger.WrapMeta(nil, logger.NewMeta("applied", len(applied)))...)
+	}
+	if err := migrator.Check(ctx); err != nil {
+		logger.AppLog.Fatal("数据库结构检查失败 请执行 go run cmd/main.go migrate up", logger.WrapMeta(err)...)
+	}
+
+	return db
+}
+
+/**
+ * @Description: 创建当前驱动的迁移器 迁移文件内嵌在程序中
+ * @param db
+ * @param cfg
+ * @return (*migrate.Migrator, error)
+ */
+func NewMigrator(db *gorm.DB, cfg config.DatabaseConfig) (*migrate.Migrator, error) {
+	driver := cfg.Driver
+	if driver == "" {
+		driver = config.DriverMySQL
+	}
+	return migrate.New(db, driver, migrations.FS)
+}
+
+/**
+ * @Description: 根据配置打开数据库连接 不做迁移
+ * @param cfg
+ * @return (*gorm.DB, error)
+ */
+func Open(cfg config.DatabaseConfig) (*gorm.DB, error) {
+	dialector, err := newDialector(cfg)
+	if err != nil {
+		return nil, err
+	}
+	db, err := gorm.Open(dialector, &gorm.Config{
+		//禁用配置外键
+		DisableForeignKeyConstraintWhenMigrating: true,
+		//设置表名规则
+		NamingStrategy: schema.NamingStrategy{
+			TablePrefix:   "table_", // 表名加table_前缀
+			SingularTable: true,     // 禁用复数表名（去掉s后缀）
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	//统计每次操作的耗时
+	if err := db.Use(metrics.NewGormPlugin()); err != nil {
+		return nil, err
+	}
+	//每条语句一个span 不记录参数 防止密码等敏感信息进入链路
+	if err := db.Use(gormtracing.NewPlugin(gormtracing.WithoutMetrics(), gormtracing.WithoutQueryVariables())); err != nil {
+		return nil, err
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Driver == config.DriverSQLite {
+		//sqlite同时只能有一个写连接 内存数据库每个连接都是独立的库 连接不能过期
+		sqlDB.SetMaxOpenConns(1)
+		sqlDB.SetMaxIdleConns(1)
+		return db, nil
+	}
+	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(time.Duration(cfg.ConnMaxLifetime) * time.Second)
+	sqlDB.SetConnMaxIdleTime(time.Duration(cfg.ConnMaxIdleTime) * time.Second)
+	return db, nil
+}
+
+// 根据驱动构建gorm方言 DSN由各驱动的格式化方法生成 避免特殊字符问题
+func newDialector(cfg config.DatabaseConfig) (gorm.Dialector, error) {
+	switch cfg.Driver {
+	case config.DriverMySQL, "":
+		mysqlCfg := gomysql.NewConfig()
+		mysqlCfg.User = cfg.User
+		mysqlCfg.Passwd = cfg.Password
+		mysqlCfg.Net = "tcp"
+		mysqlCfg.Addr = net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port))
+		mysqlCfg.DBName = cfg.DBName
+		mysqlCfg.ParseTime = true
+		mysqlCfg.Loc = time.Local
+		mysqlCfg.Params = map[string]string{"charset": "utf8mb4"}
+		return mysql.Open(mysqlCfg.FormatDSN()), nil
+	case config.DriverPostgres:
+		sslMode := cfg.SSLMode
+		if sslMode == "" {
+			sslMode = "disable"
+		}
+		dsn := url.URL{
+			Scheme:   "postgres",
+			User:     url.UserPassword(cfg.User, cfg.Password),
+			Host:     net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port)),
+			Path:     "/" + cfg.DBName,
+			RawQuery: url.Values{"sslmode": {sslMode}}.Encode(),
+		}
+		return postgres.Open(dsn.String()), nil
+	case config.DriverSQLite:
+		return sqlite.Open(sqliteDSN(cfg.DBName)), nil
+	default:
+		return nil, fmt.Errorf("不支持的数据库驱动: %s", cfg.Driver)
+	}
+}
+
+// sqlite开启WAL和忙等待 减少并发写入时的database is locked错误
+func sqliteDSN(path string) string {
+	if path == ":memory:" {
+		return "file::memory:?_pragma=foreign_keys(0)"
+	}
+	separator := "?"
+	if strings.Contains(path, "?") {
+		separator = "&"
+	}
+	return path + separator + "_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)"
+}
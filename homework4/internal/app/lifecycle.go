@@ -0,0 +1,76 @@
+package app
+
+/**
+ * @Description: 应用生命周期 后台任务注册启动和停止钩子 启动按注册顺序 停止按相反顺序
+ */
+import (
+	"context"
+	"errors"
+	"fmt"
+	"homework4/pkg/logger"
+)
+
+// Hook 生命周期钩子 OnStart和OnStop可以为空
+type Hook struct {
+	Name    string
+	OnStart func(ctx context.Context) error
+	OnStop  func(ctx context.Context) error
+}
+
+type Lifecycle struct {
+	hooks   []Hook
+	started int // 已启动的钩子数 停止时只停止已启动的
+}
+
+func NewLifecycle() *Lifecycle {
+	return &Lifecycle{}
+}
+
+/**
+ * @Description: 注册钩子 需要在Start之前调用
+ * @param hook
+ */
+func (l *Lifecycle) Append(hook Hook) {
+	l.hooks = append(l.hooks, hook)
+}
+
+/**
+ * @Description: 按注册顺序执行启动钩子 失败时停止已启动的钩子并返回错误
+ * @param ctx
+ * @return error
+ */
+func (l *Lifecycle) Start(ctx context.Context) error {
+	for _, hook := range l.hooks[l.started:] {
+		if hook.OnStart != nil {
+			if err := hook.OnStart(ctx); err != nil {
+				stopErr := l.Stop(ctx)
+				return errors.Join(fmt.Errorf("启动%s失败: %w", hook.Name, err), stopErr)
+			}
+		}
+		l.started++
+		logger.AppLog.Info("启动成功", logger.WrapMeta(nil, logger.NewMeta("hook", hook.Name))...)
+	}
+	return nil
+}
+
+/**
+ * @Description: 按相反顺序执行已启动钩子的停止钩子 某个钩子失败时继续停止其它钩子
+ * @param ctx 超时后钩子应尽快返回
+ * @return error 所有停止失败的错误
+ */
+func (l *Lifecycle) Stop(ctx context.Context) error {
+	var errs []error
+	for ; l.started > 0; l.started-- {
+		hook := l.hooks[l.started-1]
+		if hook.OnStop == nil {
+			continue
+		}
+		if err := hook.OnStop(ctx); err != nil {
+			logger.AppLog.Error("停止失败", logger.WrapMeta(err, logger.NewMeta("hook", hook.Name))...)
+			errs = append(errs, fmt.Errorf("停止%s失败: %w", hook.Name, err))
+			continue
+		}
+		logger.AppLog.Info("停止成功", logger.WrapMeta(nil, logger.NewMeta("hook", hook.Name))...)
+	}
+	return errors.Join(errs...)
+}
@@ -7,29 +7,36 @@ import (
 	"context"
 	"fmt"
 	"homework4/config"
+	"homework4/internal/metrics"
 	"homework4/pkg/logger"
 	"time"
 
+	"github.com/redis/go-redis/extra/redisotel/v9"
 	"github.com/redis/go-redis/v9"
 )
 
-var RedisClient *redis.Client
-
-// 初始化redis配置
-func InitRedis() {
-	dsn := config.Cfg.Redis.Host + ":" + fmt.Sprintf("%d", config.Cfg.Redis.Port)
-	RedisClient = redis.NewClient(&redis.Options{
+// 初始化redis配置 连接失败时直接退出
+func InitRedis(cfg config.RedisConfig) *redis.Client {
+	dsn := cfg.Host + ":" + fmt.Sprintf("%d", cfg.Port)
+	client := redis.NewClient(&redis.Options{
 		Addr:     dsn,
-		Password: config.Cfg.Redis.Password,
-		DB:       config.Cfg.Redis.DB,
+		Password: cfg.Password,
+		DB:       cfg.DB,
 	})
+	//统计每个命令的耗时
+	client.AddHook(metrics.NewRedisHook())
+	//每个命令一个span
+	if err := redisotel.InstrumentTracing(client); err != nil {
+		logger.AppLog.Fatal("redis链路追踪初始化失败", logger.WrapMeta(err)...)
+	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	_, err := RedisClient.Ping(ctx).Result()
+	_, err := client.Ping(ctx).Result()
 	if err != nil {
 		logger.AppLog.Fatal("redis连接失败", logger.WrapMeta(err)...)
 	}
 	logger.AppLog.Info("redis连接成功")
+	return client
 }
@@ -0,0 +1,162 @@
+package cache
+
+/**
+ * @Description: 两级读穿缓存 先查本机LRU再查redis 都未命中时调用加载函数查询数据库并写入
+ * 写操作后删除缓存 并通过redis发布订阅通知其它实例删除本机缓存 通知丢失时由过期时间兜底
+ */
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"homework4/config"
+	"homework4/pkg/logger"
+	"sync"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+const (
+	EntityPost     = "post"     // 文章详情 key为文章ID
+	EntityPostList = "postlist" // 文章列表分页 key为排序和分页参数
+	EntityUser     = "user"     // 用户公开信息 key为用户ID
+
+	keyPrefix         = "cache:"           // redis key前缀 cache:{数据名}:v{版本}:{key}
+	invalidateChannel = "cache:invalidate" // 失效通知频道
+)
+
+// 失效通知 Flush为true时整类数据失效
+type notice struct {
+	Origin  string   `json:"origin"` // 发出通知的实例 自己发出的通知不处理
+	Entity  string   `json:"entity"`
+	Keys    []string `json:"keys,omitempty"`
+	Flush   bool     `json:"flush,omitempty"`
+	Version int64    `json:"version,omitempty"`
+}
+
+type Manager struct {
+	cfg      config.CacheConfig
+	rdb      *goredis.Client // 为空时只使用本机缓存
+	instance string
+
+	mu       sync.Mutex
+	entities map[string]*Entity
+
+	pubsub *goredis.PubSub
+	done   chan struct{}
+}
+
+/**
+ * @Description: 创建缓存管理 各类数据通过Entity获取
+ * @param cfg
+ * @param rdb 为空时只使用本机缓存 失效不通知其它实例
+ * @return *Manager
+ */
+func NewManager(cfg config.CacheConfig, rdb *goredis.Client) *Manager {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return &Manager{cfg: cfg, rdb: rdb, instance: hex.EncodeToString(b), entities: make(map[string]*Entity)}
+}
+
+/**
+ * @Description: 获取一类数据的缓存 未开启缓存或没有配置时每次都调用加载函数
+ * @param name 数据名 使用小写 viper读取配置时key不区分大小写
+ * @return *Entity
+ */
+func (m *Manager) Entity(name string) *Entity {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if e, ok := m.entities[name]; ok {
+		return e
+	}
+	cfg, ok := m.cfg.Entities[name]
+	e := newEntity(m, name, cfg, m.cfg.Enabled && ok)
+	m.entities[name] = e
+	return e
+}
+
+/**
+ * @Description: 读取各类数据的缓存版本并订阅失效通知 作为生命周期钩子在启动服务时调用
+ * @param ctx
+ * @return error
+ */
+func (m *Manager) Start(ctx context.Context) error {
+	if m.rdb == nil || !m.cfg.Enabled {
+		return nil
+	}
+	m.mu.Lock()
+	entities := make([]*Entity, 0, len(m.entities))
+	for _, e := range m.entities {
+		entities = append(entities, e)
+	}
+	m.mu.Unlock()
+	for _, e := range entities {
+		if err := e.loadVersion(ctx); err != nil {
+			return err
+		}
+	}
+
+	m.pubsub = m.rdb.Subscribe(ctx, invalidateChannel)
+	//等待订阅成功 之后的失效通知不会丢失
+	if _, err := m.pubsub.Receive(ctx); err != nil {
+		m.pubsub.Close()
+		m.pubsub = nil
+		return err
+	}
+	m.done = make(chan struct{})
+	go m.listen(m.pubsub.Channel())
+	return nil
+}
+
+/**
+ * @Description: 取消订阅失效通知
+ * @param ctx
+ * @return error
+ */
+func (m *Manager) Stop(ctx context.Context) error {
+	if m.pubsub == nil {
+		return nil
+	}
+	err := m.pubsub.Close()
+	select {
+	case <-m.done:
+	case <-ctx.Done():
+	}
+	return err
+}
+
+// 处理其它实例发出的失效通知 删除本机缓存
+func (m *Manager) listen(ch <-chan *goredis.Message) {
+	defer close(m.done)
+	for msg := range ch {
+		var n notice
+		if err := json.Unmarshal([]byte(msg.Payload), &n); err != nil {
+			logger.AppLog.Error("解析缓存失效通知失败", logger.WrapMeta(err, logger.NewMeta("payload", msg.Payload))...)
+			continue
+		}
+		if n.Origin == m.instance {
+			continue
+		}
+		m.mu.Lock()
+		e, ok := m.entities[n.Entity]
+		m.mu.Unlock()
+		if ok {
+			e.apply(n)
+		}
+	}
+}
+
+// 发布失效通知 失败时其它实例的本机缓存在过期后失效
+func (m *Manager) publish(ctx context.Context, n notice) {
+	if m.rdb == nil {
+		return
+	}
+	n.Origin = m.instance
+	payload, err := json.Marshal(n)
+	if err != nil {
+		return
+	}
+	if err := m.rdb.Publish(ctx, invalidateChannel, payload).Err(); err != nil {
+		logger.FromContext(ctx).Error("发布缓存失效通知失败", logger.WrapMeta(err, logger.NewMeta("entity", n.Entity))...)
+	}
+}
@@ -0,0 +1,319 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"homework4/config"
+	"homework4/internal/metrics"
+	"homework4/pkg/logger"
+	"math/rand/v2"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/hashicorp/golang-lru/v2/expirable"
+	goredis "github.com/redis/go-redis/v9"
+	"golang.org/x/sync/singleflight"
+)
+
+// Entity 一类数据的缓存 值以JSON保存 每次读取都反序列化出新的对象
+type Entity struct {
+	m       *Manager
+	name    string
+	cfg     config.CacheEntityConfig
+	enabled bool
+
+	local   *expirable.LRU[string, []byte] // 为空时不使用本机缓存
+	group   singleflight.Group             // 同一个key的并发加载合并为一次
+	version atomic.Int64                   // 整类数据失效时加1 作为key的一部分
+}
+
+func newEntity(m *Manager, name string, cfg config.CacheEntityConfig, enabled bool) *Entity {
+	e := &Entity{m: m, name: name, cfg: cfg, enabled: enabled}
+	if enabled && cfg.LocalTTL > 0 {
+		e.local = expirable.NewLRU[string, []byte](cfg.LocalSize, nil, time.Duration(cfg.LocalTTL)*time.Second)
+	}
+	return e
+}
+
+/**
+ * @Description: 读穿缓存 未命中时调用加载函数并写入缓存 加载失败时不缓存
+ * @param ctx
+ * @param e
+ * @param key
+ * @param load 加载函数 同一个key并发未命中时只调用一次 不随调用方的ctx取消
+ * @return (T, error)
+ */
+func Get[T any](ctx context.Context, e *Entity, key string, load func(ctx context.Context) (T, error)) (T, error) {
+	var value T
+	if !e.enabled {
+		return load(ctx)
+	}
+	fullKey := e.key(key)
+	if data, ok := e.get(ctx, fullKey); ok {
+		if err := json.Unmarshal(data, &value); err == nil {
+			return value, nil
+		}
+	}
+
+	result, err, _ := e.group.Do(fullKey, func() (interface{}, error) {
+		//等待同一个key的其它请求共用加载结果 第一个请求取消时不中断加载
+		loadCtx := context.WithoutCancel(ctx)
+		loaded, err := load(loadCtx)
+		if err != nil {
+			return nil, err
+		}
+		data, err := json.Marshal(loaded)
+		if err != nil {
+			return nil, err
+		}
+		e.set(loadCtx, map[string][]byte{fullKey: data})
+		return data, nil
+	})
+	if err != nil {
+		return value, err
+	}
+	err = json.Unmarshal(result.([]byte), &value)
+	return value, err
+}
+
+/**
+ * @Description: 按ID批量读穿缓存 未命中的ID一次调用加载函数 加载结果中没有的ID不缓存
+ * @param ctx
+ * @param e
+ * @param ids
+ * @param load 加载未命中的ID 返回ID到值的映射
+ * @return (map[uint]T, error)
+ */
+func GetMany[T any](ctx context.Context, e *Entity, ids []uint, load func(ctx context.Context, ids []uint) (map[uint]T, error)) (map[uint]T, error) {
+	if !e.enabled {
+		return load(ctx, ids)
+	}
+	values := make(map[uint]T, len(ids))
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = e.key(strconv.FormatUint(uint64(id), 10))
+	}
+	for i, data := range e.getMany(ctx, keys) {
+		if data == nil {
+			continue
+		}
+		var value T
+		if err := json.Unmarshal(data, &value); err == nil {
+			values[ids[i]] = value
+		}
+	}
+
+	missing := make([]uint, 0, len(ids)-len(values))
+	for _, id := range ids {
+		if _, ok := values[id]; !ok {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) == 0 {
+		return values, nil
+	}
+	loaded, err := load(ctx, missing)
+	if err != nil {
+		return nil, err
+	}
+	entries := make(map[string][]byte, len(loaded))
+	for id, value := range loaded {
+		values[id] = value
+		if data, err := json.Marshal(value); err == nil {
+			entries[e.key(strconv.FormatUint(uint64(id), 10))] = data
+		}
+	}
+	e.set(ctx, entries)
+	return values, nil
+}
+
+/**
+ * @Description: 删除缓存 并通知其它实例删除本机缓存
+ * @param ctx
+ * @param keys
+ */
+func (e *Entity) Delete(ctx context.Context, keys ...string) {
+	if !e.enabled || len(keys) == 0 {
+		return
+	}
+	fullKeys := make([]string, len(keys))
+	for i, key := range keys {
+		fullKeys[i] = e.key(key)
+		if e.local != nil {
+			e.local.Remove(fullKeys[i])
+		}
+	}
+	if e.m.rdb != nil {
+		if err := e.m.rdb.Del(ctx, fullKeys...).Err(); err != nil {
+			logger.FromContext(ctx).Error("删除缓存失败", logger.WrapMeta(err, logger.NewMeta("entity", e.name), logger.NewMeta("keys", keys))...)
+		}
+	}
+	e.m.publish(ctx, notice{Entity: e.name, Keys: keys})
+}
+
+/**
+ * @Description: 整类数据失效 版本加1后旧版本的key不再读取 在redis中等待过期
+ * @param ctx
+ */
+func (e *Entity) Flush(ctx context.Context) {
+	if !e.enabled {
+		return
+	}
+	version := e.version.Add(1)
+	if e.m.rdb != nil {
+		if v, err := e.m.rdb.Incr(ctx, e.versionKey()).Result(); err != nil {
+			logger.FromContext(ctx).Error("更新缓存版本失败", logger.WrapMeta(err, logger.NewMeta("entity", e.name))...)
+		} else {
+			version = v
+			e.setVersion(v)
+		}
+	}
+	if e.local != nil {
+		e.local.Purge()
+	}
+	e.m.publish(ctx, notice{Entity: e.name, Flush: true, Version: version})
+}
+
+// 处理其它实例的失效通知
+func (e *Entity) apply(n notice) {
+	if n.Flush {
+		e.setVersion(n.Version)
+		if e.local != nil {
+			e.local.Purge()
+		}
+		return
+	}
+	if e.local != nil {
+		for _, key := range n.Keys {
+			e.local.Remove(e.key(key))
+		}
+	}
+}
+
+// 读取redis中的版本
+func (e *Entity) loadVersion(ctx context.Context) error {
+	if !e.enabled {
+		return nil
+	}
+	v, err := e.m.rdb.Get(ctx, e.versionKey()).Int64()
+	if errors.Is(err, goredis.Nil) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	e.setVersion(v)
+	return nil
+}
+
+// 版本只增不减 乱序到达的通知不会回退版本
+func (e *Entity) setVersion(v int64) {
+	for {
+		current := e.version.Load()
+		if v <= current || e.version.CompareAndSwap(current, v) {
+			return
+		}
+	}
+}
+
+func (e *Entity) key(key string) string {
+	return keyPrefix + e.name + ":v" + strconv.FormatInt(e.version.Load(), 10) + ":" + key
+}
+
+func (e *Entity) versionKey() string {
+	return keyPrefix + e.name + ":version"
+}
+
+// 过期时间随机增加0到Jitter比例 避免同时写入的缓存同时过期
+func (e *Entity) ttl() time.Duration {
+	ttl := time.Duration(e.cfg.TTL) * time.Second
+	if e.cfg.Jitter > 0 {
+		ttl += time.Duration(rand.Float64() * e.cfg.Jitter * float64(ttl))
+	}
+	return ttl
+}
+
+// 先查本机缓存再查redis redis命中时写入本机缓存
+func (e *Entity) get(ctx context.Context, key string) ([]byte, bool) {
+	values := e.getMany(ctx, []string{key})
+	return values[0], values[0] != nil
+}
+
+// 批量查询 返回的切片和keys一一对应 未命中为nil
+func (e *Entity) getMany(ctx context.Context, keys []string) [][]byte {
+	values := make([][]byte, len(keys))
+	remaining := make([]int, 0, len(keys))
+	for i, key := range keys {
+		if e.local != nil {
+			if data, ok := e.local.Get(key); ok {
+				values[i] = data
+				e.observe(metrics.CacheTierLocal, true)
+				continue
+			}
+			e.observe(metrics.CacheTierLocal, false)
+		}
+		remaining = append(remaining, i)
+	}
+	if len(remaining) == 0 || e.m.rdb == nil {
+		return values
+	}
+
+	redisKeys := make([]string, len(remaining))
+	for i, index := range remaining {
+		redisKeys[i] = keys[index]
+	}
+	results, err := e.m.rdb.MGet(ctx, redisKeys...).Result()
+	if err != nil {
+		logger.FromContext(ctx).Error("读取缓存失败", logger.WrapMeta(err, logger.NewMeta("entity", e.name))...)
+		return values
+	}
+	for i, result := range results {
+		data, ok := result.(string)
+		e.observe(metrics.CacheTierRedis, ok)
+		if !ok {
+			continue
+		}
+		index := remaining[i]
+		values[index] = []byte(data)
+		if e.local != nil {
+			e.local.Add(keys[index], values[index])
+		}
+	}
+	return values
+}
+
+// 写入本机缓存和redis
+func (e *Entity) set(ctx context.Context, entries map[string][]byte) {
+	if len(entries) == 0 {
+		return
+	}
+	if e.local != nil {
+		for key, data := range entries {
+			e.local.Add(key, data)
+		}
+	}
+	if e.m.rdb == nil {
+		return
+	}
+	pipe := e.m.rdb.Pipeline()
+	for key, data := range entries {
+		pipe.Set(ctx, key, data, e.ttl())
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		logger.FromContext(ctx).Error("写入缓存失败", logger.WrapMeta(err, logger.NewMeta("entity", e.name))...)
+	}
+}
+
+// 统计命中率 未开启统计时不记录
+func (e *Entity) observe(tier string, hit bool) {
+	if !e.cfg.Metrics {
+		return
+	}
+	result := metrics.CacheMiss
+	if hit {
+		result = metrics.CacheHit
+	}
+	metrics.CacheRequests.WithLabelValues(e.name, tier, result).Inc()
+}
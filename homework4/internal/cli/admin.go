@@ -0,0 +1,41 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"homework4/internal/app"
+	"homework4/internal/metrics"
+	"homework4/pkg/logger"
+	"net"
+	"net/http"
+	"time"
+)
+
+// 管理端口服务 只暴露/metrics 不经过业务中间件
+func adminServerHook(port int) app.Hook {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.Handler())
+	server := &http.Server{
+		Addr:              fmt.Sprintf(":%d", port),
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	return app.Hook{
+		Name: "admin-server",
+		OnStart: func(ctx context.Context) error {
+			listener, err := net.Listen("tcp", server.Addr)
+			if err != nil {
+				return err
+			}
+			go func() {
+				if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+					logger.AppLog.Error("管理端口服务异常退出", logger.WrapMeta(err)...)
+				}
+			}()
+			logger.AppLog.Info("管理端口启动成功", logger.WrapMeta(nil, logger.NewMeta("port", port))...)
+			return nil
+		},
+		OnStop: server.Shutdown,
+	}
+}
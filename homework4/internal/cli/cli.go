@@ -0,0 +1,139 @@
+package cli
+
+/**
+ * @Description: 管理命令 所有子命令共用配置和日志初始化
+ * 用法: go run cmd/main.go [-config 配置文件路径] [-profile 环境] <命令> [参数]
+ */
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"homework4/config"
+	"homework4/internal/app"
+	"homework4/internal/app/database"
+	"homework4/internal/app/redis"
+	"homework4/internal/common"
+	"homework4/pkg/logger"
+	"io"
+	"os"
+	"sort"
+	"time"
+)
+
+// errUsage 参数错误 输出用法后退出码为2
+var errUsage = errors.New("usage")
+
+// 子命令
+type command struct {
+	usage string                              // 用法
+	short string                              // 说明
+	run   func(env *Env, args []string) error // 执行
+}
+
+var commands = map[string]command{
+	"serve":     {usage: "serve", short: "启动HTTP服务 不指定命令时默认执行", run: runServe},
+	"migrate":   {usage: "migrate [-steps N] [-dir DIR] up|down|status|create NAME", short: "数据库迁移", run: runMigrate},
+	"seed":      {usage: "seed [-set minimal|demo]", short: "写入固定的测试数据 已存在的用户会跳过", run: runSeed},
+	"user":      {usage: "user create [-admin] -username NAME -password PASSWORD | user reset-password -username NAME -password PASSWORD", short: "用户管理", run: runUser},
+	"token":     {usage: "token revoke -user ID或用户名", short: "吊销用户的登录令牌", run: runToken},
+	"reconcile": {usage: "reconcile [-fix]", short: "检查文章评论数和用户文章数 -fix时修正", run: runReconcile},
+	"openapi":   {usage: "openapi check", short: "检查注册的路由和接口文档是否一致", run: runOpenAPI},
+}
+
+// Env 子命令运行环境
+type Env struct {
+	ConfigPath string
+	Profile    string
+	Stdout     io.Writer
+}
+
+/**
+ * @Description: 解析全局参数并执行子命令
+ * @param args 不包含程序名的命令行参数
+ * @return int 退出码
+ */
+func Run(args []string) int {
+	global := flag.NewFlagSet("homework4", flag.ContinueOnError)
+	configPath := global.String("config", "", "配置文件路径 默认读取环境变量APP_CONFIG 都为空时使用config/config.yaml")
+	profile := global.String("profile", "", "环境 dev/test/prod 默认读取环境变量APP_PROFILE 必须指定其中一个")
+	global.Usage = func() { printUsage(global.Output()) }
+	if err := global.Parse(args); err != nil {
+		return 2
+	}
+
+	name := "serve"
+	rest := global.Args()
+	if len(rest) > 0 {
+		name, rest = rest[0], rest[1:]
+	}
+	cmd, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "未知命令: %s\n", name)
+		printUsage(os.Stderr)
+		return 2
+	}
+
+	initLogger()
+	//日志最后同步 在数据库和redis关闭之后
+	defer logger.AppLog.Sync()
+	env := &Env{ConfigPath: *configPath, Profile: *profile, Stdout: os.Stdout}
+	if err := cmd.run(env, rest); err != nil {
+		if errors.Is(err, errUsage) || errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintln(os.Stderr, "用法: homework4 [-config PATH] [-profile dev|test|prod] "+cmd.usage)
+			return 2
+		}
+		logger.AppLog.Error("命令执行失败", logger.WrapMeta(err, logger.NewMeta("command", name))...)
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "用法: homework4 [-config PATH] [-profile dev|test|prod] <命令> [参数]")
+	fmt.Fprintln(w, "命令:")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-10s %s\n", name, commands[name].short)
+		fmt.Fprintf(w, "  %-10s   %s\n", "", commands[name].usage)
+	}
+}
+
+// 初始化日志
+func initLogger() {
+	logger.InitAppLog(
+		// logger.WithDisableConsole(),
+		logger.WithTimeLayout(time.DateTime),
+		logger.WithFileP(common.GetLogFile()),
+	)
+}
+
+// 加载配置
+func (e *Env) loadConfig() *config.Config {
+	config.LoadConfig(e.ConfigPath, e.Profile)
+	if err := logger.SetLevel(config.Cfg.Log.Level); err != nil {
+		logger.AppLog.Fatal("日志级别错误", logger.WrapMeta(err)...)
+	}
+	return config.Cfg
+}
+
+// 加载配置并连接数据库和redis 创建应用容器
+func (e *Env) newContainer() *app.Container {
+	cfg := e.loadConfig()
+	db := database.InitDB(cfg.Database)
+	rdb := redis.InitRedis(cfg.Redis)
+	return app.NewContainer(cfg, db, rdb, app.GormRepositories(db, rdb))
+}
+
+// 解析子命令参数 参数错误时返回errUsage
+func parseFlags(flags *flag.FlagSet, args []string) error {
+	flags.SetOutput(io.Discard)
+	if err := flags.Parse(args); err != nil {
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+	return nil
+}
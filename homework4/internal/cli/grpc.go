@@ -0,0 +1,45 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"homework4/internal/app"
+	"homework4/pkg/logger"
+	"net"
+
+	"google.golang.org/grpc"
+)
+
+// gRPC服务 在HTTP服务之前启动 停止时等待处理中的请求结束 超时后强制关闭
+func grpcServerHook(port int, server *grpc.Server) app.Hook {
+	return app.Hook{
+		Name: "grpc-server",
+		OnStart: func(ctx context.Context) error {
+			listener, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+			if err != nil {
+				return err
+			}
+			go func() {
+				if err := server.Serve(listener); err != nil {
+					logger.AppLog.Error("gRPC服务异常退出", logger.WrapMeta(err)...)
+				}
+			}()
+			logger.AppLog.Info("gRPC服务启动成功", logger.WrapMeta(nil, logger.NewMeta("port", port))...)
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			stopped := make(chan struct{})
+			go func() {
+				server.GracefulStop()
+				close(stopped)
+			}()
+			select {
+			case <-stopped:
+				return nil
+			case <-ctx.Done():
+				server.Stop()
+				return ctx.Err()
+			}
+		},
+	}
+}
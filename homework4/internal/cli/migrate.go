@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"homework4/config"
+	"homework4/internal/app/database"
+	"homework4/internal/migrate"
+	"time"
+)
+
+// 数据库迁移 up/down/status只连接数据库 create不需要配置
+func runMigrate(env *Env, args []string) error {
+	flags := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	steps := flags.Int("steps", 0, "up时最多执行的数量 0为全部 down时回滚的数量 默认1")
+	dir := flags.String("dir", "migrations", "create时迁移文件根目录")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() < 1 {
+		return errUsage
+	}
+
+	action := flags.Arg(0)
+	if action == "create" {
+		if flags.NArg() < 2 {
+			return errUsage
+		}
+		files, err := migrate.Create(*dir, []string{config.DriverMySQL, config.DriverPostgres, config.DriverSQLite}, flags.Arg(1), time.Now())
+		for _, file := range files {
+			fmt.Fprintln(env.Stdout, file)
+		}
+		return err
+	}
+
+	cfg := env.loadConfig()
+	db, err := database.Open(cfg.Database)
+	if err != nil {
+		return err
+	}
+	if sqlDB, err := db.DB(); err == nil {
+		defer sqlDB.Close()
+	}
+	migrator, err := database.NewMigrator(db, cfg.Database)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	switch action {
+	case "up":
+		applied, err := migrator.Up(ctx, *steps)
+		for _, migration := range applied {
+			fmt.Fprintf(env.Stdout, "up   %d_%s\n", migration.Version, migration.Name)
+		}
+		return err
+	case "down":
+		reverted, err := migrator.Down(ctx, *steps)
+		for _, migration := range reverted {
+			fmt.Fprintf(env.Stdout, "down %d_%s\n", migration.Version, migration.Name)
+		}
+		return err
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			state := "pending"
+			switch {
+			case status.Missing:
+				state = "missing"
+			case status.Modified:
+				state = "modified"
+			case status.Applied:
+				state = "applied " + status.AppliedAt.Format(time.DateTime)
+			}
+			fmt.Fprintf(env.Stdout, "%d_%s\t%s\n", status.Version, status.Name, state)
+		}
+		return nil
+	default:
+		return errUsage
+	}
+}
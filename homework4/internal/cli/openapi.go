@@ -0,0 +1,33 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"homework4/internal/api/route"
+	"homework4/internal/app"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 接口文档 check检查注册的路由和接口文档是否一致 不一致时退出码为1 不连接数据库和redis
+func runOpenAPI(env *Env, args []string) error {
+	if len(args) < 1 || args[0] != "check" {
+		return errUsage
+	}
+	if err := parseFlags(flag.NewFlagSet("openapi check", flag.ContinueOnError), args[1:]); err != nil {
+		return err
+	}
+
+	cfg := env.loadConfig()
+	//可以关闭的接口也要写在文档中 gRPC gateway不在文档中 不需要创建
+	cfg.GraphQL.Enabled = true
+	cfg.GRPC.Enabled = false
+	gin.SetMode(gin.ReleaseMode)
+	container := app.NewContainer(cfg, nil, nil, app.MemoryRepositories())
+	r := route.InitRoutes(container)
+	if err := container.OpenAPI.CheckRoutes(r.Routes(), route.UndocumentedPrefixes...); err != nil {
+		return err
+	}
+	fmt.Fprintln(env.Stdout, "路由与接口文档一致")
+	return nil
+}
@@ -0,0 +1,40 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"homework4/pkg/logger"
+)
+
+// 检查冗余计数 -fix时修正不一致的计数
+func runReconcile(env *Env, args []string) error {
+	flags := flag.NewFlagSet("reconcile", flag.ContinueOnError)
+	fix := flags.Bool("fix", false, "修正不一致的计数")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	//修正计数后需要删除缓存 使用应用容器中的缓存
+	container := env.newContainer()
+	defer closeContainer(container)
+
+	drifts, err := container.CounterService.ReconcileCounters(context.Background(), *fix)
+	if err != nil {
+		return err
+	}
+	for _, drift := range drifts {
+		logger.AppLog.Warn("冗余计数不一致", logger.WrapMeta(nil,
+			logger.NewMeta("counter", drift.Counter),
+			logger.NewMeta("id", drift.ID),
+			logger.NewMeta("stored", drift.Stored),
+			logger.NewMeta("actual", drift.Actual),
+		)...)
+	}
+
+	if *fix {
+		logger.AppLog.Info("冗余计数检查完成", logger.WrapMeta(nil, logger.NewMeta("fixed", len(drifts)))...)
+	} else {
+		logger.AppLog.Info("冗余计数检查完成", logger.WrapMeta(nil, logger.NewMeta("drifted", len(drifts)))...)
+	}
+	return nil
+}
@@ -0,0 +1,160 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"homework4/internal/app"
+	"homework4/internal/models"
+	"homework4/internal/service"
+)
+
+// 测试用户 密码统一为seedPassword
+type seedUser struct {
+	username string
+	nickname string
+	admin    bool
+}
+
+// 测试文章 author为seedUser的下标
+type seedPost struct {
+	author  int
+	title   string
+	content string
+}
+
+// 测试数据集 内容固定 每次执行结果相同
+type seedSet struct {
+	users    []seedUser
+	posts    []seedPost
+	comments int      // 每篇文章的评论数 评论人按用户顺序轮流
+	follows  [][2]int // 关注关系 [关注者下标, 被关注者下标]
+}
+
+const seedPassword = "123456"
+
+var seedSets = map[string]seedSet{
+	"minimal": {
+		users: []seedUser{
+			{username: "admin", nickname: "管理员", admin: true},
+			{username: "alice", nickname: "Alice"},
+		},
+		posts: []seedPost{
+			{author: 1, title: "第一篇文章", content: "这是alice的第一篇文章"},
+		},
+		comments: 1,
+		follows:  [][2]int{{0, 1}},
+	},
+	"demo": {
+		users: []seedUser{
+			{username: "admin", nickname: "管理员", admin: true},
+			{username: "alice", nickname: "Alice"},
+			{username: "bob", nickname: "Bob"},
+			{username: "carol", nickname: "Carol"},
+			{username: "dave", nickname: "Dave"},
+		},
+		posts: []seedPost{
+			{author: 1, title: "Go并发入门", content: "goroutine和channel的基本用法"},
+			{author: 1, title: "Gin中间件", content: "如何编写一个鉴权中间件"},
+			{author: 2, title: "GORM事务", content: "在事务中维护冗余计数"},
+			{author: 2, title: "Redis计数器", content: "先写redis再定时刷入数据库"},
+			{author: 3, title: "读扩散和写扩散", content: "首页动态的两种实现方式"},
+			{author: 4, title: "热门排行", content: "按时间衰减的热度分数"},
+		},
+		comments: 3,
+		follows:  [][2]int{{1, 2}, {2, 3}, {3, 4}, {4, 1}, {1, 3}, {2, 1}},
+	},
+}
+
+// 写入测试数据 已存在的用户会跳过 只给本次创建的用户写入文章和评论
+func runSeed(env *Env, args []string) error {
+	ctx := context.Background()
+	flags := flag.NewFlagSet("seed", flag.ContinueOnError)
+	setName := flags.String("set", "minimal", "数据集 minimal或demo")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	set, ok := seedSets[*setName]
+	if !ok {
+		return fmt.Errorf("未知数据集: %s", *setName)
+	}
+
+	container := env.newContainer()
+	defer closeContainer(container)
+	users, created, err := seedUsers(ctx, container, set.users)
+	if err != nil {
+		return err
+	}
+
+	postCount, commentCount := 0, 0
+	for _, p := range set.posts {
+		if !created[p.author] {
+			continue
+		}
+		post, err := container.PostService.CreatePost(ctx, &service.CreatePostRequest{Title: p.title, Content: p.content}, users[p.author].ID)
+		if err != nil {
+			return err
+		}
+		postCount++
+		for i := 0; i < set.comments; i++ {
+			commenter := users[(p.author+i+1)%len(users)]
+			content := fmt.Sprintf("%s的第%d条评论", commenter.Nickname, i+1)
+			if _, err := container.CommentService.CreateComment(ctx, &service.CreateCommentRequest{PostID: post.ID, Content: content}, commenter.ID); err != nil {
+				return err
+			}
+			commentCount++
+		}
+	}
+
+	for _, follow := range set.follows {
+		follower, followee := users[follow[0]], users[follow[1]]
+		following, err := container.FollowService.IsFollowing(ctx, follower.ID, followee.ID)
+		if err != nil {
+			return err
+		}
+		if following {
+			continue
+		}
+		if err := container.FollowService.Follow(ctx, &service.FollowRequest{UserID: followee.ID}, follower.ID); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintf(env.Stdout, "写入数据集%s完成 新建用户%d个 文章%d篇 评论%d条 默认密码%s\n", *setName, countTrue(created), postCount, commentCount, seedPassword)
+	return nil
+}
+
+// 创建测试用户 已存在时直接使用
+func seedUsers(ctx context.Context, container *app.Container, seeds []seedUser) ([]*models.User, []bool, error) {
+	users := make([]*models.User, len(seeds))
+	created := make([]bool, len(seeds))
+	for i, seed := range seeds {
+		user, err := container.UserService.FindUser(ctx, seed.username)
+		if err == nil {
+			users[i] = user
+			continue
+		}
+		user, err = container.UserService.CreateUser(ctx, &service.RegisterRequest{
+			Username: seed.username,
+			Nickname: seed.nickname,
+			Password: seedPassword,
+			Email:    seed.username + "@example.com",
+		}, seed.admin)
+		if err != nil {
+			return nil, nil, err
+		}
+		users[i] = user
+		created[i] = true
+	}
+	return users, created, nil
+}
+
+func countTrue(values []bool) int {
+	count := 0
+	for _, value := range values {
+		if value {
+			count++
+		}
+	}
+	return count
+}
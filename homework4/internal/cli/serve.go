@@ -0,0 +1,160 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"homework4/config"
+	"homework4/internal/api/route"
+	"homework4/internal/app"
+	"homework4/internal/feature"
+	"homework4/internal/health"
+	"homework4/internal/job"
+	"homework4/internal/metrics"
+	"homework4/internal/tracing"
+	"homework4/internal/validation"
+	"homework4/pkg/logger"
+	"net"
+	"net/http"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// 启动HTTP服务和gRPC服务 收到SIGINT或SIGTERM后等待处理中的请求结束 再停止后台任务并关闭连接
+func runServe(env *Env, args []string) error {
+	if err := parseFlags(flag.NewFlagSet("serve", flag.ContinueOnError), args); err != nil {
+		return err
+	}
+	container := env.newContainer()
+	defer closeContainer(container)
+	cfg := container.Config
+
+	//链路追踪最先注册 最后停止 导出退出过程中的span
+	shutdownTracing, err := tracing.Init(cfg.Tracing)
+	if err != nil {
+		return err
+	}
+	container.Lifecycle.Append(app.Hook{Name: "tracing", OnStop: shutdownTracing})
+
+	//指标 管理端口最先启动最后停止
+	if cfg.Metrics.Enabled {
+		if sqlDB, err := container.DB.DB(); err == nil {
+			if err := metrics.RegisterDBStats(sqlDB, cfg.Database.DBName); err != nil {
+				return err
+			}
+		}
+		if cfg.Metrics.AdminPort > 0 {
+			container.Lifecycle.Append(adminServerHook(cfg.Metrics.AdminPort))
+		}
+	}
+
+	//订阅其它实例的缓存失效通知
+	container.Lifecycle.Append(app.Hook{Name: "cache", OnStart: container.Cache.Start, OnStop: container.Cache.Stop})
+
+	//注册后台任务 写扩散模式才执行写扩散任务
+	workers := []*job.Worker{
+		job.NewReactionWorker(container.ReactionService, cfg.Reaction),
+		job.NewPostWorker(container.ViewService, container.HotService, cfg.View, cfg.Hot),
+	}
+	if cfg.Feed.Mode == config.FeedModeWrite {
+		workers = append(workers, job.NewFeedWorker(container.FeedService, cfg.Feed))
+	}
+	for _, worker := range workers {
+		container.Lifecycle.Append(app.Hook{Name: worker.Name(), OnStart: worker.Start, OnStop: worker.Stop})
+		container.Health.Register(worker.Name(), 0, health.HeartbeatCheck(worker))
+	}
+
+	//重新加载配置 只更新日志级别、跨域、限流、幂等、违禁词和功能开关
+	reloader := config.NewReloader(env.ConfigPath, env.Profile, cfg)
+	reloader.Subscribe("logger", func(cfg *config.Config) error {
+		return logger.SetLevel(cfg.Log.Level)
+	})
+	reloader.Subscribe("cors", func(cfg *config.Config) error {
+		return container.CORS.Update(cfg.CORS)
+	})
+	reloader.Subscribe("rateLimit", func(cfg *config.Config) error {
+		container.RateLimiter.Update(cfg.RateLimit)
+		return nil
+	})
+	reloader.Subscribe("idempotency", func(cfg *config.Config) error {
+		container.Idempotency.Update(cfg.Idempotency)
+		return nil
+	})
+	reloader.Subscribe("validation", func(cfg *config.Config) error {
+		validation.Update(cfg.Validation)
+		return nil
+	})
+	reloader.Subscribe("features", func(cfg *config.Config) error {
+		feature.Set(cfg.Features)
+		return nil
+	})
+	container.Lifecycle.Append(app.Hook{Name: "config-reloader", OnStart: reloader.Start, OnStop: reloader.Stop})
+
+	//gRPC服务最后注册 退出时在后台任务之前停止
+	if container.GRPCServer != nil {
+		container.Lifecycle.Append(grpcServerHook(cfg.GRPC.Port, container.GRPCServer))
+	}
+
+	//初始化路由
+	r := route.InitRoutes(container)
+	server := &http.Server{
+		Handler:           r,
+		ReadTimeout:       time.Duration(cfg.App.ReadTimeout) * time.Second,
+		ReadHeaderTimeout: time.Duration(cfg.App.ReadHeaderTimeout) * time.Second,
+		WriteTimeout:      time.Duration(cfg.App.WriteTimeout) * time.Second,
+		IdleTimeout:       time.Duration(cfg.App.IdleTimeout) * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	//先监听端口 端口被占用时直接失败
+	port := fmt.Sprintf("%d", cfg.App.Port)
+	listener, err := net.Listen("tcp", ":"+port)
+	if err != nil {
+		return err
+	}
+	if err := container.Lifecycle.Start(ctx); err != nil {
+		listener.Close()
+		return err
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- server.Serve(listener)
+	}()
+	logger.AppLog.Info("服务启动成功,端口：" + port)
+
+	var runErr error
+	select {
+	case <-ctx.Done():
+		logger.AppLog.Info("收到退出信号 开始关闭服务")
+	case err := <-serveErr:
+		runErr = err
+	}
+
+	//就绪检查先失败 负载均衡不再转发新请求
+	container.Health.SetShuttingDown()
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.App.ShutdownTimeout)*time.Second)
+	defer cancel()
+	//不再接受新请求 等待处理中的请求结束
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		runErr = errors.Join(runErr, fmt.Errorf("等待请求结束失败: %w", err))
+	}
+	if err := container.Lifecycle.Stop(shutdownCtx); err != nil {
+		runErr = errors.Join(runErr, err)
+	}
+	if runErr == nil {
+		logger.AppLog.Info("服务已关闭")
+	}
+	return runErr
+}
+
+// 关闭数据库和redis连接
+func closeContainer(container *app.Container) {
+	if err := container.Close(); err != nil {
+		logger.AppLog.Error("关闭连接失败", logger.WrapMeta(err)...)
+	}
+}
@@ -0,0 +1,35 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+)
+
+// 登录令牌管理 revoke吊销用户的令牌
+func runToken(env *Env, args []string) error {
+	ctx := context.Background()
+	if len(args) < 1 || args[0] != "revoke" {
+		return errUsage
+	}
+	flags := flag.NewFlagSet("token revoke", flag.ContinueOnError)
+	userFlag := flags.String("user", "", "用户ID或用户名")
+	if err := parseFlags(flags, args[1:]); err != nil {
+		return err
+	}
+	if *userFlag == "" {
+		return errUsage
+	}
+
+	container := env.newContainer()
+	defer closeContainer(container)
+	user, err := container.UserService.FindUser(ctx, *userFlag)
+	if err != nil {
+		return err
+	}
+	if err := container.UserService.RevokeToken(ctx, user.ID); err != nil {
+		return err
+	}
+	fmt.Fprintf(env.Stdout, "吊销登录令牌成功 id=%d username=%s\n", user.ID, user.Username)
+	return nil
+}
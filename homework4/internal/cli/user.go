@@ -0,0 +1,93 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"homework4/internal/errcode"
+	"homework4/internal/service"
+	"homework4/internal/validation"
+	"strings"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// 用户管理 create创建用户 reset-password重置密码
+func runUser(env *Env, args []string) error {
+	ctx := context.Background()
+	if len(args) < 1 {
+		return errUsage
+	}
+	action, args := args[0], args[1:]
+
+	flags := flag.NewFlagSet("user "+action, flag.ContinueOnError)
+	username := flags.String("username", "", "用户名")
+	password := flags.String("password", "", "密码 至少6个字符 同时包含字母和数字")
+	nickname := flags.String("nickname", "", "昵称 默认和用户名相同")
+	email := flags.String("email", "", "邮箱")
+	admin := flags.Bool("admin", false, "创建管理员")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if *username == "" || *password == "" {
+		return errUsage
+	}
+	if *nickname == "" {
+		*nickname = *username
+	}
+	//和注册接口使用相同的校验规则
+	req := &service.RegisterRequest{
+		Username: *username,
+		Nickname: *nickname,
+		Password: *password,
+		Email:    *email,
+	}
+	validation.Setup(env.loadConfig().Validation)
+
+	switch action {
+	case "create":
+		if err := validateRequest(binding.Validator.ValidateStruct(req)); err != nil {
+			return err
+		}
+		container := env.newContainer()
+		defer closeContainer(container)
+		user, err := container.UserService.CreateUser(ctx, req, *admin)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(env.Stdout, "创建用户成功 id=%d username=%s admin=%t\n", user.ID, user.Username, user.IsAdmin)
+		return nil
+	case "reset-password":
+		engine := binding.Validator.Engine().(*validator.Validate)
+		if err := validateRequest(engine.StructPartial(req, "Password")); err != nil {
+			return err
+		}
+		container := env.newContainer()
+		defer closeContainer(container)
+		if err := container.UserService.ResetPassword(ctx, *username, *password); err != nil {
+			return err
+		}
+		fmt.Fprintf(env.Stdout, "重置密码成功 username=%s 登录令牌已吊销\n", *username)
+		return nil
+	default:
+		return errUsage
+	}
+}
+
+// 参数校验错误转换为每个字段一行的提示
+func validateRequest(err error) error {
+	if err == nil {
+		return nil
+	}
+	details := validation.Details(err, errcode.LangZH)
+	if len(details) == 0 {
+		return err
+	}
+	messages := make([]string, len(details))
+	for i, detail := range details {
+		messages[i] = detail.Message
+	}
+	return errors.New(strings.Join(messages, "\n"))
+}
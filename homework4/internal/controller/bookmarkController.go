@@ -0,0 +1,221 @@
+package controller
+
+import (
+	"homework4/internal/middleware/auth"
+	"homework4/internal/middleware/response"
+	"homework4/internal/service"
+	"homework4/internal/validation"
+
+	"github.com/gin-gonic/gin"
+)
+
+type BookmarkController struct {
+	bookmarkService *service.BookmarkService
+}
+
+func NewBookmarkController(bookmarkService *service.BookmarkService) *BookmarkController {
+	return &BookmarkController{
+		bookmarkService: bookmarkService,
+	}
+}
+
+/**
+ * @Description: 添加收藏
+ * @param c
+ * @return error
+ */
+func (ctrl *BookmarkController) AddBookmark(c *gin.Context) error {
+	var req service.AddBookmarkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		return response.NewValidationError(err)
+	}
+	//查询登录信息
+	authUser := auth.GetCurrentAuthUser(c)
+	//添加收藏
+	if err := ctrl.bookmarkService.AddBookmark(c.Request.Context(), &req, authUser.UserID); err != nil {
+		return err
+	}
+
+	response.SendJSON(c, gin.H{
+		"message": "收藏成功",
+	})
+	return nil
+}
+
+/**
+ * @Description: 取消收藏
+ * @param c
+ * @return error
+ */
+func (ctrl *BookmarkController) RemoveBookmark(c *gin.Context) error {
+	var req service.RemoveBookmarkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		return response.NewValidationError(err)
+	}
+	//查询登录信息
+	authUser := auth.GetCurrentAuthUser(c)
+	//取消收藏
+	if err := ctrl.bookmarkService.RemoveBookmark(c.Request.Context(), &req, authUser.UserID); err != nil {
+		return err
+	}
+
+	response.SendJSON(c, gin.H{
+		"message": "取消成功",
+	})
+	return nil
+}
+
+/**
+ * @Description: 调整收藏顺序
+ * @param c
+ * @return error
+ */
+func (ctrl *BookmarkController) ReorderBookmarks(c *gin.Context) error {
+	var req service.ReorderBookmarkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		return response.NewValidationError(err)
+	}
+	//查询登录信息
+	authUser := auth.GetCurrentAuthUser(c)
+	//调整顺序
+	if err := ctrl.bookmarkService.ReorderBookmarks(c.Request.Context(), &req, authUser.UserID); err != nil {
+		return err
+	}
+
+	response.SendJSON(c, gin.H{
+		"message": "调整成功",
+	})
+	return nil
+}
+
+/**
+ * @Description: 获取自己的收藏
+ * @param c
+ * @return error
+ */
+func (ctrl *BookmarkController) GetBookmarkList(c *gin.Context) error {
+	var req service.GetBookmarkListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		return response.NewValidationError(err)
+	}
+	//查询登录信息
+	authUser := auth.GetCurrentAuthUser(c)
+
+	page, err := ctrl.bookmarkService.GetBookmarkList(c.Request.Context(), &req, authUser.UserID)
+	if err != nil {
+		return err
+	}
+
+	response.SendJSON(c, page)
+	return nil
+}
+
+/**
+ * @Description: 创建阅读清单
+ * @param c
+ * @return error
+ */
+func (ctrl *BookmarkController) CreateReadingList(c *gin.Context) error {
+	var req service.CreateReadingListRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		return response.NewValidationError(err)
+	}
+	//查询登录信息
+	authUser := auth.GetCurrentAuthUser(c)
+	//创建阅读清单
+	list, err := ctrl.bookmarkService.CreateReadingList(c.Request.Context(), &req, authUser.UserID)
+	if err != nil {
+		return err
+	}
+
+	response.SendJSON(c, list)
+	return nil
+}
+
+/**
+ * @Description: 更新阅读清单
+ * @param c
+ * @return error
+ */
+func (ctrl *BookmarkController) UpdateReadingList(c *gin.Context) error {
+	var req service.UpdateReadingListRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		return response.NewValidationError(err)
+	}
+	//查询登录信息
+	authUser := auth.GetCurrentAuthUser(c)
+	//更新阅读清单
+	updated, err := ctrl.bookmarkService.UpdateReadingList(c.Request.Context(), &req, authUser.UserID)
+	if err != nil {
+		return err
+	}
+
+	response.SendJSON(c, updated)
+	return nil
+}
+
+/**
+ * @Description: 删除阅读清单
+ * @param c
+ * @return error
+ */
+func (ctrl *BookmarkController) DeleteReadingList(c *gin.Context) error {
+	var req service.DeleteReadingListRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		return response.NewValidationError(err)
+	}
+	//查询登录信息
+	authUser := auth.GetCurrentAuthUser(c)
+	//删除阅读清单会校验是不是当前用户的清单
+	if err := ctrl.bookmarkService.DeleteReadingList(c.Request.Context(), &req, authUser.UserID); err != nil {
+		return err
+	}
+
+	response.SendJSON(c, gin.H{
+		"message": "删除成功",
+	})
+	return nil
+}
+
+/**
+ * @Description: 获取自己的阅读清单
+ * @param c
+ * @return error
+ */
+func (ctrl *BookmarkController) GetMyReadingLists(c *gin.Context) error {
+	//查询登录信息
+	authUser := auth.GetCurrentAuthUser(c)
+
+	lists, err := ctrl.bookmarkService.GetMyReadingLists(c.Request.Context(), authUser.UserID)
+	if err != nil {
+		return err
+	}
+
+	response.SendJSON(c, lists)
+	return nil
+}
+
+/**
+ * @Description: 获取阅读清单中的收藏
+ * @param c
+ * @return error
+ */
+func (ctrl *BookmarkController) GetReadingListBookmarks(c *gin.Context) error {
+	var req service.GetBookmarkListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		return response.NewValidationError(err)
+	}
+	if req.ListID == 0 {
+		return response.NewValidationError(&validation.FieldError{Field: "listId", Rule: "required"})
+	}
+	//查询登录信息 未登录时用户ID为0
+	authUser, _ := auth.GetOptionalAuthUser(c)
+
+	page, err := ctrl.bookmarkService.GetReadingListBookmarks(c.Request.Context(), &req, authUser.UserID)
+	if err != nil {
+		return err
+	}
+
+	response.SendJSON(c, page)
+	return nil
+}
@@ -12,9 +12,9 @@ type CommentController struct {
 	commentService *service.CommentService
 }
 
-func NewCommentController() *CommentController {
+func NewCommentController(commentService *service.CommentService) *CommentController {
 	return &CommentController{
-		commentService: service.NewCommentService(),
+		commentService: commentService,
 	}
 }
 
@@ -23,56 +23,82 @@ func NewCommentController() *CommentController {
  * @param c
  * @return error
  */
-// CreateComment godoc
-// @Summary 创建评论
-// @Description 为文章创建评论,需要登录
-// @Tags 评论管理
-// @Accept json
-// @Produce json
-// @Param request body service.CreateCommentRequest true "评论信息"
-// @Security Bearer
-// @Success 200 {object} response.Response{data=service.CommentResponse} "创建成功"
-// @Failure 400 {object} response.Response "参数错误"
-// @Failure 401 {object} response.Response "未授权"
-// @Router /comment/create [post]
 func (ctrl *CommentController) CreateComment(c *gin.Context) error {
 	var req service.CreateCommentRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		return response.NewBadRequestError("参数错误: " + err.Error())
+		return response.NewValidationError(err)
 	}
 	//查询登录信息
 	authUser := auth.GetCurrentAuthUser(c)
 	//创建评论
-	comment, err := ctrl.commentService.CreateComment(&req, authUser.UserID)
+	comment, err := ctrl.commentService.CreateComment(c.Request.Context(), &req, authUser.UserID)
 	if err != nil {
-		return response.NewBizError(response.CodeBadRequest, err.Error(), nil)
+		return err
 	}
 
 	response.SendJSON(c, comment)
 	return nil
 }
 
-// GetCommentList godoc
-// @Summary 获取评论列表
-// @Description 分页获取文章评论列表,不需要登录
-// @Tags 评论管理
-// @Accept json
-// @Produce json
-// @Param postId query int true "文章ID"
-// @Param page query int false "页码" default(1)
-// @Param pageSize query int false "每页数量" default(10)
-// @Success 200 {object} response.Response{data=map[string]interface{}} "获取成功"
-// @Failure 400 {object} response.Response "参数错误"
-// @Router /comment/list [get]
+/**
+ * @Description: 删除评论
+ * @param c
+ * @return error
+ */
+func (ctrl *CommentController) DeleteComment(c *gin.Context) error {
+	var req service.DeleteCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		return response.NewValidationError(err)
+	}
+	//查询登录信息
+	authUser := auth.GetCurrentAuthUser(c)
+	//删除评论会校验是不是评论作者或文章作者
+	if err := ctrl.commentService.DeleteComment(c.Request.Context(), &req, authUser.UserID); err != nil {
+		return err
+	}
+
+	response.SendJSON(c, gin.H{
+		"message": "删除成功",
+	})
+	return nil
+}
+
+/**
+ * @Description: 批量删除评论
+ * @param c
+ * @return error
+ */
+func (ctrl *CommentController) BatchDeleteComments(c *gin.Context) error {
+	var req service.BatchDeleteCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		return response.NewValidationError(err)
+	}
+	//查询登录信息
+	authUser := auth.GetCurrentAuthUser(c)
+	//只会删除当前用户的评论
+	deleted, err := ctrl.commentService.BatchDeleteComments(c.Request.Context(), &req, authUser.UserID)
+	if err != nil {
+		return err
+	}
+
+	response.SendJSON(c, gin.H{
+		"message": "删除成功",
+		"deleted": deleted,
+	})
+	return nil
+}
+
 func (ctrl *CommentController) GetCommentList(c *gin.Context) error {
 	var req service.GetCommentListRequest
 	if err := c.ShouldBindQuery(&req); err != nil {
-		return response.NewBadRequestError("参数错误: " + err.Error())
+		return response.NewValidationError(err)
 	}
 
-	comments, total, err := ctrl.commentService.GetCommentList(&req)
+	//查询登录信息 未登录时用户ID为0
+	authUser, _ := auth.GetOptionalAuthUser(c)
+	comments, total, err := ctrl.commentService.GetCommentList(c.Request.Context(), &req, authUser.UserID)
 	if err != nil {
-		return response.NewBizError(response.CodeBadRequest, err.Error(), nil)
+		return err
 	}
 
 	response.SendJSON(c, gin.H{
@@ -0,0 +1,111 @@
+package controller
+
+import (
+	"homework4/internal/middleware/auth"
+	"homework4/internal/middleware/response"
+	"homework4/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type FollowController struct {
+	followService *service.FollowService
+}
+
+func NewFollowController(followService *service.FollowService) *FollowController {
+	return &FollowController{
+		followService: followService,
+	}
+}
+
+/**
+ * @Description: 关注用户
+ * @param c
+ * @return error
+ */
+func (ctrl *FollowController) Follow(c *gin.Context) error {
+	var req service.FollowRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		return response.NewValidationError(err)
+	}
+	//查询登录信息
+	authUser := auth.GetCurrentAuthUser(c)
+	//关注用户
+	if err := ctrl.followService.Follow(c.Request.Context(), &req, authUser.UserID); err != nil {
+		return err
+	}
+
+	response.SendJSON(c, gin.H{
+		"message": "关注成功",
+	})
+	return nil
+}
+
+/**
+ * @Description: 取消关注
+ * @param c
+ * @return error
+ */
+func (ctrl *FollowController) Unfollow(c *gin.Context) error {
+	var req service.FollowRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		return response.NewValidationError(err)
+	}
+	//查询登录信息
+	authUser := auth.GetCurrentAuthUser(c)
+	//取消关注
+	if err := ctrl.followService.Unfollow(c.Request.Context(), &req, authUser.UserID); err != nil {
+		return err
+	}
+
+	response.SendJSON(c, gin.H{
+		"message": "取消成功",
+	})
+	return nil
+}
+
+/**
+ * @Description: 获取粉丝列表
+ * @param c
+ * @return error
+ */
+func (ctrl *FollowController) GetFollowers(c *gin.Context) error {
+	var req service.GetFollowListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		return response.NewValidationError(err)
+	}
+
+	users, total, err := ctrl.followService.GetFollowers(c.Request.Context(), &req)
+	if err != nil {
+		return err
+	}
+
+	response.SendJSON(c, gin.H{
+		"list":  users,
+		"total": total,
+	})
+	return nil
+}
+
+/**
+ * @Description: 获取关注列表
+ * @param c
+ * @return error
+ */
+func (ctrl *FollowController) GetFollowing(c *gin.Context) error {
+	var req service.GetFollowListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		return response.NewValidationError(err)
+	}
+
+	users, total, err := ctrl.followService.GetFollowing(c.Request.Context(), &req)
+	if err != nil {
+		return err
+	}
+
+	response.SendJSON(c, gin.H{
+		"list":  users,
+		"total": total,
+	})
+	return nil
+}
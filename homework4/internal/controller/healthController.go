@@ -0,0 +1,43 @@
+package controller
+
+import (
+	"homework4/internal/health"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type HealthController struct {
+	registry *health.Registry
+}
+
+func NewHealthController(registry *health.Registry) *HealthController {
+	return &HealthController{
+		registry: registry,
+	}
+}
+
+/**
+ * @Description: 存活检查 进程能处理请求即返回正常 不检查依赖 失败时应重启进程
+ * @param c
+ * @return error
+ */
+func (ctrl *HealthController) Livez(c *gin.Context) error {
+	c.JSON(http.StatusOK, gin.H{"status": health.StatusUp})
+	return nil
+}
+
+/**
+ * @Description: 就绪检查 检查数据库、redis、迁移版本和后台任务心跳 任一异常或正在关闭时返回503
+ * @param c
+ * @return error
+ */
+func (ctrl *HealthController) Readyz(c *gin.Context) error {
+	report := ctrl.registry.Ready(c.Request.Context())
+	statusCode := http.StatusOK
+	if report.Status != health.StatusUp {
+		statusCode = http.StatusServiceUnavailable
+	}
+	c.JSON(statusCode, report)
+	return nil
+}
@@ -1,6 +1,7 @@
 package controller
 
 import (
+	"fmt"
 	"homework4/internal/middleware/auth"
 	"homework4/internal/middleware/response"
 	"homework4/internal/service"
@@ -12,9 +13,9 @@ type PostController struct {
 	postService *service.PostService
 }
 
-func NewPostController() *PostController {
+func NewPostController(postService *service.PostService) *PostController {
 	return &PostController{
-		postService: service.NewPostService(),
+		postService: postService,
 	}
 }
 
@@ -23,29 +24,17 @@ func NewPostController() *PostController {
  * @param c
  * @return error
  */
-// CreatePost godoc
-// @Summary 创建文章
-// @Description 创建新文章,需要登录
-// @Tags 文章管理
-// @Accept json
-// @Produce json
-// @Param request body service.CreatePostRequest true "文章信息"
-// @Security Bearer
-// @Success 200 {object} response.Response{data=service.PostResponse} "创建成功"
-// @Failure 400 {object} response.Response "参数错误"
-// @Failure 401 {object} response.Response "未授权"
-// @Router /post/create [post]
 func (ctrl *PostController) CreatePost(c *gin.Context) error {
 	var req service.CreatePostRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		return response.NewBadRequestError("参数错误: " + err.Error())
+		return response.NewValidationError(err)
 	}
 	//查询登录信息
 	authUser := auth.GetCurrentAuthUser(c)
 	//创建文章
-	post, err := ctrl.postService.CreatePost(&req, authUser.UserID)
+	post, err := ctrl.postService.CreatePost(c.Request.Context(), &req, authUser.UserID)
 	if err != nil {
-		return response.NewBizError(response.CodeBadRequest, err.Error(), nil)
+		return err
 	}
 
 	response.SendJSON(c, post)
@@ -57,29 +46,17 @@ func (ctrl *PostController) CreatePost(c *gin.Context) error {
  * @param c
  * @return error
  */
-// UpdatePost godoc
-// @Summary 更新文章
-// @Description 更新文章信息,需要登录
-// @Tags 文章管理
-// @Accept json
-// @Produce json
-// @Param request body service.UpdatePostRequest true "文章信息"
-// @Security Bearer
-// @Success 200 {object} response.Response{data=service.PostResponse} "更新成功"
-// @Failure 400 {object} response.Response "参数错误"
-// @Failure 401 {object} response.Response "未授权"
-// @Router /post/update [put]
 func (ctrl *PostController) UpdatePost(c *gin.Context) error {
 	var req service.UpdatePostRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		return response.NewBadRequestError("参数错误: " + err.Error())
+		return response.NewValidationError(err)
 	}
 	//查询登录信息
 	authUser := auth.GetCurrentAuthUser(c)
 	//更新文章
-	post, err := ctrl.postService.UpdatePost(&req, authUser.UserID)
+	post, err := ctrl.postService.UpdatePost(c.Request.Context(), &req, authUser.UserID)
 	if err != nil {
-		return response.NewBizError(response.CodeBadRequest, err.Error(), nil)
+		return err
 	}
 
 	response.SendJSON(c, post)
@@ -91,29 +68,17 @@ func (ctrl *PostController) UpdatePost(c *gin.Context) error {
  * @param c
  * @return error
  */
-// DeletePost godoc
-// @Summary 删除文章
-// @Description 删除文章,需要登录
-// @Tags 文章管理
-// @Accept json
-// @Produce json
-// @Param request body service.DeletePostRequest true "文章ID"
-// @Security Bearer
-// @Success 200 {object} response.Response{data=map[string]interface{}} "删除成功"
-// @Failure 400 {object} response.Response "参数错误"
-// @Failure 401 {object} response.Response "未授权"
-// @Router /post/delete [delete]
 func (ctrl *PostController) DeletePost(c *gin.Context) error {
 	var req service.DeletePostRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		return response.NewBadRequestError("参数错误: " + err.Error())
+		return response.NewValidationError(err)
 	}
 	//查询登录信息
 	authUser := auth.GetCurrentAuthUser(c)
 	//删除文章会校验是不是当前用户的文章
-	err := ctrl.postService.DeletePost(&req, authUser.UserID)
+	err := ctrl.postService.DeletePost(c.Request.Context(), &req, authUser.UserID)
 	if err != nil {
-		return response.NewBizError(response.CodeBadRequest, err.Error(), nil)
+		return err
 	}
 
 	response.SendJSON(c, gin.H{
@@ -122,31 +87,94 @@ func (ctrl *PostController) DeletePost(c *gin.Context) error {
 	return nil
 }
 
+/**
+ * @Description: 批量删除文章
+ * @param c
+ * @return error
+ */
+func (ctrl *PostController) BatchDeletePosts(c *gin.Context) error {
+	var req service.BatchDeletePostRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		return response.NewValidationError(err)
+	}
+	//查询登录信息
+	authUser := auth.GetCurrentAuthUser(c)
+	//只会删除当前用户的文章
+	deleted, err := ctrl.postService.BatchDeletePosts(c.Request.Context(), &req, authUser.UserID)
+	if err != nil {
+		return err
+	}
+
+	response.SendJSON(c, gin.H{
+		"message": "删除成功",
+		"deleted": deleted,
+	})
+	return nil
+}
+
 /**
  * @Description: 获取文章分页
  * @param c
  * @return error
  */
-// GetPostList godoc
-// @Summary 获取文章列表
-// @Description 分页获取文章列表,不需要登录
-// @Tags 文章管理
-// @Accept json
-// @Produce json
-// @Param page query int false "页码" default(1)
-// @Param pageSize query int false "每页数量" default(10)
-// @Success 200 {object} response.Response{data=map[string]interface{}} "获取成功"
-// @Failure 400 {object} response.Response "参数错误"
-// @Router /post/list [get]
 func (ctrl *PostController) GetPostList(c *gin.Context) error {
 	var req service.GetPostListRequest
 	if err := c.ShouldBindQuery(&req); err != nil {
-		return response.NewBadRequestError("参数错误: " + err.Error())
+		return response.NewValidationError(err)
+	}
+
+	//查询登录信息 未登录时用户ID为0
+	authUser, _ := auth.GetOptionalAuthUser(c)
+	posts, total, err := ctrl.postService.GetPostList(c.Request.Context(), &req, authUser.UserID)
+	if err != nil {
+		return err
+	}
+
+	response.SendJSON(c, gin.H{
+		"list":  posts,
+		"total": total,
+	})
+	return nil
+}
+
+/**
+ * @Description: 获取首页动态
+ * @param c
+ * @return error
+ */
+func (ctrl *PostController) GetFeed(c *gin.Context) error {
+	var req service.GetFeedRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		return response.NewValidationError(err)
 	}
+	//查询登录信息
+	authUser := auth.GetCurrentAuthUser(c)
 
-	posts, total, err := ctrl.postService.GetPostList(&req)
+	feed, err := ctrl.postService.GetFeed(c.Request.Context(), &req, authUser.UserID)
 	if err != nil {
-		return response.NewBizError(response.CodeBadRequest, err.Error(), nil)
+		return err
+	}
+
+	response.SendJSON(c, feed)
+	return nil
+}
+
+/**
+ * @Description: 获取热门文章
+ * @param c
+ * @return error
+ */
+func (ctrl *PostController) GetHotPostList(c *gin.Context) error {
+	var req service.GetHotPostListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		return response.NewValidationError(err)
+	}
+	//查询登录信息 未登录时用户ID为0
+	authUser, _ := auth.GetOptionalAuthUser(c)
+
+	posts, total, err := ctrl.postService.GetHotPostList(c.Request.Context(), &req, authUser.UserID)
+	if err != nil {
+		return err
 	}
 
 	response.SendJSON(c, gin.H{
@@ -155,3 +183,33 @@ func (ctrl *PostController) GetPostList(c *gin.Context) error {
 	})
 	return nil
 }
+
+/**
+ * @Description: 获取文章详情
+ * @param c
+ * @return error
+ */
+func (ctrl *PostController) GetPostDetail(c *gin.Context) error {
+	var req service.GetPostDetailRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		return response.NewValidationError(err)
+	}
+	//查询登录信息 登录用户按用户ID去重 未登录按IP去重
+	authUser, ok := auth.GetOptionalAuthUser(c)
+
+	post, err := ctrl.postService.GetPostDetail(c.Request.Context(), &req, authUser.UserID, visitor(c, authUser, ok))
+	if err != nil {
+		return err
+	}
+
+	response.SendJSON(c, post)
+	return nil
+}
+
+// 浏览量去重的访客标识 登录用户按用户ID 未登录按IP
+func visitor(c *gin.Context, authUser auth.AuthUser, loggedIn bool) string {
+	if loggedIn {
+		return fmt.Sprintf("u:%d", authUser.UserID)
+	}
+	return "ip:" + c.ClientIP()
+}
@@ -0,0 +1,236 @@
+package controller
+
+import (
+	"fmt"
+	"homework4/internal/middleware/auth"
+	"homework4/internal/middleware/response"
+	"homework4/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+/**
+ * @Description: v2文章和评论接口 资源地址中带文章ID 与v1共用service
+ */
+type PostV2Controller struct {
+	postService    *service.PostService
+	commentService *service.CommentService
+}
+
+func NewPostV2Controller(postService *service.PostService, commentService *service.CommentService) *PostV2Controller {
+	return &PostV2Controller{
+		postService:    postService,
+		commentService: commentService,
+	}
+}
+
+/**
+ * @Description: 获取文章分页
+ * @param c
+ * @return error
+ */
+func (ctrl *PostV2Controller) ListPosts(c *gin.Context) error {
+	var req service.GetPostListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		return response.NewValidationError(err)
+	}
+	//查询登录信息 未登录时用户ID为0
+	authUser, _ := auth.GetOptionalAuthUser(c)
+
+	posts, total, err := ctrl.postService.GetPostList(c.Request.Context(), &req, authUser.UserID)
+	if err != nil {
+		return err
+	}
+
+	response.SendJSON(c, gin.H{
+		"list":  posts,
+		"total": total,
+	})
+	return nil
+}
+
+/**
+ * @Description: 创建文章 返回201和新文章的地址
+ * @param c
+ * @return error
+ */
+func (ctrl *PostV2Controller) CreatePost(c *gin.Context) error {
+	var req service.CreatePostRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		return response.NewValidationError(err)
+	}
+	//查询登录信息
+	authUser := auth.GetCurrentAuthUser(c)
+
+	post, err := ctrl.postService.CreatePost(c.Request.Context(), &req, authUser.UserID)
+	if err != nil {
+		return err
+	}
+
+	response.SendCreated(c, postLocation(post.ID), post)
+	return nil
+}
+
+/**
+ * @Description: 获取文章详情 同时记录浏览量
+ * @param c
+ * @return error
+ */
+func (ctrl *PostV2Controller) GetPost(c *gin.Context) error {
+	var uri service.PostIDRequest
+	if err := c.ShouldBindUri(&uri); err != nil {
+		return response.NewValidationError(err)
+	}
+	//查询登录信息 登录用户按用户ID去重 未登录按IP去重
+	authUser, ok := auth.GetOptionalAuthUser(c)
+
+	post, err := ctrl.postService.GetPostDetail(c.Request.Context(), &service.GetPostDetailRequest{PostID: uri.PostID}, authUser.UserID, visitor(c, authUser, ok))
+	if err != nil {
+		return err
+	}
+
+	response.SendJSON(c, post)
+	return nil
+}
+
+/**
+ * @Description: 部分更新文章 返回更新后的文章
+ * @param c
+ * @return error
+ */
+func (ctrl *PostV2Controller) PatchPost(c *gin.Context) error {
+	var uri service.PostIDRequest
+	if err := c.ShouldBindUri(&uri); err != nil {
+		return response.NewValidationError(err)
+	}
+	var req service.PatchPostRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		return response.NewValidationError(err)
+	}
+	//查询登录信息
+	authUser := auth.GetCurrentAuthUser(c)
+
+	ctx := c.Request.Context()
+	if _, err := ctrl.postService.UpdatePost(ctx, &service.UpdatePostRequest{PostID: uri.PostID, Title: req.Title, Content: req.Content}, authUser.UserID); err != nil {
+		return err
+	}
+	post, err := ctrl.postService.GetPost(ctx, uri.PostID, authUser.UserID)
+	if err != nil {
+		return err
+	}
+
+	response.SendJSON(c, post)
+	return nil
+}
+
+/**
+ * @Description: 删除文章 成功返回204
+ * @param c
+ * @return error
+ */
+func (ctrl *PostV2Controller) DeletePost(c *gin.Context) error {
+	var uri service.PostIDRequest
+	if err := c.ShouldBindUri(&uri); err != nil {
+		return response.NewValidationError(err)
+	}
+	//查询登录信息
+	authUser := auth.GetCurrentAuthUser(c)
+
+	if err := ctrl.postService.DeletePost(c.Request.Context(), &service.DeletePostRequest{PostID: uri.PostID}, authUser.UserID); err != nil {
+		return err
+	}
+
+	response.SendNoContent(c)
+	return nil
+}
+
+/**
+ * @Description: 获取文章的评论分页
+ * @param c
+ * @return error
+ */
+func (ctrl *PostV2Controller) ListComments(c *gin.Context) error {
+	var uri service.PostIDRequest
+	if err := c.ShouldBindUri(&uri); err != nil {
+		return response.NewValidationError(err)
+	}
+	var req service.GetPostCommentsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		return response.NewValidationError(err)
+	}
+	//查询登录信息 未登录时用户ID为0
+	authUser, _ := auth.GetOptionalAuthUser(c)
+
+	comments, total, err := ctrl.commentService.GetCommentList(c.Request.Context(), &service.GetCommentListRequest{
+		PostID:   uri.PostID,
+		Page:     req.Page,
+		PageSize: req.PageSize,
+	}, authUser.UserID)
+	if err != nil {
+		return err
+	}
+
+	response.SendJSON(c, gin.H{
+		"list":  comments,
+		"total": total,
+	})
+	return nil
+}
+
+/**
+ * @Description: 在文章下创建评论 返回201和新评论的地址
+ * @param c
+ * @return error
+ */
+func (ctrl *PostV2Controller) CreateComment(c *gin.Context) error {
+	var uri service.PostIDRequest
+	if err := c.ShouldBindUri(&uri); err != nil {
+		return response.NewValidationError(err)
+	}
+	var req service.CreatePostCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		return response.NewValidationError(err)
+	}
+	//查询登录信息
+	authUser := auth.GetCurrentAuthUser(c)
+
+	comment, err := ctrl.commentService.CreateComment(c.Request.Context(), &service.CreateCommentRequest{PostID: uri.PostID, Content: req.Content}, authUser.UserID)
+	if err != nil {
+		return err
+	}
+
+	response.SendCreated(c, commentLocation(comment.ID), comment)
+	return nil
+}
+
+/**
+ * @Description: 获取评论详情
+ * @param c
+ * @return error
+ */
+func (ctrl *PostV2Controller) GetComment(c *gin.Context) error {
+	var uri service.CommentIDRequest
+	if err := c.ShouldBindUri(&uri); err != nil {
+		return response.NewValidationError(err)
+	}
+	//查询登录信息 未登录时用户ID为0
+	authUser, _ := auth.GetOptionalAuthUser(c)
+
+	comment, err := ctrl.commentService.GetComment(c.Request.Context(), uri.CommentID, authUser.UserID)
+	if err != nil {
+		return err
+	}
+
+	response.SendJSON(c, comment)
+	return nil
+}
+
+// 文章的资源地址
+func postLocation(postID uint) string {
+	return fmt.Sprintf("/api/v2/posts/%d", postID)
+}
+
+// 评论的资源地址
+func commentLocation(commentID uint) string {
+	return fmt.Sprintf("/api/v2/comments/%d", commentID)
+}
@@ -0,0 +1,63 @@
+package controller
+
+import (
+	"homework4/internal/middleware/auth"
+	"homework4/internal/middleware/response"
+	"homework4/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ReactionController struct {
+	reactionService *service.ReactionService
+}
+
+func NewReactionController(reactionService *service.ReactionService) *ReactionController {
+	return &ReactionController{
+		reactionService: reactionService,
+	}
+}
+
+/**
+ * @Description: 添加表情回应(点赞)
+ * @param c
+ * @return error
+ */
+func (ctrl *ReactionController) AddReaction(c *gin.Context) error {
+	var req service.ReactionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		return response.NewValidationError(err)
+	}
+	//查询登录信息
+	authUser := auth.GetCurrentAuthUser(c)
+	//添加回应
+	resp, err := ctrl.reactionService.AddReaction(c.Request.Context(), &req, authUser.UserID)
+	if err != nil {
+		return err
+	}
+
+	response.SendJSON(c, resp)
+	return nil
+}
+
+/**
+ * @Description: 取消表情回应(取消点赞)
+ * @param c
+ * @return error
+ */
+func (ctrl *ReactionController) RemoveReaction(c *gin.Context) error {
+	var req service.ReactionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		return response.NewValidationError(err)
+	}
+	//查询登录信息
+	authUser := auth.GetCurrentAuthUser(c)
+	//取消回应
+	resp, err := ctrl.reactionService.RemoveReaction(c.Request.Context(), &req, authUser.UserID)
+	if err != nil {
+		return err
+	}
+
+	response.SendJSON(c, resp)
+	return nil
+}
@@ -1,6 +1,9 @@
 package controller
 
 import (
+	"homework4/internal/errcode"
+	"homework4/internal/feature"
+	"homework4/internal/middleware/auth"
 	"homework4/internal/middleware/response"
 	"homework4/internal/service"
 
@@ -11,9 +14,9 @@ type UserController struct {
 	userService *service.UserService
 }
 
-func NewUserController() *UserController {
+func NewUserController(userService *service.UserService) *UserController {
 	return &UserController{
-		userService: service.NewUserService(),
+		userService: userService,
 	}
 }
 
@@ -22,25 +25,18 @@ func NewUserController() *UserController {
  * @param c
  * @return error
  */
-// Register godoc
-// @Summary 用户注册
-// @Description 创建新用户账号
-// @Tags 用户管理
-// @Accept json
-// @Produce json
-// @Param request body service.RegisterRequest true "注册信息"
-// @Success 200 {object} response.Response{data=map[string]interface{}} "注册成功"
-// @Failure 400 {object} response.Response "参数错误"
-// @Router /user/register [post]
 func (ctrl *UserController) Register(c *gin.Context) error {
+	if !feature.Enabled(feature.Registration) {
+		return errcode.ErrRegistrationDisabled
+	}
 	var req service.RegisterRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		return response.NewBadRequestError("参数错误: " + err.Error())
+		return response.NewValidationError(err)
 	}
 
-	user, err := ctrl.userService.Register(&req)
+	user, err := ctrl.userService.Register(c.Request.Context(), &req)
 	if err != nil {
-		return response.NewBizError(response.CodeBadRequest, err.Error(), nil)
+		return err
 	}
 
 	response.SendJSON(c, gin.H{
@@ -57,26 +53,37 @@ func (ctrl *UserController) Register(c *gin.Context) error {
  * @param c
  * @return error
  */
-// Login godoc
-// @Summary 用户登录
-// @Description 用户登录获取JWT token
-// @Tags 用户管理
-// @Accept json
-// @Produce json
-// @Param request body service.LoginRequest true "登录信息"
-// @Success 200 {object} response.Response{data=service.LoginResponse} "登录成功"
-// @Failure 400 {object} response.Response "参数错误或登录失败"
-// @Router /user/login [post]
 func (ctrl *UserController) Login(c *gin.Context) error {
 	var req service.LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		return response.NewBadRequestError("参数错误: " + err.Error())
+		return response.NewValidationError(err)
 	}
 
-	resp, err := ctrl.userService.Login(&req)
+	resp, err := ctrl.userService.Login(c.Request.Context(), &req)
 	if err != nil {
-		return response.NewBizError(response.CodeBadRequest, err.Error(), nil)
+		return err
 	}
 	response.SendJSON(c, resp)
 	return nil
 }
+
+/**
+ * @Description: 获取用户公开资料
+ * @param c
+ * @return error
+ */
+func (ctrl *UserController) GetProfile(c *gin.Context) error {
+	var req service.GetProfileRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		return response.NewValidationError(err)
+	}
+	//查询登录信息 未登录时用户ID为0
+	authUser, _ := auth.GetOptionalAuthUser(c)
+
+	profile, err := ctrl.userService.GetProfile(c.Request.Context(), &req, authUser.UserID)
+	if err != nil {
+		return err
+	}
+	response.SendJSON(c, profile)
+	return nil
+}
@@ -0,0 +1,68 @@
+package errcode
+
+/**
+ * @Description: 错误码目录 新增错误在这里定义 错误码使用大写下划线命名 发布后不再修改
+ */
+
+var catalog = map[string]*Error{}
+
+// 通用
+var (
+	ErrInternal        = define(KindInternal, "INTERNAL_ERROR", "服务器内部错误", "Internal server error")
+	ErrInvalidParams   = define(KindValidation, "INVALID_PARAMS", "参数错误", "Invalid parameters")
+	ErrInvalidCursor   = define(KindValidation, "INVALID_CURSOR", "游标无效", "Invalid cursor")
+	ErrTooManyRequests = define(KindTooManyRequests, "TOO_MANY_REQUESTS", "请求过于频繁，请稍后再试", "Too many requests, please try again later")
+)
+
+// 登录认证
+var (
+	ErrTokenMissing         = define(KindUnauthorized, "TOKEN_MISSING", "未提供认证令牌", "Authentication token is missing")
+	ErrTokenInvalid         = define(KindUnauthorized, "TOKEN_INVALID", "认证令牌无效", "Authentication token is invalid")
+	ErrTokenExpired         = define(KindUnauthorized, "TOKEN_EXPIRED", "认证令牌过期", "Authentication token has expired")
+	ErrTokenMismatch        = define(KindUnauthorized, "TOKEN_MISMATCH", "认证令牌不匹配", "Authentication token does not match")
+	ErrLoginFailed          = define(KindUnauthorized, "LOGIN_FAILED", "用户名或密码错误", "Incorrect username or password")
+	ErrRegistrationDisabled = define(KindForbidden, "REGISTRATION_DISABLED", "暂未开放注册", "Registration is currently closed")
+)
+
+// 用户和关注
+var (
+	ErrUserNotFound     = define(KindNotFound, "USER_NOT_FOUND", "用户不存在", "User not found")
+	ErrUsernameExists   = define(KindConflict, "USERNAME_EXISTS", "用户名已存在", "Username already exists")
+	ErrCannotFollowSelf = define(KindValidation, "CANNOT_FOLLOW_SELF", "不能关注自己", "You cannot follow yourself")
+)
+
+// 文章和评论
+var (
+	ErrPostNotFound           = define(KindNotFound, "POST_NOT_FOUND", "文章不存在", "Post not found")
+	ErrPostUpdateForbidden    = define(KindForbidden, "POST_UPDATE_FORBIDDEN", "无权修改此文章", "You are not allowed to update this post")
+	ErrPostDeleteForbidden    = define(KindForbidden, "POST_DELETE_FORBIDDEN", "无权删除此文章", "You are not allowed to delete this post")
+	ErrCommentNotFound        = define(KindNotFound, "COMMENT_NOT_FOUND", "评论不存在", "Comment not found")
+	ErrCommentDeleteForbidden = define(KindForbidden, "COMMENT_DELETE_FORBIDDEN", "无权删除此评论", "You are not allowed to delete this comment")
+)
+
+// 收藏和阅读清单
+var (
+	ErrBookmarkMismatch      = define(KindValidation, "BOOKMARK_MISMATCH", "存在未收藏或重复的文章", "Some posts are not bookmarked or are duplicated")
+	ErrReadingListNotFound   = define(KindNotFound, "READING_LIST_NOT_FOUND", "阅读清单不存在", "Reading list not found")
+	ErrReadingListNameExists = define(KindConflict, "READING_LIST_NAME_EXISTS", "阅读清单名称已存在", "Reading list name already exists")
+	ErrReadingListForbidden  = define(KindForbidden, "READING_LIST_FORBIDDEN", "无权操作此阅读清单", "You are not allowed to modify this reading list")
+)
+
+// 幂等
+var (
+	ErrIdempotencyKeyInvalid = define(KindValidation, "IDEMPOTENCY_KEY_INVALID", "Idempotency-Key格式错误", "Invalid Idempotency-Key")
+	ErrIdempotencyKeyReused  = define(KindUnprocessable, "IDEMPOTENCY_KEY_REUSED", "Idempotency-Key已用于不同的请求内容", "Idempotency-Key has been used with a different request body")
+	ErrIdempotencyInProgress = define(KindConflict, "IDEMPOTENCY_IN_PROGRESS", "相同Idempotency-Key的请求正在处理，请稍后重试", "A request with the same Idempotency-Key is in progress, please retry later")
+	ErrRequestBodyUnreadable = define(KindValidation, "REQUEST_BODY_UNREADABLE", "读取请求体失败", "Failed to read request body")
+)
+
+// GraphQL
+var (
+	ErrQueryTooDeep    = define(KindValidation, "QUERY_TOO_DEEP", "查询嵌套层数超过限制", "Query exceeds the maximum depth")
+	ErrQueryTooComplex = define(KindValidation, "QUERY_TOO_COMPLEX", "查询复杂度超过限制", "Query exceeds the maximum complexity")
+)
+
+// 接口文档
+var (
+	ErrResponseMismatch = define(KindInternal, "RESPONSE_SPEC_MISMATCH", "响应与接口文档不一致", "Response does not match the API specification")
+)
@@ -0,0 +1,100 @@
+package errcode
+
+/**
+ * @Description: 业务错误码 service返回带类型和稳定错误码的错误 由response.SendErrorJSON统一转换为HTTP状态码和本地化提示
+ * 没有错误码的错误都按服务器内部错误处理
+ */
+import (
+	"errors"
+	"net/http"
+)
+
+// Kind 错误类型 决定HTTP状态码
+type Kind int
+
+const (
+	KindInternal        Kind = iota // 服务器内部错误
+	KindValidation                  // 参数不合法
+	KindUnauthorized                // 未登录或登录失效
+	KindForbidden                   // 没有权限
+	KindNotFound                    // 资源不存在
+	KindConflict                    // 资源冲突 如名称重复
+	KindUnprocessable               // 请求无法处理 如幂等键已用于不同的请求内容
+	KindTooManyRequests             // 请求过于频繁
+)
+
+var kindStatus = map[Kind]int{
+	KindInternal:        http.StatusInternalServerError,
+	KindValidation:      http.StatusBadRequest,
+	KindUnauthorized:    http.StatusUnauthorized,
+	KindForbidden:       http.StatusForbidden,
+	KindNotFound:        http.StatusNotFound,
+	KindConflict:        http.StatusConflict,
+	KindUnprocessable:   http.StatusUnprocessableEntity,
+	KindTooManyRequests: http.StatusTooManyRequests,
+}
+
+// Status 错误类型对应的HTTP状态码
+func (k Kind) Status() int {
+	if status, ok := kindStatus[k]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
+// Error 带错误码的业务错误 错误码发布后不再修改 客户端按错误码判断错误
+type Error struct {
+	Kind     Kind
+	Code     string          // 稳定的错误码 如POST_NOT_FOUND
+	messages map[Lang]string // 各语言的提示
+}
+
+// 定义错误码 同一个错误码只能定义一次
+func define(kind Kind, code string, zh string, en string) *Error {
+	if _, ok := catalog[code]; ok {
+		panic("errcode: 重复的错误码 " + code)
+	}
+	e := &Error{Kind: kind, Code: code, messages: map[Lang]string{LangZH: zh, LangEN: en}}
+	catalog[code] = e
+	return e
+}
+
+// Error 返回中文提示 用于日志和管理命令输出
+func (e *Error) Error() string {
+	return e.messages[LangZH]
+}
+
+/**
+ * @Description: 获取指定语言的提示 没有该语言时返回中文
+ * @param lang
+ * @return string
+ */
+func (e *Error) Message(lang Lang) string {
+	if message, ok := e.messages[lang]; ok {
+		return message
+	}
+	return e.messages[LangZH]
+}
+
+/**
+ * @Description: 从错误链中取出带错误码的错误 没有时返回服务器内部错误
+ * @param err
+ * @return (*Error, bool) 是否带错误码
+ */
+func From(err error) (*Error, bool) {
+	var e *Error
+	if errors.As(err, &e) {
+		return e, true
+	}
+	return ErrInternal, false
+}
+
+/**
+ * @Description: 根据错误码查询错误定义
+ * @param code
+ * @return (*Error, bool)
+ */
+func Lookup(code string) (*Error, bool) {
+	e, ok := catalog[code]
+	return e, ok
+}
@@ -0,0 +1,42 @@
+package errcode
+
+/**
+ * @Description: 错误提示的语言 按Accept-Language请求头选择 默认中文
+ */
+import (
+	"golang.org/x/text/language"
+)
+
+// Lang 提示语言
+type Lang string
+
+const (
+	LangZH Lang = "zh-CN" // 中文 默认语言
+	LangEN Lang = "en"    // 英文
+)
+
+// 按顺序和supported中的标签对应 第一个为默认语言
+var (
+	supported = []Lang{LangZH, LangEN}
+	matcher   = language.NewMatcher([]language.Tag{language.SimplifiedChinese, language.English})
+)
+
+/**
+ * @Description: 根据Accept-Language请求头选择提示语言 没有匹配的语言时使用中文
+ * @param acceptLanguage
+ * @return Lang
+ */
+func ParseAcceptLanguage(acceptLanguage string) Lang {
+	if acceptLanguage == "" {
+		return LangZH
+	}
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(tags) == 0 {
+		return LangZH
+	}
+	_, index, confidence := matcher.Match(tags...)
+	if confidence == language.No {
+		return LangZH
+	}
+	return supported[index]
+}
@@ -0,0 +1,40 @@
+package feature
+
+/**
+ * @Description: 功能开关 由配置中的features设置 配置重新加载后立即生效
+ */
+import (
+	"strings"
+	"sync/atomic"
+)
+
+const (
+	Registration = "registration" // 开放用户注册
+)
+
+var flags atomic.Pointer[map[string]bool]
+
+/**
+ * @Description: 替换全部功能开关
+ * @param features key为功能名 不区分大小写
+ */
+func Set(features map[string]bool) {
+	m := make(map[string]bool, len(features))
+	for name, enabled := range features {
+		m[strings.ToLower(name)] = enabled
+	}
+	flags.Store(&m)
+}
+
+/**
+ * @Description: 功能是否开启 未配置的功能视为关闭
+ * @param name
+ * @return bool
+ */
+func Enabled(name string) bool {
+	m := flags.Load()
+	if m == nil {
+		return false
+	}
+	return (*m)[strings.ToLower(name)]
+}
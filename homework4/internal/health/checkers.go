@@ -0,0 +1,62 @@
+package health
+
+/**
+ * @Description: 常用检查项 数据库、redis、迁移版本和后台任务心跳
+ */
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// DBCheck ping数据库
+func DBCheck(db *gorm.DB) CheckFunc {
+	return func(ctx context.Context) error {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return err
+		}
+		return sqlDB.PingContext(ctx)
+	}
+}
+
+// RedisCheck ping redis
+func RedisCheck(rdb *goredis.Client) CheckFunc {
+	return func(ctx context.Context) error {
+		return rdb.Ping(ctx).Err()
+	}
+}
+
+// SchemaChecker 检查数据库结构版本 由migrate.Migrator实现
+type SchemaChecker interface {
+	Check(ctx context.Context) error
+}
+
+// MigrationCheck 检查是否有未执行或被修改的迁移
+func MigrationCheck(schema SchemaChecker) CheckFunc {
+	return schema.Check
+}
+
+// Heartbeater 后台任务心跳 由job.Worker实现
+type Heartbeater interface {
+	LastHeartbeat() time.Time
+	HeartbeatTimeout() time.Duration
+}
+
+// HeartbeatCheck 检查后台任务是否在超时时间内有心跳
+func HeartbeatCheck(worker Heartbeater) CheckFunc {
+	return func(ctx context.Context) error {
+		last := worker.LastHeartbeat()
+		if last.IsZero() {
+			return errors.New("未启动")
+		}
+		if elapsed := time.Since(last); elapsed > worker.HeartbeatTimeout() {
+			return fmt.Errorf("心跳超时 距上次心跳%s", elapsed.Truncate(time.Second))
+		}
+		return nil
+	}
+}
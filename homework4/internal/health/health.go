@@ -0,0 +1,136 @@
+package health
+
+/**
+ * @Description: 健康检查 注册依赖检查项 就绪检查时并发执行并返回各组件的状态和耗时
+ */
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	StatusUp   = "up"   // 正常
+	StatusDown = "down" // 异常
+)
+
+// CheckFunc 检查函数 返回错误表示组件异常
+type CheckFunc func(ctx context.Context) error
+
+// 检查项
+type checker struct {
+	name    string
+	timeout time.Duration
+	check   CheckFunc
+}
+
+// ComponentStatus 组件检查结果
+type ComponentStatus struct {
+	Name      string `json:"name" example:"database"`    // 组件名称
+	Status    string `json:"status" example:"up"`        // 状态 up/down
+	LatencyMs int64  `json:"latencyMs" example:"3"`      // 检查耗时 单位毫秒
+	Error     string `json:"error,omitempty" example:""` // 异常原因
+}
+
+// Report 就绪检查结果
+type Report struct {
+	Status     string            `json:"status" example:"up"` // 整体状态 任一组件异常或正在关闭时为down
+	Shutdown   bool              `json:"shutdown,omitempty"`  // 是否正在关闭
+	Components []ComponentStatus `json:"components"`          // 各组件状态 按注册顺序
+}
+
+type Registry struct {
+	mu             sync.RWMutex
+	checkers       []checker
+	defaultTimeout time.Duration
+	shuttingDown   atomic.Bool
+}
+
+/**
+ * @Description: 创建检查注册表
+ * @param defaultTimeout 注册时未指定超时时使用
+ * @return *Registry
+ */
+func NewRegistry(defaultTimeout time.Duration) *Registry {
+	return &Registry{defaultTimeout: defaultTimeout}
+}
+
+/**
+ * @Description: 注册检查项
+ * @param name 组件名称
+ * @param timeout 单次检查超时 0时使用默认超时
+ * @param check
+ */
+func (r *Registry) Register(name string, timeout time.Duration, check CheckFunc) {
+	if timeout <= 0 {
+		timeout = r.defaultTimeout
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checkers = append(r.checkers, checker{name: name, timeout: timeout, check: check})
+}
+
+// SetShuttingDown 标记正在关闭 之后就绪检查始终失败 让负载均衡摘除流量
+func (r *Registry) SetShuttingDown() {
+	r.shuttingDown.Store(true)
+}
+
+/**
+ * @Description: 执行所有检查项 每项有独立超时 并发执行
+ * @param ctx
+ * @return Report
+ */
+func (r *Registry) Ready(ctx context.Context) Report {
+	r.mu.RLock()
+	checkers := append([]checker(nil), r.checkers...)
+	r.mu.RUnlock()
+
+	report := Report{Status: StatusUp, Components: make([]ComponentStatus, len(checkers))}
+	var wg sync.WaitGroup
+	for i, c := range checkers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			report.Components[i] = run(ctx, c)
+		}()
+	}
+	wg.Wait()
+
+	for _, component := range report.Components {
+		if component.Status != StatusUp {
+			report.Status = StatusDown
+		}
+	}
+	if r.shuttingDown.Load() {
+		report.Status = StatusDown
+		report.Shutdown = true
+	}
+	return report
+}
+
+// 执行单个检查 超时后不等待检查函数返回
+func run(ctx context.Context, c checker) ComponentStatus {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	result := make(chan error, 1)
+	go func() {
+		result <- c.check(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-result:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	status := ComponentStatus{Name: c.name, Status: StatusUp, LatencyMs: time.Since(start).Milliseconds()}
+	if err != nil {
+		status.Status = StatusDown
+		status.Error = err.Error()
+	}
+	return status
+}
@@ -0,0 +1,38 @@
+package job
+
+/**
+ * @Description: 首页动态写扩散任务
+ */
+import (
+	"context"
+	"homework4/config"
+	"homework4/internal/service"
+	"homework4/pkg/logger"
+	"time"
+)
+
+// 每次最多执行的写扩散批次数 剩余的任务下次执行 避免一次执行过久心跳超时
+const feedFanOutBatchesPerRun = 50
+
+/**
+ * @Description: 写扩散任务 定时执行队列中写入和移除粉丝时间线的任务 未执行完的任务保留在redis中 重启后继续执行
+ * @return *Worker
+ */
+func NewFeedWorker(feedService *service.FeedService, cfg config.FeedConfig) *Worker {
+	return newWorker("feed-job").
+		every(time.Duration(cfg.FanOutInterval)*time.Second, func(ctx context.Context) {
+			processFeedFanOut(ctx, feedService)
+		})
+}
+
+// 执行写扩散任务
+func processFeedFanOut(ctx context.Context, feedService *service.FeedService) {
+	processed, err := feedService.ProcessFanOut(ctx, feedFanOutBatchesPerRun)
+	if err != nil {
+		logger.AppLog.Error("读取写扩散任务失败", logger.WrapMeta(err)...)
+		return
+	}
+	if processed > 0 {
+		logger.AppLog.Debug("写扩散任务执行完成", logger.WrapMeta(nil, logger.NewMeta("batches", processed))...)
+	}
+}
@@ -0,0 +1,55 @@
+package job
+
+/**
+ * @Description: 文章浏览量和热门排行定时任务
+ */
+import (
+	"context"
+	"homework4/config"
+	"homework4/internal/service"
+	"homework4/pkg/logger"
+	"time"
+)
+
+/**
+ * @Description: 文章任务 定时将redis浏览量刷入数据库 并定时刷新热门排行 停止时刷入剩余浏览量
+ * @return *Worker
+ */
+func NewPostWorker(viewService *service.ViewService, hotService *service.HotService, viewCfg config.ViewConfig, hotCfg config.HotConfig) *Worker {
+	w := newWorker("post-job").
+		every(time.Duration(viewCfg.FlushInterval)*time.Second, func(ctx context.Context) {
+			flushPostViews(ctx, viewService)
+		}).
+		every(time.Duration(hotCfg.RefreshInterval)*time.Second, func(ctx context.Context) {
+			refreshHotRanking(ctx, hotService)
+		})
+	w.onStart = func(ctx context.Context) {
+		refreshHotRanking(ctx, hotService)
+	}
+	w.onStop = func(ctx context.Context) {
+		flushPostViews(ctx, viewService)
+	}
+	return w
+}
+
+// 刷入浏览量
+func flushPostViews(ctx context.Context, viewService *service.ViewService) {
+	flushed, err := viewService.FlushViews(ctx)
+	if err != nil {
+		logger.AppLog.Error("浏览量刷入数据库失败", logger.WrapMeta(err)...)
+		return
+	}
+	if flushed > 0 {
+		logger.AppLog.Info("浏览量刷入数据库成功", logger.WrapMeta(nil, logger.NewMeta("flushed", flushed))...)
+	}
+}
+
+// 刷新热门排行
+func refreshHotRanking(ctx context.Context, hotService *service.HotService) {
+	ranked, err := hotService.RefreshRanking(ctx)
+	if err != nil {
+		logger.AppLog.Error("热门排行刷新失败", logger.WrapMeta(err)...)
+		return
+	}
+	logger.AppLog.Info("热门排行刷新成功", logger.WrapMeta(nil, logger.NewMeta("ranked", ranked))...)
+}
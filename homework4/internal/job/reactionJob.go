@@ -0,0 +1,60 @@
+package job
+
+/**
+ * @Description: 点赞/表情回应计数定时任务
+ */
+import (
+	"context"
+	"homework4/config"
+	"homework4/internal/service"
+	"homework4/pkg/logger"
+	"time"
+)
+
+/**
+ * @Description: 回应计数任务 定时将redis计数刷入数据库 并定时根据回应表重建计数 停止时刷入剩余计数
+ * @return *Worker
+ */
+func NewReactionWorker(reactionService *service.ReactionService, cfg config.ReactionConfig) *Worker {
+	w := newWorker("reaction-job").
+		every(time.Duration(cfg.FlushInterval)*time.Second, func(ctx context.Context) {
+			flushReactionCounters(ctx, reactionService)
+		}).
+		every(time.Duration(cfg.ReconcileInterval)*time.Second, func(ctx context.Context) {
+			reconcileReactionCounters(ctx, reactionService)
+		})
+	//启动时先重建一次 防止redis数据丢失后计数不准
+	w.onStart = func(ctx context.Context) {
+		reconcileReactionCounters(ctx, reactionService)
+	}
+	w.onStop = func(ctx context.Context) {
+		flushReactionCounters(ctx, reactionService)
+	}
+	return w
+}
+
+// 刷入计数
+func flushReactionCounters(ctx context.Context, reactionService *service.ReactionService) {
+	flushed, err := reactionService.FlushCounters(ctx)
+	if err != nil {
+		logger.AppLog.Error("回应计数刷入数据库失败", logger.WrapMeta(err)...)
+		return
+	}
+	if flushed > 0 {
+		logger.AppLog.Info("回应计数刷入数据库成功", logger.WrapMeta(nil, logger.NewMeta("flushed", flushed))...)
+	}
+}
+
+// 重建计数
+func reconcileReactionCounters(ctx context.Context, reactionService *service.ReactionService) {
+	reconciled, err := reactionService.ReconcileCounters(ctx)
+	if err != nil {
+		logger.AppLog.Error("回应计数重建失败", logger.WrapMeta(err)...)
+		return
+	}
+	if !reconciled {
+		logger.AppLog.Info("其它实例正在重建回应计数 跳过")
+		return
+	}
+	logger.AppLog.Info("回应计数重建成功")
+}
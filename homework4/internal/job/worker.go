@@ -0,0 +1,145 @@
+package job
+
+/**
+ * @Description: 后台任务 按间隔执行定时任务 通过Start/Stop接入应用生命周期
+ */
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// 定时任务
+type task struct {
+	interval time.Duration
+	run      func(ctx context.Context)
+}
+
+// Worker 后台任务 同一个Worker的任务串行执行
+type Worker struct {
+	name    string
+	onStart func(ctx context.Context) // 启动后先执行一次
+	onStop  func(ctx context.Context) // 停止时最后执行一次 如把redis中未刷入的数据写入数据库
+	tasks   []task
+
+	mu        sync.Mutex // 串行执行任务
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+	heartbeat atomic.Int64 // 最近一次心跳的unix纳秒 启动和每次执行完任务时更新
+}
+
+func newWorker(name string) *Worker {
+	return &Worker{name: name}
+}
+
+// 添加定时任务
+func (w *Worker) every(interval time.Duration, run func(ctx context.Context)) *Worker {
+	w.tasks = append(w.tasks, task{interval: interval, run: run})
+	return w
+}
+
+// Name 任务名称
+func (w *Worker) Name() string {
+	return w.name
+}
+
+/**
+ * @Description: 启动任务 任务在后台执行 不阻塞
+ * @param ctx 未使用 任务的生命周期由Stop控制
+ * @return error
+ */
+func (w *Worker) Start(_ context.Context) error {
+	runCtx, cancel := context.WithCancel(context.Background())
+	w.cancel = cancel
+	w.beat()
+
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		if w.onStart != nil {
+			w.exec(runCtx, w.onStart)
+			w.beat()
+		}
+		for _, t := range w.tasks {
+			w.wg.Add(1)
+			go w.loop(runCtx, t)
+		}
+	}()
+	return nil
+}
+
+/**
+ * @Description: 停止任务 等待正在执行的任务结束后执行停止钩子
+ * @param ctx 超时后不再等待
+ * @return error
+ */
+func (w *Worker) Stop(ctx context.Context) error {
+	if w.cancel == nil {
+		return nil
+	}
+	w.cancel()
+	w.heartbeat.Store(0)
+
+	done := make(chan struct{})
+	go func() {
+		w.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	if w.onStop != nil {
+		w.exec(ctx, w.onStop)
+	}
+	return nil
+}
+
+func (w *Worker) loop(ctx context.Context, t task) {
+	defer w.wg.Done()
+	ticker := time.NewTicker(t.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.exec(ctx, t.run)
+			w.beat()
+		}
+	}
+}
+
+// 执行任务 停止时不取消正在执行的任务 避免刷入数据库时中断导致计数丢失
+func (w *Worker) exec(ctx context.Context, run func(ctx context.Context)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	run(context.WithoutCancel(ctx))
+}
+
+func (w *Worker) beat() {
+	w.heartbeat.Store(time.Now().UnixNano())
+}
+
+// LastHeartbeat 最近一次心跳时间 未启动或已停止时为零值
+func (w *Worker) LastHeartbeat() time.Time {
+	nanos := w.heartbeat.Load()
+	if nanos == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, nanos)
+}
+
+// HeartbeatTimeout 心跳超时时间 为最短任务间隔的3倍 任务卡住或执行过慢时就绪检查失败
+func (w *Worker) HeartbeatTimeout() time.Duration {
+	var shortest time.Duration
+	for _, t := range w.tasks {
+		if shortest == 0 || t.interval < shortest {
+			shortest = t.interval
+		}
+	}
+	return 3 * shortest
+}
@@ -0,0 +1,46 @@
+package metrics
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 没有匹配到路由的请求 防止按原始路径产生大量标签
+const unmatchedRoute = "unmatched"
+
+// Middleware 统计HTTP请求数和耗时 按路由模板、方法和状态码分组
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		status := strconv.Itoa(c.Writer.Status())
+		httpRequests.WithLabelValues(c.Request.Method, route, status).Inc()
+		httpDuration.WithLabelValues(c.Request.Method, route, status).Observe(time.Since(start).Seconds())
+	}
+}
+
+/**
+ * @Description: 需要Bearer令牌的指标处理器 用于在业务端口暴露指标
+ * @param token
+ * @return gin.HandlerFunc
+ */
+func ProtectedHandler(token string) gin.HandlerFunc {
+	handler := Handler()
+	expected := []byte("Bearer " + token)
+	return func(c *gin.Context) {
+		if subtle.ConstantTimeCompare([]byte(c.GetHeader("Authorization")), expected) != 1 {
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+		handler.ServeHTTP(c.Writer, c.Request)
+	}
+}
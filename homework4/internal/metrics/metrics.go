@@ -0,0 +1,135 @@
+package metrics
+
+/**
+ * @Description: Prometheus指标 使用独立的注册表 通过管理端口或带令牌的/metrics暴露
+ */
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "homework4"
+
+// Registry 应用的指标注册表
+var Registry = prometheus.NewRegistry()
+
+var (
+	// HTTP请求
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace, Subsystem: "http", Name: "requests_total",
+		Help: "HTTP请求数 route为路由模板",
+	}, []string{"method", "route", "status"})
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace, Subsystem: "http", Name: "request_duration_seconds",
+		Help:    "HTTP请求耗时",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	// gRPC请求
+	grpcRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace, Subsystem: "grpc", Name: "requests_total",
+		Help: "gRPC请求数 method为完整方法名 code为gRPC状态码",
+	}, []string{"method", "code"})
+	grpcDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace, Subsystem: "grpc", Name: "request_duration_seconds",
+		Help:    "gRPC请求耗时 流请求为整个流的持续时间",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "code"})
+
+	// 数据库
+	dbDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace, Subsystem: "db", Name: "query_duration_seconds",
+		Help:    "数据库操作耗时 operation为create/query/update/delete/row/raw",
+		Buckets: []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"operation", "table"})
+	dbErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace, Subsystem: "db", Name: "query_errors_total",
+		Help: "数据库操作错误数 不包含记录不存在",
+	}, []string{"operation", "table"})
+
+	// redis
+	redisDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace, Subsystem: "redis", Name: "command_duration_seconds",
+		Help:    "redis命令耗时 pipeline的command为pipeline",
+		Buckets: []float64{.0001, .00025, .0005, .001, .0025, .005, .01, .025, .05, .1, .25},
+	}, []string{"command"})
+	redisErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace, Subsystem: "redis", Name: "command_errors_total",
+		Help: "redis命令错误数 不包含key不存在",
+	}, []string{"command"})
+
+	// 业务
+	Registrations = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace, Subsystem: "user", Name: "registrations_total",
+		Help: "用户注册数",
+	})
+	Logins = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace, Subsystem: "user", Name: "logins_total",
+		Help: "登录次数 result为success或failure",
+	}, []string{"result"})
+	PostsCreated = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace, Subsystem: "post", Name: "created_total",
+		Help: "创建的文章数",
+	})
+	CommentsCreated = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace, Subsystem: "comment", Name: "created_total",
+		Help: "创建的评论数",
+	})
+
+	// 缓存
+	CacheRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace, Subsystem: "cache", Name: "requests_total",
+		Help: "缓存查询次数 tier为local或redis result为hit或miss",
+	}, []string{"entity", "tier", "result"})
+)
+
+const (
+	LoginSuccess = "success" // 登录成功
+	LoginFailure = "failure" // 登录失败
+
+	CacheTierLocal = "local" // 本机LRU
+	CacheTierRedis = "redis" // redis
+	CacheHit       = "hit"   // 命中
+	CacheMiss      = "miss"  // 未命中
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests, httpDuration,
+		grpcRequests, grpcDuration,
+		dbDuration, dbErrors,
+		redisDuration, redisErrors,
+		Registrations, Logins, PostsCreated, CommentsCreated,
+		CacheRequests,
+	)
+	//没有登录时也输出0
+	Logins.WithLabelValues(LoginSuccess)
+	Logins.WithLabelValues(LoginFailure)
+}
+
+/**
+ * @Description: 注册数据库连接池指标 同一个连接池重复注册时忽略
+ * @param db
+ * @param name 连接池名称
+ * @return error
+ */
+func RegisterDBStats(db *sql.DB, name string) error {
+	err := Registry.Register(collectors.NewDBStatsCollector(db, name))
+	var registered prometheus.AlreadyRegisteredError
+	if errors.As(err, &registered) {
+		return nil
+	}
+	return err
+}
+
+// Handler 输出指标的http处理器
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
@@ -2,16 +2,17 @@ package auth
 
 import (
 	"context"
-	"fmt"
+	"errors"
 	"homework4/config"
-	"homework4/internal/app/redis"
+	"homework4/internal/errcode"
+	"homework4/internal/repository"
 	"homework4/internal/utils/jwt"
-
-	"homework4/internal/middleware/response"
+	"homework4/pkg/logger"
 	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
 )
 
 const (
@@ -24,57 +25,111 @@ type AuthUser struct {
 	Nickname string `json:"nickname"`
 }
 
-func AuthMiddleware() gin.HandlerFunc {
+/**
+ * @description: 登录认证 签发和校验JWT token 每个用户只保留最新的token
+ */
+type Authenticator struct {
+	tokens repository.TokenStore
+	cfg    config.JWTConfig
+}
+
+func NewAuthenticator(tokens repository.TokenStore, cfg config.JWTConfig) *Authenticator {
+	return &Authenticator{tokens: tokens, cfg: cfg}
+}
+
+func (a *Authenticator) AuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.Error(response.NewUnauthorizedError("未提供认证令牌"))
+			c.Error(errcode.ErrTokenMissing)
 			c.Abort()
 			return
 		}
 
-		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
-
-		claims, err := jwt.ParseToken(tokenString, config.Cfg.JWT.Secret)
+		user, err := a.Verify(c.Request.Context(), strings.TrimPrefix(authHeader, "Bearer "))
 		if err != nil {
-			c.Error(response.NewUnauthorizedError("认证令牌无效"))
+			c.Error(err)
 			c.Abort()
 			return
 		}
+		setAuthUser(c, user)
 
-		storedToken, err := RedisGetToken(claims.UserID)
-		if err != nil {
-			c.Error(response.NewUnauthorizedError("认证令牌过期"))
-			c.Abort()
-			return
-		}
+		c.Next()
+	}
+}
 
-		if storedToken != tokenString {
-			c.Error(response.NewUnauthorizedError("认证令牌不匹配"))
-			c.Abort()
+/**
+ * @description: 可选登录中间件 携带有效token时设置登录信息 否则按未登录处理
+ */
+func (a *Authenticator) OptionalAuthMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tokenString := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if tokenString == "" {
+			c.Next()
 			return
 		}
 
-		c.Set(AuthUserKey, AuthUser{
-			UserID:   claims.UserID,
-			Username: claims.Username,
-			Nickname: claims.Nickname,
-		})
+		if user, err := a.Verify(c.Request.Context(), tokenString); err == nil {
+			setAuthUser(c, user)
+		}
 
 		c.Next()
 	}
 }
 
 /**
- * @description: redis存储用户token到Redis
- * @param {string} token token字符串
- * @param {uint} userID 用户ID
- * @return {error} 错误信息
+ * @description: 校验token 必须是该用户最新签发的token
+ * @param {context.Context} ctx
+ * @param {string} tokenString 不带Bearer前缀
+ * @return {AuthUser, error} token不合法时返回对应的错误码 令牌存储不可用时返回原始错误
  */
-func RedisStoreToken(token string, userID uint) error {
-	ctx := context.Background()
-	key := fmt.Sprintf("token:%d", userID)
-	return redis.RedisClient.Set(ctx, key, token, time.Duration(config.Cfg.JWT.Expires)*time.Hour).Err()
+func (a *Authenticator) Verify(ctx context.Context, tokenString string) (AuthUser, error) {
+	claims, err := jwt.ParseToken(tokenString, a.cfg.Secret)
+	if err != nil {
+		return AuthUser{}, errcode.ErrTokenInvalid
+	}
+
+	storedToken, err := a.tokens.Get(ctx, claims.UserID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return AuthUser{}, errcode.ErrTokenExpired
+	}
+	//令牌存储不可用时返回500 不让客户端误以为需要重新登录
+	if err != nil {
+		return AuthUser{}, err
+	}
+
+	if storedToken != tokenString {
+		return AuthUser{}, errcode.ErrTokenMismatch
+	}
+
+	return AuthUser{
+		UserID:   claims.UserID,
+		Username: claims.Username,
+		Nickname: claims.Nickname,
+	}, nil
+}
+
+// 保存登录信息 请求上下文的日志记录器追加user_id
+func setAuthUser(c *gin.Context, user AuthUser) {
+	c.Set(AuthUserKey, user)
+	c.Request = c.Request.WithContext(logger.With(c.Request.Context(), zap.Uint("user_id", user.UserID)))
+}
+
+type contextKey struct{}
+
+/**
+ * @description: 将登录信息放入上下文 用于gin以外的请求 如gRPC
+ */
+func NewContext(ctx context.Context, user AuthUser) context.Context {
+	return context.WithValue(ctx, contextKey{}, user)
+}
+
+/**
+ * @description: 获取上下文中的登录信息 未登录时返回false
+ */
+func FromContext(ctx context.Context) (AuthUser, bool) {
+	user, ok := ctx.Value(contextKey{}).(AuthUser)
+	return user, ok
 }
 
 /**
@@ -85,35 +140,40 @@ func GetCurrentAuthUser(c *gin.Context) AuthUser {
 }
 
 /**
- * @description: 从Redis获取用户token
- * @param {uint} userID 用户ID
- * @return {string} token
+ * @description: 获取可选的登录用户信息 未登录时返回false
  */
-func RedisGetToken(userID uint) (string, error) {
-	ctx := context.Background()
-	key := fmt.Sprintf("token:%d", userID)
-	return redis.RedisClient.Get(ctx, key).Result()
+func GetOptionalAuthUser(c *gin.Context) (AuthUser, bool) {
+	value, ok := c.Get(AuthUserKey)
+	if !ok {
+		return AuthUser{}, false
+	}
+	authUser, ok := value.(AuthUser)
+	return authUser, ok
 }
 
 /**
- * @description: 删除redis用户token
+ * @description: 生成JWT token并保存 旧token随之失效
  * @param {uint} userID 用户ID
- * @return {error} 错误信息
+ * @param {string} username 登录账号
+ * @param {string} nickname 昵称
+ * @return {string} token
  */
-func RedisDeleteToken(userID uint) error {
-	ctx := context.Background()
-	key := fmt.Sprintf("token:%d", userID)
-	return redis.RedisClient.Del(ctx, key).Err()
+func (a *Authenticator) IssueToken(ctx context.Context, userID uint, username string, nickname string) (string, error) {
+	token, err := jwt.GenerateToken(userID, username, nickname, a.cfg.Secret, a.cfg.Expires)
+	if err != nil {
+		return "", err
+	}
+	if err := a.tokens.Store(ctx, userID, token, time.Duration(a.cfg.Expires)*time.Hour); err != nil {
+		return "", err
+	}
+	return token, nil
 }
 
 /**
- * @description: 生成JWT token
+ * @description: 删除用户token 用户需要重新登录
  * @param {uint} userID 用户ID
- * @param {string} username 登录账号
- * @return {string} token
+ * @return {error} 错误信息
  */
-func GenerateToken(userID uint, username string, nickname string) string {
-	token, _ := jwt.GenerateToken(userID, username, nickname, config.Cfg.JWT.Secret, config.Cfg.JWT.Expires)
-	RedisStoreToken(token, userID)
-	return token
+func (a *Authenticator) RevokeToken(ctx context.Context, userID uint) error {
+	return a.tokens.Delete(ctx, userID)
 }
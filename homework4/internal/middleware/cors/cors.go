@@ -0,0 +1,64 @@
+package cors
+
+/**
+ * @Description: 跨域中间件 配置重新加载后替换为新的跨域规则
+ */
+import (
+	"homework4/config"
+	"homework4/internal/middleware/deprecation"
+	"homework4/internal/middleware/idempotency"
+	"homework4/internal/middleware/requestid"
+	"sync/atomic"
+	"time"
+
+	gincors "github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+)
+
+type CORS struct {
+	handler atomic.Pointer[gin.HandlerFunc]
+}
+
+/**
+ * @Description: 创建跨域中间件
+ * @param cfg
+ * @return (*CORS, error)
+ */
+func New(cfg config.CORSConfig) (*CORS, error) {
+	c := &CORS{}
+	if err := c.Update(cfg); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+/**
+ * @Description: 替换跨域规则 配置不合法时返回错误并保留原规则
+ * @param cfg
+ * @return error
+ */
+func (c *CORS) Update(cfg config.CORSConfig) error {
+	corsCfg := gincors.Config{
+		AllowOrigins: cfg.AllowOrigins,
+		AllowMethods: []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},                              // 允许的请求方法
+		AllowHeaders: []string{"Origin", "Content-Type", "Authorization", requestid.Header, idempotency.Header}, // 允许的请求头
+		ExposeHeaders: []string{"Content-Length", "Location", requestid.Header, idempotency.HeaderReplayed,
+			deprecation.HeaderDeprecation, deprecation.HeaderSunset, deprecation.HeaderLink},
+		AllowCredentials: cfg.AllowCredentials,
+		MaxAge:           time.Duration(cfg.MaxAge) * time.Second, // 预检请求缓存时间
+	}
+	//gincors.New在配置不合法时会panic 先校验
+	if err := corsCfg.Validate(); err != nil {
+		return err
+	}
+	handler := gincors.New(corsCfg)
+	c.handler.Store(&handler)
+	return nil
+}
+
+// Middleware 使用当前跨域规则处理请求
+func (c *CORS) Middleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		(*c.handler.Load())(ctx)
+	}
+}
@@ -0,0 +1,48 @@
+package deprecation
+
+/**
+ * @Description: 接口弃用中间件 已弃用版本的响应带Deprecation(RFC 9745)、Sunset(RFC 8594)和指向新版本的Link头
+ */
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	HeaderDeprecation = "Deprecation"
+	HeaderSunset      = "Sunset"
+	HeaderLink        = "Link"
+
+	dateLayout = "2006-01-02"
+)
+
+/**
+ * @Description: 创建弃用中间件 日期为空时不返回对应的响应头 配置已经校验过日期格式
+ * @param deprecatedAt 弃用日期 格式2006-01-02
+ * @param sunset 下线日期 格式2006-01-02
+ * @param successor 新版本的地址
+ * @return gin.HandlerFunc
+ */
+func Middleware(deprecatedAt string, sunset string, successor string) gin.HandlerFunc {
+	headers := make(map[string]string, 3)
+	if t, err := time.Parse(dateLayout, deprecatedAt); err == nil {
+		headers[HeaderDeprecation] = "@" + strconv.FormatInt(t.Unix(), 10)
+	}
+	if t, err := time.Parse(dateLayout, sunset); err == nil {
+		headers[HeaderSunset] = t.UTC().Format(http.TimeFormat)
+	}
+	if len(headers) > 0 && successor != "" {
+		headers[HeaderLink] = fmt.Sprintf(`<%s>; rel="successor-version"`, successor)
+	}
+
+	return func(c *gin.Context) {
+		for name, value := range headers {
+			c.Header(name, value)
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,298 @@
+package idempotency
+
+/**
+ * @Description: 幂等中间件 请求携带Idempotency-Key请求头时 同一用户同一请求路径相同的key只处理一次
+ * 首次成功的响应和请求体哈希保存在redis 相同请求体的重试直接返回保存的响应 请求体不同返回422
+ * 首次请求还在处理时 重复请求等待处理完成 超过等待时间返回409 redis不可用时直接放行
+ */
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"homework4/config"
+	"homework4/internal/errcode"
+	"homework4/internal/middleware/auth"
+	"homework4/internal/middleware/response"
+	"homework4/pkg/logger"
+	"io"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+const (
+	Header         = "Idempotency-Key"     // 幂等键请求头
+	HeaderReplayed = "Idempotent-Replayed" // 返回保存的响应时设置为true
+
+	maxKeyLength = 255
+	keyPrefix    = "idempotency:"
+	pollInterval = 100 * time.Millisecond // 等待首次请求完成时查询redis的间隔
+
+	stateProcessing = "processing"
+	stateCompleted  = "completed"
+)
+
+// 保存的响应只回放这些响应头
+var replayHeaders = []string{"Content-Type", "Location"}
+
+// 只有处理中的记录属于当前请求时才写入响应 避免处理超时后覆盖其他请求的记录
+var completeScript = goredis.NewScript(`
+local current = redis.call('GET', KEYS[1])
+if not current or cjson.decode(current).token ~= ARGV[1] then
+	return 0
+end
+redis.call('SET', KEYS[1], ARGV[2], 'EX', ARGV[3])
+return 1
+`)
+
+// 只删除属于当前请求的处理中记录
+var releaseScript = goredis.NewScript(`
+local current = redis.call('GET', KEYS[1])
+if current then
+	local record = cjson.decode(current)
+	if record.state == 'processing' and record.token == ARGV[1] then
+		return redis.call('DEL', KEYS[1])
+	end
+end
+return 0
+`)
+
+// 保存在redis中的记录
+type record struct {
+	State    string            `json:"state"`
+	BodyHash string            `json:"bodyHash"`
+	Token    string            `json:"token,omitempty"` // 处理中的请求标识
+	Status   int               `json:"status,omitempty"`
+	Headers  map[string]string `json:"headers,omitempty"`
+	Body     []byte            `json:"body,omitempty"`
+}
+
+type Idempotency struct {
+	cfg atomic.Pointer[config.IdempotencyConfig]
+	rdb *goredis.Client // 为空时直接放行
+	seq atomic.Uint64
+}
+
+/**
+ * @Description: 创建幂等中间件
+ * @param cfg
+ * @param rdb 为空时直接放行
+ * @return *Idempotency
+ */
+func New(cfg config.IdempotencyConfig, rdb *goredis.Client) *Idempotency {
+	i := &Idempotency{rdb: rdb}
+	i.Update(cfg)
+	return i
+}
+
+/**
+ * @Description: 替换幂等配置 已保存的记录不受影响
+ * @param cfg
+ */
+func (i *Idempotency) Update(cfg config.IdempotencyConfig) {
+	i.cfg.Store(&cfg)
+}
+
+/**
+ * @Description: 幂等中间件 需要放在登录认证之后 按用户区分幂等键 没有携带请求头时直接放行
+ * @return gin.HandlerFunc
+ */
+func (i *Idempotency) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		cfg := i.cfg.Load()
+		key := c.GetHeader(Header)
+		if !cfg.Enabled || i.rdb == nil || key == "" {
+			c.Next()
+			return
+		}
+		if !validKey(key) {
+			response.SendErrorJSON(c, errcode.ErrIdempotencyKeyInvalid)
+			c.Abort()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			response.SendErrorJSON(c, errcode.ErrRequestBodyUnreadable)
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		sum := sha256.Sum256(body)
+		bodyHash := hex.EncodeToString(sum[:])
+
+		i.handle(c, *cfg, redisKey(c, key), bodyHash)
+	}
+}
+
+// 抢占幂等键 抢到后处理请求 否则按已有记录回放、等待或拒绝
+func (i *Idempotency) handle(c *gin.Context, cfg config.IdempotencyConfig, key string, bodyHash string) {
+	ctx := c.Request.Context()
+	log := logger.FromContext(ctx)
+	token := strconv.FormatInt(time.Now().UnixNano(), 36) + "-" + strconv.FormatUint(i.seq.Add(1), 36)
+	processing, _ := json.Marshal(record{State: stateProcessing, BodyHash: bodyHash, Token: token})
+
+	deadline := time.Now().Add(time.Duration(cfg.Wait) * time.Millisecond)
+	for {
+		acquired, err := i.rdb.SetNX(ctx, key, processing, time.Duration(cfg.LockTTL)*time.Second).Result()
+		if err != nil {
+			log.Error("保存幂等记录失败 直接处理请求", logger.WrapMeta(err)...)
+			c.Next()
+			return
+		}
+		if acquired {
+			i.process(c, cfg, key, token, bodyHash)
+			return
+		}
+
+		existing, err := i.load(ctx, key)
+		if err != nil {
+			log.Error("读取幂等记录失败 直接处理请求", logger.WrapMeta(err)...)
+			c.Next()
+			return
+		}
+		//记录刚好过期或被删除 重新抢占
+		if existing == nil {
+			continue
+		}
+		if existing.BodyHash != bodyHash {
+			response.SendErrorJSON(c, errcode.ErrIdempotencyKeyReused)
+			c.Abort()
+			return
+		}
+		if existing.State == stateCompleted {
+			replay(c, existing)
+			return
+		}
+
+		if !time.Now().Before(deadline) {
+			response.SendErrorJSON(c, errcode.ErrIdempotencyInProgress)
+			c.Abort()
+			return
+		}
+		select {
+		case <-ctx.Done():
+			c.Abort()
+			return
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// 处理请求并保存成功的响应 出错时删除处理中的记录 允许客户端使用相同的key重试
+func (i *Idempotency) process(c *gin.Context, cfg config.IdempotencyConfig, key string, token string, bodyHash string) {
+	writer := &bodyWriter{ResponseWriter: c.Writer}
+	c.Writer = writer
+	defer func() {
+		c.Writer = writer.ResponseWriter
+	}()
+
+	//请求结束后客户端可能已经断开 也要写完记录
+	ctx := context.WithoutCancel(c.Request.Context())
+	log := logger.FromContext(ctx)
+	released := false
+	defer func() {
+		//处理过程panic时也要释放
+		if !released {
+			if err := releaseScript.Run(ctx, i.rdb, []string{key}, token).Err(); err != nil {
+				log.Error("删除幂等记录失败", logger.WrapMeta(err)...)
+			}
+		}
+	}()
+
+	c.Next()
+
+	//错误响应由全局错误处理中间件在之后写入 不保存
+	status := writer.Status()
+	if len(c.Errors) > 0 || !writer.Written() || status >= http.StatusInternalServerError {
+		return
+	}
+	headers := make(map[string]string, len(replayHeaders))
+	for _, name := range replayHeaders {
+		if value := writer.Header().Get(name); value != "" {
+			headers[name] = value
+		}
+	}
+	completed, err := json.Marshal(record{State: stateCompleted, BodyHash: bodyHash, Status: status, Headers: headers, Body: writer.body.Bytes()})
+	if err != nil {
+		log.Error("序列化幂等记录失败", logger.WrapMeta(err)...)
+		return
+	}
+	if err := completeScript.Run(ctx, i.rdb, []string{key}, token, completed, cfg.TTL).Err(); err != nil {
+		log.Error("保存幂等响应失败", logger.WrapMeta(err)...)
+		return
+	}
+	released = true
+}
+
+// 读取幂等记录 不存在时返回nil
+func (i *Idempotency) load(ctx context.Context, key string) (*record, error) {
+	data, err := i.rdb.Get(ctx, key).Bytes()
+	if errors.Is(err, goredis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var r record
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// 返回保存的响应
+func replay(c *gin.Context, r *record) {
+	for name, value := range r.Headers {
+		c.Header(name, value)
+	}
+	c.Header(HeaderReplayed, "true")
+	c.Data(r.Status, r.Headers["Content-Type"], r.Body)
+	c.Abort()
+}
+
+// 幂等键按用户和请求路径区分 路径中带资源ID时不同资源的请求互不影响 客户端传入的key只保存哈希
+func redisKey(c *gin.Context, key string) string {
+	var userID uint
+	if authUser, ok := auth.GetOptionalAuthUser(c); ok {
+		userID = authUser.UserID
+	}
+	sum := sha256.Sum256([]byte(c.Request.Method + " " + c.Request.URL.Path + "\n" + key))
+	return keyPrefix + strconv.FormatUint(uint64(userID), 10) + ":" + hex.EncodeToString(sum[:16])
+}
+
+// 只允许可见的ASCII字符
+func validKey(key string) bool {
+	if len(key) > maxKeyLength {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		if key[i] < 0x21 || key[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// 记录写出的响应体
+type bodyWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *bodyWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *bodyWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,181 @@
+package idempotency
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"homework4/config"
+	"homework4/internal/middleware/auth"
+	"homework4/pkg/logger"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	goredis "github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// 使用miniredis的幂等中间件 创建评论的接口返回路径中的文章ID和处理次数
+func newTestRouter(t *testing.T) (*gin.Engine, *miniredis.Miniredis, *atomic.Int64) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	logger.AppLog = zap.NewNop()
+	mr := miniredis.RunT(t)
+	rdb := goredis.NewClient(&goredis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { rdb.Close() })
+	i := New(config.IdempotencyConfig{Enabled: true, TTL: 60, LockTTL: 10, Wait: 0}, rdb)
+
+	handled := &atomic.Int64{}
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set(auth.AuthUserKey, auth.AuthUser{UserID: 1})
+	})
+	r.POST("/api/v2/posts/:id/comments", i.Middleware(), func(c *gin.Context) {
+		n := handled.Add(1)
+		c.JSON(http.StatusCreated, gin.H{"postId": c.Param("id"), "handled": n})
+	})
+	//处理失败的接口
+	r.POST("/api/v2/posts", i.Middleware(), func(c *gin.Context) {
+		handled.Add(1)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "失败"})
+	})
+	return r, mr, handled
+}
+
+// 和中间件相同的redis key
+func testRedisKey(path string, key string) string {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, path, nil)
+	c.Set(auth.AuthUserKey, auth.AuthUser{UserID: 1})
+	return redisKey(c, key)
+}
+
+func doRequest(r *gin.Engine, path string, key string, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(Header, key)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestSameKeyOnDifferentPaths(t *testing.T) {
+	r, _, handled := newTestRouter(t)
+	body := `{"content":"评论"}`
+
+	first := doRequest(r, "/api/v2/posts/1/comments", "key-1", body)
+	second := doRequest(r, "/api/v2/posts/2/comments", "key-1", body)
+
+	if first.Code != http.StatusCreated || second.Code != http.StatusCreated {
+		t.Fatalf("status = %d %d, want 201 201", first.Code, second.Code)
+	}
+	if second.Header().Get(HeaderReplayed) != "" {
+		t.Error("不同文章的请求回放了第一篇文章的响应")
+	}
+	if !strings.Contains(second.Body.String(), `"postId":"2"`) {
+		t.Errorf("body = %s, want postId 2", second.Body.String())
+	}
+	if got := handled.Load(); got != 2 {
+		t.Errorf("处理次数 = %d, want 2", got)
+	}
+}
+
+func TestIdempotentRequests(t *testing.T) {
+	const path = "/api/v2/posts/1/comments"
+	const body = `{"content":"评论"}`
+	processing := `{"state":"processing","bodyHash":"%s","token":"other"}`
+	sum := sha256.Sum256([]byte(body))
+	bodyHash := hex.EncodeToString(sum[:])
+
+	tests := []struct {
+		name        string
+		existing    string // 已有的记录 为空时先发送一次相同的请求
+		body        string
+		wantStatus  int
+		wantReplay  bool
+		wantHandled int64
+	}{
+		{name: "完成后重试返回保存的响应", body: body, wantStatus: http.StatusCreated, wantReplay: true, wantHandled: 1},
+		{name: "请求体不同", body: `{"content":"其他"}`, wantStatus: http.StatusUnprocessableEntity, wantHandled: 1},
+		{name: "首次请求处理中", existing: fmt.Sprintf(processing, bodyHash), body: body, wantStatus: http.StatusConflict},
+		{name: "处理中且请求体不同", existing: fmt.Sprintf(processing, "other"), body: body, wantStatus: http.StatusUnprocessableEntity},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, mr, handled := newTestRouter(t)
+			if tt.existing != "" {
+				mr.Set(testRedisKey(path, "key-1"), tt.existing)
+			} else if w := doRequest(r, path, "key-1", body); w.Code != http.StatusCreated {
+				t.Fatalf("首次请求 status = %d", w.Code)
+			}
+
+			w := doRequest(r, path, "key-1", tt.body)
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d body = %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if replayed := w.Header().Get(HeaderReplayed) == "true"; replayed != tt.wantReplay {
+				t.Errorf("replayed = %v, want %v", replayed, tt.wantReplay)
+			}
+			if tt.wantReplay && !strings.Contains(w.Body.String(), `"handled":1`) {
+				t.Errorf("回放的响应 = %s, want 首次的响应", w.Body.String())
+			}
+			if got := handled.Load(); got != tt.wantHandled {
+				t.Errorf("处理次数 = %d, want %d", got, tt.wantHandled)
+			}
+		})
+	}
+}
+
+func TestFailedRequestReleasesKey(t *testing.T) {
+	r, mr, handled := newTestRouter(t)
+	for i := 0; i < 2; i++ {
+		if w := doRequest(r, "/api/v2/posts", "key-1", `{}`); w.Code != http.StatusInternalServerError {
+			t.Fatalf("status = %d, want 500", w.Code)
+		}
+	}
+	if got := handled.Load(); got != 2 {
+		t.Errorf("处理次数 = %d, want 2 失败后可以重试", got)
+	}
+	if mr.Exists(testRedisKey("/api/v2/posts", "key-1")) {
+		t.Error("失败的请求没有删除处理中的记录")
+	}
+}
+
+func TestScriptsCheckToken(t *testing.T) {
+	_, mr, _ := newTestRouter(t)
+	rdb := goredis.NewClient(&goredis.Options{Addr: mr.Addr()})
+	defer rdb.Close()
+	ctx := context.Background()
+	mr.Set("k", `{"state":"processing","bodyHash":"h","token":"other"}`)
+
+	//处理超时后记录属于其他请求 不能覆盖或删除
+	if n, err := completeScript.Run(ctx, rdb, []string{"k"}, "mine", `{"state":"completed"}`, 60).Int(); err != nil || n != 0 {
+		t.Errorf("completeScript = %d %v, want 0", n, err)
+	}
+	if n, err := releaseScript.Run(ctx, rdb, []string{"k"}, "mine").Int(); err != nil || n != 0 {
+		t.Errorf("releaseScript = %d %v, want 0", n, err)
+	}
+	if !mr.Exists("k") {
+		t.Fatal("其他请求的记录被删除")
+	}
+
+	if n, err := completeScript.Run(ctx, rdb, []string{"k"}, "other", `{"state":"completed"}`, 60).Int(); err != nil || n != 1 {
+		t.Errorf("completeScript = %d %v, want 1", n, err)
+	}
+	if got, _ := mr.Get("k"); got != `{"state":"completed"}` {
+		t.Errorf("记录 = %s", got)
+	}
+	if ttl := mr.TTL("k"); ttl != time.Minute {
+		t.Errorf("ttl = %v, want 1m", ttl)
+	}
+	//已完成的记录不能释放
+	if n, err := releaseScript.Run(ctx, rdb, []string{"k"}, "other").Int(); err != nil || n != 0 {
+		t.Errorf("releaseScript = %d %v, want 0", n, err)
+	}
+}
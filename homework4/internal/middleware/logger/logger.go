@@ -18,7 +18,7 @@ func LoggerMiddleware() gin.HandlerFunc {
 		c.Next()
 
 		cost := time.Since(start)
-		log := logger.AppLog
+		log := logger.FromContext(c.Request.Context())
 
 		log.Info("HTTP请求",
 			zap.Int("status", c.Writer.Status()),
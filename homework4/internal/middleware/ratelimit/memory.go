@@ -0,0 +1,62 @@
+package ratelimit
+
+/**
+ * @Description: 本机内存计数 固定窗口 redis不可用时使用 多个实例之间不共享
+ */
+import (
+	"sync"
+	"time"
+)
+
+const memorySweepInterval = time.Minute // 清理过期窗口的间隔
+
+// 计数窗口
+type window struct {
+	start time.Time
+	size  time.Duration
+	count int
+}
+
+type memoryStore struct {
+	mu        sync.Mutex
+	windows   map[string]*window
+	lastSweep time.Time
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{windows: make(map[string]*window)}
+}
+
+// 计数并判断是否允许 Reset为距离窗口结束的时间
+func (s *memoryStore) allow(key string, limit int, size time.Duration, now time.Time) Result {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sweep(now)
+
+	w, ok := s.windows[key]
+	if !ok || w.size != size || now.Sub(w.start) >= size {
+		w = &window{start: now, size: size}
+		s.windows[key] = w
+	}
+	result := Result{Limit: limit, Reset: w.start.Add(size).Sub(now)}
+	if w.count >= limit {
+		return result
+	}
+	w.count++
+	result.Allowed = true
+	result.Remaining = limit - w.count
+	return result
+}
+
+// 定期清理已过期的窗口 防止IP过多时占用内存
+func (s *memoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < memorySweepInterval {
+		return
+	}
+	for key, w := range s.windows {
+		if now.Sub(w.start) >= w.size {
+			delete(s.windows, key)
+		}
+	}
+	s.lastSweep = now
+}
@@ -0,0 +1,154 @@
+package ratelimit
+
+/**
+ * @Description: 限流中间件 全局按客户端IP限流 路由组按配置的策略限流 超过限制时返回429
+ * 优先使用redis滑动窗口计数 多个实例共享 redis不可用时退化为本机内存计数 配置重新加载后立即生效
+ */
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"homework4/config"
+	"homework4/internal/errcode"
+	"homework4/internal/middleware/auth"
+	"homework4/internal/middleware/response"
+	"homework4/pkg/logger"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+const (
+	HeaderLimit     = "X-RateLimit-Limit"     // 窗口内允许的请求数
+	HeaderRemaining = "X-RateLimit-Remaining" // 窗口内剩余的请求数
+	HeaderReset     = "X-RateLimit-Reset"     // 距离释放出下一个请求名额的秒数
+	HeaderAPIKey    = "X-API-Key"             // 按apiKey计数时读取的请求头
+
+	defaultPolicy = "default"              // 全局限流的策略名
+	redisTimeout  = 200 * time.Millisecond // redis计数超时 超时后使用内存计数
+)
+
+// Result 一次计数的结果
+type Result struct {
+	Allowed   bool          // 是否允许
+	Limit     int           // 窗口内允许的请求数
+	Remaining int           // 窗口内剩余的请求数
+	Reset     time.Duration // 距离释放出下一个请求名额的时间
+}
+
+type Limiter struct {
+	cfg atomic.Pointer[config.RateLimitConfig]
+
+	redis    *redisStore // 为空时只使用内存计数
+	memory   *memoryStore
+	degraded atomic.Bool // redis计数失败 正在使用内存计数
+}
+
+/**
+ * @Description: 创建限流器
+ * @param cfg
+ * @param rdb 为空时只使用内存计数
+ * @return *Limiter
+ */
+func NewLimiter(cfg config.RateLimitConfig, rdb *goredis.Client) *Limiter {
+	l := &Limiter{memory: newMemoryStore()}
+	if rdb != nil {
+		l.redis = newRedisStore(rdb)
+	}
+	l.Update(cfg)
+	return l
+}
+
+/**
+ * @Description: 替换限流配置 窗口大小变化后已有的计数在下一个窗口按新配置计算
+ * @param cfg
+ */
+func (l *Limiter) Update(cfg config.RateLimitConfig) {
+	l.cfg.Store(&cfg)
+}
+
+// Middleware 全局限流中间件 按客户端IP计数 未开启时直接放行
+func (l *Limiter) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		cfg := l.cfg.Load()
+		if !cfg.Enabled {
+			c.Next()
+			return
+		}
+		l.limit(c, defaultPolicy, config.RateLimitPolicy{Key: config.RateLimitKeyIP, Requests: cfg.Requests, Window: cfg.Window})
+	}
+}
+
+/**
+ * @Description: 路由组限流中间件 按rateLimit.policies中同名的策略计数 需要放在登录认证之后才能按用户计数
+ * 未开启限流或没有配置该策略时直接放行
+ * @param name 策略名 使用小写 viper读取配置时key不区分大小写
+ * @return gin.HandlerFunc
+ */
+func (l *Limiter) Policy(name string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		cfg := l.cfg.Load()
+		policy, ok := cfg.Policies[name]
+		if !cfg.Enabled || !ok {
+			c.Next()
+			return
+		}
+		l.limit(c, name, policy)
+	}
+}
+
+// 计数并设置限流响应头 超过限制时返回429
+func (l *Limiter) limit(c *gin.Context, name string, policy config.RateLimitPolicy) {
+	key := name + ":" + identity(c, policy.Key)
+	result := l.allow(c.Request.Context(), key, policy.Requests, time.Duration(policy.Window)*time.Second)
+
+	resetSeconds := strconv.Itoa(int(result.Reset.Seconds() + 0.999))
+	c.Header(HeaderLimit, strconv.Itoa(result.Limit))
+	c.Header(HeaderRemaining, strconv.Itoa(result.Remaining))
+	c.Header(HeaderReset, resetSeconds)
+	if !result.Allowed {
+		c.Header("Retry-After", resetSeconds)
+		response.SendErrorJSON(c, errcode.ErrTooManyRequests)
+		c.Abort()
+		return
+	}
+	c.Next()
+}
+
+// 优先使用redis计数 失败时使用内存计数 只在切换时记录日志
+func (l *Limiter) allow(ctx context.Context, key string, limit int, window time.Duration) Result {
+	if l.redis != nil {
+		redisCtx, cancel := context.WithTimeout(ctx, redisTimeout)
+		result, err := l.redis.allow(redisCtx, key, limit, window)
+		cancel()
+		if err == nil {
+			if l.degraded.CompareAndSwap(true, false) {
+				logger.FromContext(ctx).Info("redis限流计数恢复")
+			}
+			return result
+		}
+		if l.degraded.CompareAndSwap(false, true) {
+			logger.FromContext(ctx).Error("redis限流计数失败 使用内存计数", logger.WrapMeta(err)...)
+		}
+	}
+	return l.memory.allow(key, limit, window, time.Now())
+}
+
+// 计数维度的标识 用户未登录或没有apiKey时按IP计数 apiKey只保存哈希
+func identity(c *gin.Context, keyType string) string {
+	switch keyType {
+	case config.RateLimitKeyUser:
+		if authUser, ok := auth.GetOptionalAuthUser(c); ok {
+			return "user:" + strconv.FormatUint(uint64(authUser.UserID), 10)
+		}
+	case config.RateLimitKeyAPIKey:
+		if apiKey := c.GetHeader(HeaderAPIKey); apiKey != "" {
+			sum := sha256.Sum256([]byte(apiKey))
+			return "apikey:" + hex.EncodeToString(sum[:16])
+		}
+	}
+	return "ip:" + c.ClientIP()
+}
@@ -0,0 +1,68 @@
+package ratelimit
+
+/**
+ * @Description: redis滑动窗口计数 每个请求以时间戳为score写入zset 窗口外的记录在计数前删除
+ */
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+const redisKeyPrefix = "ratelimit:" // 限流计数 zset key前缀 ratelimit:{策略名}:{计数维度}:{标识}
+
+// KEYS[1] 计数key ARGV[1] 当前时间毫秒 ARGV[2] 窗口毫秒 ARGV[3] 允许的请求数 ARGV[4] 本次请求的member
+// 返回 {是否允许, 剩余请求数, 距离最早的请求移出窗口的毫秒数}
+var slidingWindowScript = goredis.NewScript(`
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now - window)
+local count = redis.call('ZCARD', KEYS[1])
+local allowed = 0
+if count < limit then
+	redis.call('ZADD', KEYS[1], now, ARGV[4])
+	redis.call('PEXPIRE', KEYS[1], window)
+	count = count + 1
+	allowed = 1
+end
+local reset = window
+local oldest = redis.call('ZRANGE', KEYS[1], 0, 0, 'WITHSCORES')
+if oldest[2] then
+	reset = tonumber(oldest[2]) + window - now
+end
+return {allowed, limit - count, reset}
+`)
+
+type redisStore struct {
+	rdb      *goredis.Client
+	instance string        // 实例标识 多个实例同一毫秒的请求不会写入相同的member
+	seq      atomic.Uint64 // 同一毫秒内的请求用序号区分member
+}
+
+func newRedisStore(rdb *goredis.Client) *redisStore {
+	b := make([]byte, 4)
+	_, _ = rand.Read(b)
+	return &redisStore{rdb: rdb, instance: hex.EncodeToString(b)}
+}
+
+func (s *redisStore) allow(ctx context.Context, key string, limit int, window time.Duration) (Result, error) {
+	now := time.Now().UnixMilli()
+	member := strconv.FormatInt(now, 10) + "-" + s.instance + "-" + strconv.FormatUint(s.seq.Add(1), 10)
+	values, err := slidingWindowScript.Run(ctx, s.rdb, []string{redisKeyPrefix + key},
+		now, window.Milliseconds(), limit, member).Int64Slice()
+	if err != nil {
+		return Result{}, err
+	}
+	return Result{
+		Allowed:   values[0] == 1,
+		Limit:     limit,
+		Remaining: int(values[1]),
+		Reset:     time.Duration(values[2]) * time.Millisecond,
+	}, nil
+}
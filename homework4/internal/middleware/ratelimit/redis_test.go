@@ -0,0 +1,104 @@
+package ratelimit
+
+import (
+	"context"
+	"homework4/config"
+	"homework4/pkg/logger"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	goredis "github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+func newTestRedis(t *testing.T) (*goredis.Client, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	rdb := goredis.NewClient(&goredis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { rdb.Close() })
+	return rdb, mr
+}
+
+func TestSlidingWindowScript(t *testing.T) {
+	rdb, _ := newTestRedis(t)
+	const window, limit = 1000, 2
+	//按顺序执行 时间单位毫秒
+	steps := []struct {
+		now       int64
+		allowed   int64
+		remaining int64
+		reset     int64
+	}{
+		{now: 0, allowed: 1, remaining: 1, reset: 1000},
+		{now: 100, allowed: 1, remaining: 0, reset: 900},
+		{now: 500, allowed: 0, remaining: 0, reset: 500}, // 拒绝的请求不计入窗口
+		{now: 999, allowed: 0, remaining: 0, reset: 1},
+		{now: 1000, allowed: 1, remaining: 0, reset: 100}, // 0移出窗口
+		{now: 1050, allowed: 0, remaining: 0, reset: 50},
+		{now: 1100, allowed: 1, remaining: 0, reset: 900},  // 100移出窗口 最早的是1000
+		{now: 3000, allowed: 1, remaining: 1, reset: 1000}, // 全部移出窗口
+	}
+	for i, step := range steps {
+		got, err := slidingWindowScript.Run(context.Background(), rdb, []string{"ratelimit:test"},
+			step.now, window, limit, "m"+strconv.Itoa(i)).Int64Slice()
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []int64{step.allowed, step.remaining, step.reset}
+		if len(got) != 3 || got[0] != want[0] || got[1] != want[1] || got[2] != want[2] {
+			t.Errorf("now=%d {allowed, remaining, reset} = %v, want %v", step.now, got, want)
+		}
+	}
+}
+
+func TestRedisStoreCountsRequestsInSameMillisecond(t *testing.T) {
+	rdb, mr := newTestRedis(t)
+	store := newRedisStore(rdb)
+	for i := 0; i < 3; i++ {
+		result, err := store.allow(context.Background(), "ip:1.2.3.4", 3, time.Minute)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !result.Allowed || result.Remaining != 2-i {
+			t.Fatalf("第%d次 allowed = %v remaining = %d, want true %d", i+1, result.Allowed, result.Remaining, 2-i)
+		}
+	}
+	result, err := store.allow(context.Background(), "ip:1.2.3.4", 3, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Allowed {
+		t.Error("超过限制后仍然允许")
+	}
+	if result.Reset <= 0 || result.Reset > time.Minute {
+		t.Errorf("reset = %v, want (0, 1m]", result.Reset)
+	}
+	if members, _ := mr.ZMembers(redisKeyPrefix + "ip:1.2.3.4"); len(members) != 3 {
+		t.Errorf("窗口中的请求 = %d, want 3", len(members))
+	}
+	if ttl := mr.TTL(redisKeyPrefix + "ip:1.2.3.4"); ttl <= 0 || ttl > time.Minute {
+		t.Errorf("ttl = %v, want (0, 1m]", ttl)
+	}
+}
+
+func TestLimiterFallsBackToMemory(t *testing.T) {
+	logger.AppLog = zap.NewNop()
+	rdb, mr := newTestRedis(t)
+	l := NewLimiter(config.RateLimitConfig{}, rdb)
+	mr.Close()
+
+	//redis不可用时使用本机计数 仍然限流
+	for i := 0; i < 2; i++ {
+		if result := l.allow(context.Background(), "ip:1.2.3.4", 2, time.Minute); !result.Allowed {
+			t.Fatalf("第%d次请求被拒绝", i+1)
+		}
+	}
+	if result := l.allow(context.Background(), "ip:1.2.3.4", 2, time.Minute); result.Allowed {
+		t.Error("redis不可用时没有限流")
+	}
+	if !l.degraded.Load() {
+		t.Error("没有标记为使用内存计数")
+	}
+}
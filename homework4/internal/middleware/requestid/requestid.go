@@ -0,0 +1,93 @@
+package requestid
+
+/**
+ * @Description: 请求ID 沿用客户端传入的X-Request-ID 没有或不合法时生成 响应头原样返回
+ * 同时把带request_id和route的日志记录器放入请求上下文 service中通过logger.FromContext获取
+ */
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+
+	"homework4/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	Header = "X-Request-ID"
+
+	maxLength = 128 // 客户端传入的请求ID最大长度
+)
+
+type contextKey struct{}
+
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := Resolve(c.GetHeader(Header))
+		c.Header(Header, id)
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		ctx := NewContext(c.Request.Context(), id)
+		ctx = logger.With(ctx, zap.String("request_id", id), zap.String("route", route))
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+	}
+}
+
+/**
+ * @Description: 将请求ID放入上下文
+ * @param ctx
+ * @param id
+ * @return context.Context
+ */
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+/**
+ * @Description: 获取上下文中的请求ID 没有时返回空字符串
+ * @param ctx
+ * @return string
+ */
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
+
+/**
+ * @Description: 沿用客户端传入的请求ID 没有或不合法时生成新的ID
+ * @param id 客户端传入的请求ID
+ * @return string
+ */
+func Resolve(id string) string {
+	if !valid(id) {
+		return generate()
+	}
+	return id
+}
+
+// 只接受可见ASCII字符 避免日志注入和超长请求头
+func valid(id string) bool {
+	if id == "" || len(id) > maxLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// 生成32位十六进制随机ID
+func generate() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -4,8 +4,13 @@ package response
  * @Description: 封装统一返回和错误处理
  */
 import (
+	"errors"
+	"fmt"
 	"net/http"
 
+	"homework4/internal/errcode"
+	"homework4/internal/middleware/requestid"
+	"homework4/internal/validation"
 	"homework4/pkg/logger"
 
 	"github.com/gin-gonic/gin"
@@ -17,6 +22,12 @@ const (
 	CodeServerError  = 500 // 服务器错误返回码
 	CodeBadRequest   = 400 // 参数错误或者业务错误返回码
 	CodeUnauthorized = 401 // 未授权返回码
+	CodeForbidden    = 403 // 没有权限返回码
+	CodeNotFound     = 404 // 资源不存在返回码
+
+	CodeConflict            = 409 // 请求冲突返回码 如相同幂等键的请求正在处理
+	CodeUnprocessableEntity = 422 // 请求无法处理返回码 如幂等键已用于不同的请求内容
+	CodeTooManyRequests     = 429 // 请求过于频繁返回码
 )
 
 type BizError struct {
@@ -55,10 +66,24 @@ func NewBadRequestError(message string) *BizError {
 	}
 }
 
+// 参数绑定或校验失败 返回时转换为字段级的错误详情
+func NewValidationError(err error) *BizError {
+	return &BizError{
+		Code:    CodeBadRequest,
+		Message: errcode.ErrInvalidParams.Error(),
+		Detail:  err,
+	}
+}
+
 type Response struct {
 	Code    int         `json:"code" example:"200"`
 	Message string      `json:"message" example:"success"`
 	Data    interface{} `json:"data,omitempty"`
+
+	ErrorCode string                  `json:"errorCode,omitempty" example:"POST_NOT_FOUND"` // 错误码 出错时返回 客户端按错误码判断错误 不要依赖message
+	Details   []validation.FieldError `json:"details,omitempty"`                            // 参数错误时各字段的错误
+
+	RequestID string `json:"requestId,omitempty" example:"9f86d081884c7d659a2feaa0c55ad015"` // 请求ID 出错时返回 用于排查日志
 }
 
 type HandlerFunc func(c *gin.Context) error
@@ -88,16 +113,52 @@ func SendJSON(c *gin.Context, data interface{}) {
 }
 
 /**
- * @Description: 发送错误JSON响应
+ * @Description: 发送创建成功响应 状态码201 Location为新资源的地址
+ * @param c
+ * @param location
+ * @param data
+ */
+func SendCreated(c *gin.Context, location string, data interface{}) {
+	c.Header("Location", location)
+	c.JSON(http.StatusCreated, Success(data))
+}
+
+/**
+ * @Description: 发送没有响应体的成功响应 状态码204
+ * @param c
+ */
+func SendNoContent(c *gin.Context) {
+	c.Status(http.StatusNoContent)
+	//没有写响应体时gin不会写出状态码
+	c.Writer.WriteHeaderNow()
+}
+
+/**
+ * @Description: 发送错误JSON响应 带错误码的错误按错误类型返回HTTP状态码 提示按Accept-Language本地化
+ * 其他错误都返回500 不向客户端暴露错误内容
  * @param c
  * @param err
  */
 func SendErrorJSON(c *gin.Context, err error) {
-	logger := logger.AppLog
+	logger := logger.FromContext(c.Request.Context())
+	lang := errcode.ParseAcceptLanguage(c.GetHeader("Accept-Language"))
 	var resp *Response
 	var statusCode int
 
-	if bizErr, ok := err.(*BizError); ok {
+	var bizErr *BizError
+	if codeErr, ok := errcode.From(err); ok {
+		statusCode = codeErr.Kind.Status()
+		resp = Fail(statusCode, codeErr.Message(lang))
+		resp.ErrorCode = codeErr.Code
+
+		logger.Error("业务错误",
+			zap.Int("code", statusCode),
+			zap.String("error_code", codeErr.Code),
+			zap.String("message", codeErr.Error()),
+			zap.String("path", c.Request.URL.Path),
+			zap.String("method", c.Request.Method),
+		)
+	} else if errors.As(err, &bizErr) {
 		resp = Fail(bizErr.Code, bizErr.Message)
 
 		switch bizErr.Code {
@@ -105,6 +166,22 @@ func SendErrorJSON(c *gin.Context, err error) {
 			statusCode = http.StatusUnauthorized
 		case CodeBadRequest:
 			statusCode = http.StatusBadRequest
+			resp.ErrorCode = errcode.ErrInvalidParams.Code
+			//参数绑定和校验错误转换为字段错误 不返回校验器的原始错误
+			if detailErr, ok := bizErr.Detail.(error); ok {
+				resp.Message = errcode.ErrInvalidParams.Message(lang)
+				resp.Details = validation.Details(detailErr, lang)
+			}
+		case CodeForbidden:
+			statusCode = http.StatusForbidden
+		case CodeNotFound:
+			statusCode = http.StatusNotFound
+		case CodeConflict:
+			statusCode = http.StatusConflict
+		case CodeUnprocessableEntity:
+			statusCode = http.StatusUnprocessableEntity
+		case CodeTooManyRequests:
+			statusCode = http.StatusTooManyRequests
 		default:
 			statusCode = http.StatusOK
 		}
@@ -117,8 +194,9 @@ func SendErrorJSON(c *gin.Context, err error) {
 			zap.String("method", c.Request.Method),
 		)
 	} else {
-		resp = Fail(CodeServerError, "服务器内部错误")
 		statusCode = http.StatusInternalServerError
+		resp = Fail(CodeServerError, errcode.ErrInternal.Message(lang))
+		resp.ErrorCode = errcode.ErrInternal.Code
 		logger.Error("系统错误",
 			zap.Error(err),
 			zap.String("path", c.Request.URL.Path),
@@ -126,6 +204,7 @@ func SendErrorJSON(c *gin.Context, err error) {
 		)
 	}
 
+	resp.RequestID = requestid.FromContext(c.Request.Context())
 	c.JSON(statusCode, resp)
 }
 
@@ -158,11 +237,7 @@ func WrapHandler(h HandlerFunc) gin.HandlerFunc {
 				case error:
 					e = v
 				default:
-					e = &BizError{
-						Code:    CodeServerError,
-						Message: "系统内部错误",
-						Detail:  v,
-					}
+					e = fmt.Errorf("panic: %v", v)
 				}
 				c.Error(e)
 			}
@@ -0,0 +1,42 @@
+package migrate
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+)
+
+// 迁移名称只允许小写字母、数字和下划线
+var namePattern = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+/**
+ * @Description: 在每个驱动目录下创建空的up/down迁移文件 版本号为当前时间
+ * @param dir 迁移文件根目录
+ * @param drivers 驱动列表
+ * @param name 迁移名称
+ * @param now
+ * @return ([]string, error) 创建的文件
+ */
+func Create(dir string, drivers []string, name string, now time.Time) ([]string, error) {
+	if !namePattern.MatchString(name) {
+		return nil, fmt.Errorf("迁移名称只能包含小写字母、数字和下划线: %s", name)
+	}
+	version := now.Format("20060102150405")
+	var files []string
+	for _, driver := range drivers {
+		for _, direction := range []string{"up", "down"} {
+			file := filepath.Join(dir, driver, fmt.Sprintf("%s_%s.%s.sql", version, name, direction))
+			if _, err := os.Stat(file); err == nil {
+				return files, fmt.Errorf("迁移文件已存在: %s", file)
+			}
+			content := fmt.Sprintf("-- %s %s %s\n", version, name, direction)
+			if err := os.WriteFile(file, []byte(content), 0o644); err != nil {
+				return files, err
+			}
+			files = append(files, file)
+		}
+	}
+	return files, nil
+}
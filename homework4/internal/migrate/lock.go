@@ -0,0 +1,101 @@
+package migrate
+
+/**
+ * @Description: 迁移锁 防止多个实例同时执行迁移
+ * mysql使用GET_LOCK postgres使用pg_advisory_lock 都是会话级锁 需要固定同一个连接
+ * sqlite没有会话锁 使用锁表 超过lockStaleAfter的锁视为进程异常退出后残留的锁
+ */
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	lockName       = "homework4_schema_migrations" // mysql锁名
+	lockKey        = 7316240529                    // postgres advisory锁key
+	lockTimeout    = 60 * time.Second              // 等待锁的最长时间
+	lockStaleAfter = 10 * time.Minute              // sqlite锁表中的锁超过该时间视为残留
+)
+
+// sqlite锁表记录
+type migrationLock struct {
+	ID       int       `gorm:"primaryKey;autoIncrement:false"`
+	LockedAt time.Time `gorm:"not null"`
+}
+
+func (migrationLock) TableName() string {
+	return "schema_migrations_lock"
+}
+
+// 获取迁移锁后执行fn 锁和fn使用同一个连接
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *gorm.DB) error) error {
+	return m.db.WithContext(ctx).Connection(func(conn *gorm.DB) error {
+		//Connection返回的实例链式调用会共享查询条件 使用新会话
+		conn = conn.Session(&gorm.Session{NewDB: true})
+		if err := m.ensureTable(conn); err != nil {
+			return err
+		}
+		unlock, err := m.lock(ctx, conn)
+		if err != nil {
+			return err
+		}
+		defer unlock()
+		return fn(conn)
+	})
+}
+
+func (m *Migrator) lock(ctx context.Context, conn *gorm.DB) (func(), error) {
+	switch conn.Dialector.Name() {
+	case "mysql":
+		var locked *int
+		if err := conn.Raw("SELECT GET_LOCK(?, ?)", lockName, int(lockTimeout.Seconds())).Scan(&locked).Error; err != nil {
+			return nil, err
+		}
+		if locked == nil || *locked != 1 {
+			return nil, errors.New("等待迁移锁超时 可能有其他实例正在执行迁移")
+		}
+		return func() { conn.Exec("SELECT RELEASE_LOCK(?)", lockName) }, nil
+	case "postgres":
+		lockCtx, cancel := context.WithTimeout(ctx, lockTimeout)
+		defer cancel()
+		if err := conn.WithContext(lockCtx).Exec("SELECT pg_advisory_lock(?)", lockKey).Error; err != nil {
+			return nil, fmt.Errorf("获取迁移锁失败: %w", err)
+		}
+		return func() { conn.Exec("SELECT pg_advisory_unlock(?)", lockKey) }, nil
+	default:
+		return m.lockTable(ctx, conn)
+	}
+}
+
+// 通过锁表主键冲突实现互斥
+func (m *Migrator) lockTable(ctx context.Context, conn *gorm.DB) (func(), error) {
+	//锁操作在固定连接上执行 不能再开启默认事务
+	conn = conn.Session(&gorm.Session{NewDB: true, SkipDefaultTransaction: true})
+	if err := conn.AutoMigrate(&migrationLock{}); err != nil {
+		return nil, err
+	}
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		conn.Where("id = 1 AND locked_at < ?", time.Now().Add(-lockStaleAfter)).Delete(&migrationLock{})
+		result := conn.Clauses(clause.OnConflict{DoNothing: true}).Create(&migrationLock{ID: 1, LockedAt: time.Now()})
+		if result.Error != nil {
+			return nil, result.Error
+		}
+		if result.RowsAffected == 1 {
+			return func() { conn.Delete(&migrationLock{}, 1) }, nil
+		}
+		if time.Now().After(deadline) {
+			return nil, errors.New("等待迁移锁超时 可能有其他实例正在执行迁移")
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(500 * time.Millisecond):
+		}
+	}
+}
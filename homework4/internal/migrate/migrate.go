@@ -0,0 +1,405 @@
+package migrate
+
+/**
+ * @Description: 数据库版本迁移 按版本号顺序执行up/down迁移文件 执行记录保存在schema_migrations表
+ * 迁移文件内容的sha256作为校验和 已执行的迁移文件被修改时拒绝继续迁移
+ */
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrSchemaBehind 数据库结构版本落后于迁移文件
+var ErrSchemaBehind = errors.New("数据库结构版本落后 请先执行迁移")
+
+// 迁移文件名 {版本}_{名称}.up.sql {版本}_{名称}.down.sql
+var fileNamePattern = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// Migration 一个版本的迁移
+type Migration struct {
+	Version  int64  // 版本号 按从小到大执行
+	Name     string // 名称
+	Up       string // 升级sql
+	Down     string // 回滚sql
+	Checksum string // 升级sql的sha256
+}
+
+// MigrationStatus 迁移执行状态
+type MigrationStatus struct {
+	Version   int64      `json:"version"`   // 版本号
+	Name      string     `json:"name"`      // 名称
+	Applied   bool       `json:"applied"`   // 是否已执行
+	AppliedAt *time.Time `json:"appliedAt"` // 执行时间
+	Modified  bool       `json:"modified"`  // 执行后迁移文件是否被修改
+	Missing   bool       `json:"missing"`   // 已执行但迁移文件不存在
+}
+
+// 迁移执行记录
+type schemaMigration struct {
+	Version   int64     `gorm:"primaryKey;autoIncrement:false"`
+	Name      string    `gorm:"size:255;not null"`
+	Checksum  string    `gorm:"size:64;not null"`
+	AppliedAt time.Time `gorm:"not null"`
+}
+
+// 迁移记录表名固定为schema_migrations 不加表名前缀
+func (schemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+type Migrator struct {
+	db         *gorm.DB
+	driver     string
+	migrations []Migration
+}
+
+/**
+ * @Description: 创建迁移器 从fsys中读取driver目录下的迁移文件
+ * @param db
+ * @param driver 数据库驱动 mysql/postgres/sqlite
+ * @param fsys
+ * @return (*Migrator, error)
+ */
+func New(db *gorm.DB, driver string, fsys fs.FS) (*Migrator, error) {
+	migrations, err := Load(fsys, driver)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, driver: driver, migrations: migrations}, nil
+}
+
+/**
+ * @Description: 读取目录下的迁移文件 按版本号排序 每个版本必须同时有up和down文件
+ * @param fsys
+ * @param dir
+ * @return ([]Migration, error)
+ */
+func Load(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		match := fileNamePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("迁移文件名格式错误: %s", entry.Name())
+		}
+		version, _ := strconv.ParseInt(match[1], 10, 64)
+		content, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		} else if migration.Name != match[2] {
+			return nil, fmt.Errorf("迁移版本%d存在不同的名称: %s %s", version, migration.Name, match[2])
+		}
+		if match[3] == "up" {
+			migration.Up = string(content)
+			sum := sha256.Sum256(content)
+			migration.Checksum = hex.EncodeToString(sum[:])
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Checksum == "" {
+			return nil, fmt.Errorf("迁移版本%d缺少up文件", migration.Version)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+/**
+ * @Description: 执行未执行的迁移
+ * @param ctx
+ * @param steps 最多执行的数量 0为全部
+ * @return ([]Migration, error) 本次执行的迁移
+ */
+func (m *Migrator) Up(ctx context.Context, steps int) ([]Migration, error) {
+	var done []Migration
+	err := m.withLock(ctx, func(conn *gorm.DB) error {
+		applied, err := m.applied(conn)
+		if err != nil {
+			return err
+		}
+		if err := m.verify(applied); err != nil {
+			return err
+		}
+		for _, migration := range m.migrations {
+			if _, ok := applied[migration.Version]; ok {
+				continue
+			}
+			if steps > 0 && len(done) >= steps {
+				break
+			}
+			if err := m.run(conn, migration, true); err != nil {
+				return err
+			}
+			done = append(done, migration)
+		}
+		return nil
+	})
+	return done, err
+}
+
+/**
+ * @Description: 按版本倒序回滚已执行的迁移
+ * @param ctx
+ * @param steps 回滚的数量 小于1时回滚1个
+ * @return ([]Migration, error) 本次回滚的迁移
+ */
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	if steps < 1 {
+		steps = 1
+	}
+	var done []Migration
+	err := m.withLock(ctx, func(conn *gorm.DB) error {
+		applied, err := m.applied(conn)
+		if err != nil {
+			return err
+		}
+		for i := len(m.migrations) - 1; i >= 0 && len(done) < steps; i-- {
+			migration := m.migrations[i]
+			if _, ok := applied[migration.Version]; !ok {
+				continue
+			}
+			if strings.TrimSpace(migration.Down) == "" {
+				return fmt.Errorf("迁移版本%d没有down文件 无法回滚", migration.Version)
+			}
+			if err := m.run(conn, migration, false); err != nil {
+				return err
+			}
+			done = append(done, migration)
+		}
+		return nil
+	})
+	return done, err
+}
+
+/**
+ * @Description: 查询所有迁移的执行状态 包含已执行但文件不存在的迁移
+ * @param ctx
+ * @return ([]MigrationStatus, error)
+ */
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	conn := m.db.WithContext(ctx)
+	if err := m.ensureTable(conn); err != nil {
+		return nil, err
+	}
+	applied, err := m.applied(conn)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(m.migrations))
+	known := make(map[int64]bool, len(m.migrations))
+	for _, migration := range m.migrations {
+		known[migration.Version] = true
+		status := MigrationStatus{Version: migration.Version, Name: migration.Name}
+		if record, ok := applied[migration.Version]; ok {
+			appliedAt := record.AppliedAt
+			status.Applied = true
+			status.AppliedAt = &appliedAt
+			status.Modified = record.Checksum != migration.Checksum
+		}
+		statuses = append(statuses, status)
+	}
+	for version, record := range applied {
+		if known[version] {
+			continue
+		}
+		appliedAt := record.AppliedAt
+		statuses = append(statuses, MigrationStatus{Version: version, Name: record.Name, Applied: true, AppliedAt: &appliedAt, Missing: true})
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Version < statuses[j].Version
+	})
+	return statuses, nil
+}
+
+/**
+ * @Description: 检查数据库结构是否为最新版本 有未执行的迁移时返回ErrSchemaBehind
+ * @param ctx
+ * @return error
+ */
+func (m *Migrator) Check(ctx context.Context) error {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return err
+	}
+	pending := 0
+	for _, status := range statuses {
+		if status.Modified {
+			return fmt.Errorf("迁移版本%d执行后文件被修改", status.Version)
+		}
+		if !status.Applied {
+			pending++
+		}
+	}
+	if pending > 0 {
+		return fmt.Errorf("%w 未执行的迁移数: %d", ErrSchemaBehind, pending)
+	}
+	return nil
+}
+
+/**
+ * @Description: 当前数据库结构版本 没有执行过迁移时为0
+ * @param ctx
+ * @return (int64, error)
+ */
+func (m *Migrator) Version(ctx context.Context) (int64, error) {
+	var version int64
+	err := m.db.WithContext(ctx).Model(&schemaMigration{}).Select("COALESCE(MAX(version), 0)").Scan(&version).Error
+	return version, err
+}
+
+// LatestVersion 迁移文件的最新版本
+func (m *Migrator) LatestVersion() int64 {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// 执行单个迁移并记录 postgres和sqlite的DDL在事务中执行 mysql的DDL会隐式提交
+func (m *Migrator) run(conn *gorm.DB, migration Migration, up bool) error {
+	script := migration.Up
+	if !up {
+		script = migration.Down
+	}
+	return conn.Transaction(func(tx *gorm.DB) error {
+		for _, statement := range splitStatements(script) {
+			if err := tx.Exec(statement).Error; err != nil {
+				return fmt.Errorf("执行迁移%d_%s失败: %w", migration.Version, migration.Name, err)
+			}
+		}
+		if !up {
+			return tx.Delete(&schemaMigration{}, migration.Version).Error
+		}
+		return tx.Create(&schemaMigration{
+			Version:   migration.Version,
+			Name:      migration.Name,
+			Checksum:  migration.Checksum,
+			AppliedAt: time.Now(),
+		}).Error
+	})
+}
+
+// 已执行迁移的校验和必须和文件一致
+func (m *Migrator) verify(applied map[int64]schemaMigration) error {
+	for _, migration := range m.migrations {
+		if record, ok := applied[migration.Version]; ok && record.Checksum != migration.Checksum {
+			return fmt.Errorf("迁移版本%d执行后文件被修改 请新建迁移而不是修改已执行的迁移", migration.Version)
+		}
+	}
+	return nil
+}
+
+// 查询已执行的迁移
+func (m *Migrator) applied(conn *gorm.DB) (map[int64]schemaMigration, error) {
+	var records []schemaMigration
+	if err := conn.Order("version").Find(&records).Error; err != nil {
+		return nil, err
+	}
+	applied := make(map[int64]schemaMigration, len(records))
+	for _, record := range records {
+		applied[record.Version] = record
+	}
+	return applied, nil
+}
+
+// 创建迁移记录表
+func (m *Migrator) ensureTable(conn *gorm.DB) error {
+	return conn.Exec("CREATE TABLE IF NOT EXISTS schema_migrations (" +
+		"version BIGINT NOT NULL PRIMARY KEY, " +
+		"name VARCHAR(255) NOT NULL, " +
+		"checksum VARCHAR(64) NOT NULL, " +
+		"applied_at TIMESTAMP NOT NULL)").Error
+}
+
+/**
+ * @Description: 按分号拆分sql语句 忽略引号中的分号 去掉--注释和块注释 引号中的引号使用两个引号转义
+ * @param script
+ * @return []string
+ */
+func splitStatements(script string) []string {
+	var statements []string
+	var builder strings.Builder
+	flush := func() {
+		if statement := strings.TrimSpace(builder.String()); statement != "" {
+			statements = append(statements, statement)
+		}
+		builder.Reset()
+	}
+
+	var quote rune
+	lineComment, blockComment := false, false
+	chars := []rune(script)
+	for i := 0; i < len(chars); i++ {
+		char := chars[i]
+		var next rune
+		if i+1 < len(chars) {
+			next = chars[i+1]
+		}
+		switch {
+		case lineComment:
+			if char == '\n' {
+				lineComment = false
+				builder.WriteRune(char)
+			}
+			continue
+		case blockComment:
+			if char == '*' && next == '/' {
+				blockComment = false
+				i++
+			}
+			continue
+		case quote != 0:
+			//两个引号转义时先结束再开始 结果相同
+			if char == quote {
+				quote = 0
+			}
+		case char == '\'' || char == '"' || char == '`':
+			quote = char
+		case char == '-' && next == '-':
+			lineComment = true
+			continue
+		case char == '/' && next == '*':
+			blockComment = true
+			i++
+			continue
+		case char == ';':
+			flush()
+			continue
+		}
+		builder.WriteRune(char)
+	}
+	flush()
+	return statements
+}
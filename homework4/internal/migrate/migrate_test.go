@@ -0,0 +1,139 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestSplitStatements(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   []string
+	}{
+		{
+			name:   "多条语句",
+			script: "CREATE TABLE a (id INT);\nCREATE TABLE b (id INT);\n",
+			want:   []string{"CREATE TABLE a (id INT)", "CREATE TABLE b (id INT)"},
+		},
+		{
+			name:   "最后一条没有分号",
+			script: "SELECT 1;\nSELECT 2",
+			want:   []string{"SELECT 1", "SELECT 2"},
+		},
+		{
+			name:   "单引号中的分号",
+			script: "INSERT INTO a VALUES ('x;y');SELECT 1;",
+			want:   []string{"INSERT INTO a VALUES ('x;y')", "SELECT 1"},
+		},
+		{
+			name:   "两个单引号转义",
+			script: "INSERT INTO a VALUES ('it''s;ok');",
+			want:   []string{"INSERT INTO a VALUES ('it''s;ok')"},
+		},
+		{
+			name:   "双引号和反引号中的分号",
+			script: "CREATE TABLE \"a;b\" (`c;d` INT);",
+			want:   []string{"CREATE TABLE \"a;b\" (`c;d` INT)"},
+		},
+		{
+			name:   "单独一行的注释",
+			script: "-- 创建表;\nCREATE TABLE a (id INT);\n-- 结束",
+			want:   []string{"CREATE TABLE a (id INT)"},
+		},
+		{
+			name:   "行尾注释中的分号",
+			script: "CREATE TABLE a (id INT); -- 注释; 不是语句\nSELECT 1;",
+			want:   []string{"CREATE TABLE a (id INT)", "SELECT 1"},
+		},
+		{
+			name:   "块注释中的分号",
+			script: "/* 注释;\n多行; */CREATE TABLE a (id INT);",
+			want:   []string{"CREATE TABLE a (id INT)"},
+		},
+		{
+			name:   "引号中的注释符号",
+			script: "COMMENT ON TABLE a IS '-- 不是注释 /* */';",
+			want:   []string{"COMMENT ON TABLE a IS '-- 不是注释 /* */'"},
+		},
+		{
+			name:   "只有注释和空白",
+			script: "-- 空迁移\n\n  ;\n",
+			want:   nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := splitStatements(tt.script); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitStatements() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// 只有一个连接的sqlite内存数据库 所有操作使用同一个数据库
+func newLockTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	return db
+}
+
+func TestLockTable(t *testing.T) {
+	db := newLockTestDB(t)
+	m := &Migrator{db: db, driver: "sqlite"}
+	ctx := context.Background()
+
+	unlock, err := m.lockTable(ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	//持有锁时再次获取一直等待 直到ctx结束
+	waitCtx, cancel := context.WithTimeout(ctx, 200*time.Millisecond)
+	defer cancel()
+	if _, err := m.lockTable(waitCtx, db); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("持有锁时获取锁 err = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	unlock()
+	unlock, err = m.lockTable(ctx, db)
+	if err != nil {
+		t.Fatalf("释放后获取锁失败: %v", err)
+	}
+	unlock()
+}
+
+func TestLockTableTakesOverStaleLock(t *testing.T) {
+	db := newLockTestDB(t)
+	m := &Migrator{db: db, driver: "sqlite"}
+	if err := db.AutoMigrate(&migrationLock{}); err != nil {
+		t.Fatal(err)
+	}
+	//进程异常退出后残留的锁
+	if err := db.Create(&migrationLock{ID: 1, LockedAt: time.Now().Add(-2 * lockStaleAfter)}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	unlock, err := m.lockTable(ctx, db)
+	if err != nil {
+		t.Fatalf("残留的锁没有被清除: %v", err)
+	}
+	unlock()
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+/**
+ * @description: 阅读清单模型
+ */
+type ReadingList struct {
+	gorm.Model
+	UserID      uint   `json:"userId" gorm:"not null;index:idx_reading_list_user_id;comment:用户ID"` //设置不能为null 引用用户模型ID 添加一个索引
+	Name        string `json:"name" gorm:"not null;size:32;comment:清单名称"`                          //设置不能为null 长度32
+	Description string `json:"description" gorm:"size:200;comment:清单描述"`                           //长度200
+	IsPublic    bool   `json:"isPublic" gorm:"not null;default:false;comment:是否公开"`                //默认不公开
+}
+
+// 配置表中文注释
+func (r *ReadingList) TableComment() string {
+	return "阅读清单表"
+}
+
+/**
+ * @description: 收藏模型 ReadingListID为0表示默认的稍后阅读
+ */
+type Bookmark struct {
+	ID            uint      `gorm:"primarykey"`
+	UserID        uint      `json:"userId" gorm:"not null;uniqueIndex:idx_bookmark_user_list_post;comment:用户ID"`                            //设置不能为null 和清单、文章组成唯一索引
+	ReadingListID uint      `json:"readingListId" gorm:"not null;default:0;uniqueIndex:idx_bookmark_user_list_post;comment:阅读清单ID 0-稍后阅读"`  //默认值为0
+	PostID        uint      `json:"postId" gorm:"not null;uniqueIndex:idx_bookmark_user_list_post;index:idx_bookmark_post_id;comment:文章ID"` //设置不能为null 引用文章模型ID
+	Position      int64     `json:"position" gorm:"not null;default:0;comment:排序位置 越大越靠前"`                                                  //用于调整顺序
+	CreatedAt     time.Time `json:"createdAt"`
+}
+
+// 配置表中文注释
+func (b *Bookmark) TableComment() string {
+	return "收藏表"
+}
@@ -9,15 +9,16 @@ import (
  */
 type Comment struct {
 	gorm.Model
-	UserID  uint   `json:"userId" gorm:"not null;index:idx_user_id;comment:用户ID"` //设置不能为null 引用用户模型ID 添加一个索引
-	PostID uint `json:"postId" gorm:"not null;index:idx_post_id;comment:文章ID"` //设置不能为null 引用文章模型ID 添加一个索引
-	Content string `json:"content" gorm:"not null;size:200;comment:内容"`  //设置不能为null 长度200
+	UserID    uint   `json:"userId" gorm:"not null;index:idx_comment_user_id;comment:用户ID"` //设置不能为null 引用用户模型ID 添加一个索引
+	PostID    uint   `json:"postId" gorm:"not null;index:idx_comment_post_id;comment:文章ID"` //设置不能为null 引用文章模型ID 添加一个索引
+	Content   string `json:"content" gorm:"not null;size:200;comment:内容"`                   //设置不能为null 长度200
+	LikeCount uint64 `json:"likeCount" gorm:"not null;default:0;comment:点赞数"`               //由redis计数定时刷入 默认值为0
 
 	//用户信息
 	User User `json:"user" gorm:"foreignKey:UserID;references:ID;comment:用户"`
 }
 
-//配置表中文注释
+// 配置表中文注释
 func (c *Comment) TableComment() string {
 	return "评论表"
-}
\ No newline at end of file
+}
@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+)
+
+/**
+ * @description: 关注关系模型 FollowerID关注了FolloweeID
+ */
+type Follow struct {
+	ID         uint      `gorm:"primarykey"`
+	FollowerID uint      `json:"followerId" gorm:"not null;uniqueIndex:idx_follower_followee;comment:关注者用户ID"`                               //设置不能为null 和被关注者组成唯一索引
+	FolloweeID uint      `json:"followeeId" gorm:"not null;uniqueIndex:idx_follower_followee;index:idx_follow_followee_id;comment:被关注者用户ID"` //设置不能为null 添加索引用于查询粉丝
+	CreatedAt  time.Time `json:"createdAt"`
+
+	//关联用户信息
+	Follower User `json:"follower" gorm:"foreignKey:FollowerID;references:ID;comment:关注者"`
+	Followee User `json:"followee" gorm:"foreignKey:FolloweeID;references:ID;comment:被关注者"`
+}
+
+// 配置表中文注释
+func (f *Follow) TableComment() string {
+	return "关注表"
+}
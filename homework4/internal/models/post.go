@@ -9,10 +9,13 @@ import (
  */
 type Post struct {
 	gorm.Model
-	UserID        uint   `json:"userId" gorm:"not null;index:idx_user_id;comment:用户ID"` //设置不能为null 引用用户模型ID 添加一个索引
-	Title         string `json:"title" gorm:"not null;size:20;comment:标题"`              //设置不能为null 长度20 唯一索引
-	Content       string `json:"content" gorm:"not null;size:200;comment:内容"`           //设置不能为null 长度200
-	
+	UserID       uint   `json:"userId" gorm:"not null;index:idx_post_user_id;comment:用户ID"` //设置不能为null 引用用户模型ID 添加一个索引
+	Title        string `json:"title" gorm:"not null;size:20;comment:标题"`                   //设置不能为null 长度20 唯一索引
+	Content      string `json:"content" gorm:"not null;size:200;comment:内容"`                //设置不能为null 长度200
+	LikeCount    uint64 `json:"likeCount" gorm:"not null;default:0;comment:点赞数"`            //由redis计数定时刷入 默认值为0
+	ViewCount    uint64 `json:"viewCount" gorm:"not null;default:0;comment:浏览量"`            //同一用户/IP每天只计一次 由redis定时刷入
+	CommentCount uint32 `json:"commentCount" gorm:"not null;default:0;comment:评论数"`         //在service层和评论的增删同一事务中维护 大于0表示有评论
+
 	//关联评论模型 一对多关系 外键为PostID 引用为ID
 	Comments []Comment `json:"comments" gorm:"foreignKey:PostID;references:ID;comment:评论"`
 }
@@ -20,4 +23,4 @@ type Post struct {
 // 配置表中文注释
 func (p *Post) TableComment() string {
 	return "文章表"
-}
\ No newline at end of file
+}
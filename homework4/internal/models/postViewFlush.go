@@ -0,0 +1,15 @@
+package models
+
+/**
+ * @description: 文章每天已刷入数据库的浏览量 和文章浏览量在同一事务中更新 重复刷入时只累加差值
+ */
+type PostViewFlush struct {
+	PostID       uint   `gorm:"primaryKey;autoIncrement:false;comment:文章ID"`                         //和日期组成主键
+	Day          string `gorm:"primaryKey;size:8;index:idx_post_view_flush_day;comment:日期 yyyyMMdd"` //添加索引用于清理过期记录
+	FlushedCount int64  `gorm:"not null;default:0;comment:已刷入的浏览量"`
+}
+
+// 配置表中文注释
+func (f *PostViewFlush) TableComment() string {
+	return "浏览量刷入记录表"
+}
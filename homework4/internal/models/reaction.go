@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+)
+
+const (
+	ReactionTargetPost    = "post"    // 文章
+	ReactionTargetComment = "comment" // 评论
+
+	ReactionLike = "like" // 点赞
+)
+
+// ReactionEmojis 支持的表情回应
+var ReactionEmojis = []string{ReactionLike, "love", "haha", "wow", "sad", "angry"}
+
+/**
+ * @description: 表情回应(点赞)模型 同一用户对同一目标的同一表情只能有一条记录
+ */
+type Reaction struct {
+	ID         uint      `gorm:"primarykey"`
+	UserID     uint      `json:"userId" gorm:"not null;uniqueIndex:idx_reaction_user_target;comment:用户ID"`                                                          //设置不能为null 和目标、表情组成唯一索引
+	TargetType string    `json:"targetType" gorm:"not null;size:16;uniqueIndex:idx_reaction_user_target;index:idx_reaction_target;comment:目标类型 post-文章 comment-评论"` //设置不能为null 长度16
+	TargetID   uint      `json:"targetId" gorm:"not null;uniqueIndex:idx_reaction_user_target;index:idx_reaction_target;comment:目标ID"`                              //设置不能为null 添加目标索引用于统计
+	Emoji      string    `json:"emoji" gorm:"not null;size:16;uniqueIndex:idx_reaction_user_target;comment:表情 like-点赞"`                                             //设置不能为null 长度16
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// 配置表中文注释
+func (r *Reaction) TableComment() string {
+	return "表情回应表"
+}
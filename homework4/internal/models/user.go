@@ -15,6 +15,8 @@ type User struct {
 	Nickname  string `json:"nickname" gorm:"not null;size:64;comment:昵称"`                                       //设置不能为null 长度64
 	Password  string `json:"password" gorm:"not null;size:64;comment:登录密码(加密后的)"`                               //设置不能为null 长度64
 	Email     string `json:"email" gorm:"size:128;comment:邮箱"`
+	PostCount uint32 `json:"postCount" gorm:"not null;default:0;comment:文章数量"`    //在service层和文章的增删同一事务中维护 默认值为0
+	IsAdmin   bool   `json:"isAdmin" gorm:"not null;default:false;comment:是否管理员"` //只能通过管理命令创建 默认不是管理员
 	CreatedAt time.Time
 	UpdatedAt time.Time
 	DeletedAt soft_delete.DeletedAt `gorm:"uniqueIndex:idx_username_deleted_at"`
@@ -0,0 +1,279 @@
+package repository
+
+/**
+ * @Description: 带缓存的数据访问 包装gorm或内存实现 查询先读缓存 写操作后删除相关缓存
+ * 文章列表只缓存文章ID和总数 文章内容从文章详情缓存读取 修改文章后列表中的内容立即更新
+ */
+import (
+	"context"
+	"fmt"
+	"homework4/internal/cache"
+	"homework4/internal/models"
+	"slices"
+	"strconv"
+)
+
+// 文章列表缓存的分页结果
+type postPage struct {
+	IDs   []uint `json:"ids"`
+	Total int64  `json:"total"`
+}
+
+type cachedPostRepository struct {
+	PostRepository
+	posts *cache.Entity
+	lists *cache.Entity
+	users *cache.Entity
+}
+
+// NewCachedPostRepository 创建带缓存的文章数据访问 发布和删除文章后删除作者的用户缓存 使文章数更新
+func NewCachedPostRepository(inner PostRepository, manager *cache.Manager) PostRepository {
+	return &cachedPostRepository{
+		PostRepository: inner,
+		posts:          manager.Entity(cache.EntityPost),
+		lists:          manager.Entity(cache.EntityPostList),
+		users:          manager.Entity(cache.EntityUser),
+	}
+}
+
+func (r *cachedPostRepository) Create(ctx context.Context, post *models.Post) error {
+	if err := r.PostRepository.Create(ctx, post); err != nil {
+		return err
+	}
+	r.users.Delete(ctx, idKey(post.UserID))
+	r.lists.Flush(ctx)
+	return nil
+}
+
+func (r *cachedPostRepository) FindByID(ctx context.Context, id uint) (*models.Post, error) {
+	return cache.Get(ctx, r.posts, idKey(id), func(ctx context.Context) (*models.Post, error) {
+		return r.PostRepository.FindByID(ctx, id)
+	})
+}
+
+func (r *cachedPostRepository) FindByIDs(ctx context.Context, ids []uint) ([]models.Post, error) {
+	found, err := cache.GetMany(ctx, r.posts, ids, func(ctx context.Context, ids []uint) (map[uint]models.Post, error) {
+		posts, err := r.PostRepository.FindByIDs(ctx, ids)
+		if err != nil {
+			return nil, err
+		}
+		loaded := make(map[uint]models.Post, len(posts))
+		for _, post := range posts {
+			loaded[post.ID] = post
+		}
+		return loaded, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	posts := make([]models.Post, 0, len(found))
+	for _, id := range ids {
+		if post, ok := found[id]; ok {
+			posts = append(posts, post)
+		}
+	}
+	return posts, nil
+}
+
+func (r *cachedPostRepository) Update(ctx context.Context, id uint, updates map[string]interface{}) error {
+	if err := r.PostRepository.Update(ctx, id, updates); err != nil {
+		return err
+	}
+	r.posts.Delete(ctx, idKey(id))
+	return nil
+}
+
+func (r *cachedPostRepository) Delete(ctx context.Context, post *models.Post) error {
+	if err := r.PostRepository.Delete(ctx, post); err != nil {
+		return err
+	}
+	r.posts.Delete(ctx, idKey(post.ID))
+	r.users.Delete(ctx, idKey(post.UserID))
+	r.lists.Flush(ctx)
+	return nil
+}
+
+func (r *cachedPostRepository) BatchDelete(ctx context.Context, userID uint, ids []uint) ([]models.Post, error) {
+	posts, err := r.PostRepository.BatchDelete(ctx, userID, ids)
+	if err != nil || len(posts) == 0 {
+		return posts, err
+	}
+	keys := make([]string, len(posts))
+	userKeys := make([]string, 0, 1)
+	for i, post := range posts {
+		keys[i] = idKey(post.ID)
+		if userKey := idKey(post.UserID); !slices.Contains(userKeys, userKey) {
+			userKeys = append(userKeys, userKey)
+		}
+	}
+	r.posts.Delete(ctx, keys...)
+	r.users.Delete(ctx, userKeys...)
+	r.lists.Flush(ctx)
+	return posts, nil
+}
+
+func (r *cachedPostRepository) List(ctx context.Context, order PostOrder, offset int, limit int) ([]models.Post, int64, error) {
+	key := fmt.Sprintf("%s:%d:%d", order, offset, limit)
+	page, err := cache.Get(ctx, r.lists, key, func(ctx context.Context) (postPage, error) {
+		posts, total, err := r.PostRepository.List(ctx, order, offset, limit)
+		if err != nil {
+			return postPage{}, err
+		}
+		page := postPage{IDs: make([]uint, len(posts)), Total: total}
+		for i, post := range posts {
+			page.IDs[i] = post.ID
+		}
+		return page, nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	//按列表顺序返回 列表缓存之后删除的文章被过滤
+	posts, err := r.FindByIDs(ctx, page.IDs)
+	if err != nil {
+		return nil, 0, err
+	}
+	return posts, page.Total, nil
+}
+
+type cachedCommentRepository struct {
+	CommentRepository
+	posts *cache.Entity
+	lists *cache.Entity
+}
+
+// NewCachedCommentRepository 创建评论数据访问 评论增删后删除文章详情缓存 使评论数更新 文章列表按评论数排序 整体失效
+func NewCachedCommentRepository(inner CommentRepository, manager *cache.Manager) CommentRepository {
+	return &cachedCommentRepository{
+		CommentRepository: inner,
+		posts:             manager.Entity(cache.EntityPost),
+		lists:             manager.Entity(cache.EntityPostList),
+	}
+}
+
+func (r *cachedCommentRepository) Create(ctx context.Context, comment *models.Comment) error {
+	if err := r.CommentRepository.Create(ctx, comment); err != nil {
+		return err
+	}
+	r.posts.Delete(ctx, idKey(comment.PostID))
+	r.lists.Flush(ctx)
+	return nil
+}
+
+func (r *cachedCommentRepository) Delete(ctx context.Context, comment *models.Comment) error {
+	if err := r.CommentRepository.Delete(ctx, comment); err != nil {
+		return err
+	}
+	r.posts.Delete(ctx, idKey(comment.PostID))
+	r.lists.Flush(ctx)
+	return nil
+}
+
+func (r *cachedCommentRepository) BatchDelete(ctx context.Context, userID uint, ids []uint) (int64, []uint, error) {
+	deleted, postIDs, err := r.CommentRepository.BatchDelete(ctx, userID, ids)
+	if err != nil || len(postIDs) == 0 {
+		return deleted, postIDs, err
+	}
+	keys := make([]string, len(postIDs))
+	for i, postID := range postIDs {
+		keys[i] = idKey(postID)
+	}
+	r.posts.Delete(ctx, keys...)
+	r.lists.Flush(ctx)
+	return deleted, postIDs, nil
+}
+
+type cachedUserRepository struct {
+	UserRepository
+	users *cache.Entity
+}
+
+// NewCachedUserRepository 创建带缓存的用户数据访问 只缓存不包含密码的用户公开信息
+func NewCachedUserRepository(inner UserRepository, manager *cache.Manager) UserRepository {
+	return &cachedUserRepository{UserRepository: inner, users: manager.Entity(cache.EntityUser)}
+}
+
+func (r *cachedUserRepository) FindByIDs(ctx context.Context, ids []uint) ([]models.User, error) {
+	found, err := cache.GetMany(ctx, r.users, ids, func(ctx context.Context, ids []uint) (map[uint]models.User, error) {
+		users, err := r.UserRepository.FindByIDs(ctx, ids)
+		if err != nil {
+			return nil, err
+		}
+		loaded := make(map[uint]models.User, len(users))
+		for _, user := range users {
+			loaded[user.ID] = user
+		}
+		return loaded, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	users := make([]models.User, 0, len(found))
+	for _, user := range found {
+		users = append(users, user)
+	}
+	return users, nil
+}
+
+func (r *cachedUserRepository) Update(ctx context.Context, id uint, updates map[string]interface{}) error {
+	if err := r.UserRepository.Update(ctx, id, updates); err != nil {
+		return err
+	}
+	r.users.Delete(ctx, idKey(id))
+	return nil
+}
+
+// CounterCache 直接更新数据库中的点赞数、浏览量、评论数和文章数后删除缓存
+type CounterCache struct {
+	posts *cache.Entity
+	lists *cache.Entity
+	users *cache.Entity
+}
+
+// NewCounterCache 创建计数缓存失效 与带缓存的数据访问使用相同的缓存
+func NewCounterCache(manager *cache.Manager) *CounterCache {
+	return &CounterCache{
+		posts: manager.Entity(cache.EntityPost),
+		lists: manager.Entity(cache.EntityPostList),
+		users: manager.Entity(cache.EntityUser),
+	}
+}
+
+/**
+ * @Description: 删除文章详情缓存
+ * @param ctx
+ * @param postIDs
+ */
+func (c *CounterCache) InvalidatePosts(ctx context.Context, postIDs ...uint) {
+	c.posts.Delete(ctx, idKeys(postIDs)...)
+}
+
+/**
+ * @Description: 删除用户缓存
+ * @param ctx
+ * @param userIDs
+ */
+func (c *CounterCache) InvalidateUsers(ctx context.Context, userIDs ...uint) {
+	c.users.Delete(ctx, idKeys(userIDs)...)
+}
+
+/**
+ * @Description: 文章详情和列表缓存整体失效 用于重建全部文章的计数
+ * @param ctx
+ */
+func (c *CounterCache) FlushPosts(ctx context.Context) {
+	c.posts.Flush(ctx)
+	c.lists.Flush(ctx)
+}
+
+func idKeys(ids []uint) []string {
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = idKey(id)
+	}
+	return keys
+}
+
+func idKey(id uint) string {
+	return strconv.FormatUint(uint64(id), 10)
+}
@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+	"homework4/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type gormCommentRepository struct {
+	db *gorm.DB
+}
+
+// NewGormCommentRepository 创建基于gorm的评论数据访问
+func NewGormCommentRepository(db *gorm.DB) CommentRepository {
+	return &gormCommentRepository{db: db}
+}
+
+func (r *gormCommentRepository) Create(ctx context.Context, comment *models.Comment) error {
+	//创建评论和文章评论数在同一事务中
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(comment).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.Post{}).Where("id = ?", comment.PostID).
+			UpdateColumn("comment_count", gorm.Expr("comment_count + ?", 1)).Error
+	})
+}
+
+func (r *gormCommentRepository) FindByID(ctx context.Context, id uint) (*models.Comment, error) {
+	var comment models.Comment
+	if err := r.db.WithContext(ctx).First(&comment, id).Error; err != nil {
+		return nil, convertNotFound(err)
+	}
+	return &comment, nil
+}
+
+func (r *gormCommentRepository) Delete(ctx context.Context, comment *models.Comment) error {
+	//删除评论和文章评论数在同一事务中
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Delete(comment)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		return tx.Model(&models.Post{}).Where("id = ? AND comment_count > 0", comment.PostID).
+			UpdateColumn("comment_count", gorm.Expr("comment_count - ?", 1)).Error
+	})
+}
+
+func (r *gormCommentRepository) BatchDelete(ctx context.Context, userID uint, ids []uint) (int64, []uint, error) {
+	var deleted int64
+	var postIDs []uint
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Comment{}).Where("id IN ? AND user_id = ?", ids, userID).
+			Distinct("post_id").Pluck("post_id", &postIDs).Error; err != nil {
+			return err
+		}
+		if len(postIDs) == 0 {
+			return nil
+		}
+		result := tx.Where("id IN ? AND user_id = ?", ids, userID).Delete(&models.Comment{})
+		if result.Error != nil {
+			return result.Error
+		}
+		deleted = result.RowsAffected
+		//批量删除不会逐条处理 根据评论表重新统计涉及的文章
+		return RecountPostComments(tx, postIDs)
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+	return deleted, postIDs, nil
+}
+
+func (r *gormCommentRepository) ListByPost(ctx context.Context, postID uint, offset int, limit int) ([]models.Comment, int64, error) {
+	var comments []models.Comment
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&models.Comment{}).Where("post_id = ?", postID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if err := query.Order("created_at DESC").Offset(offset).Limit(limit).Find(&comments).Error; err != nil {
+		return nil, 0, err
+	}
+	return comments, total, nil
+}
+
+func (r *gormCommentRepository) ListByPosts(ctx context.Context, postIDs []uint, offset int, limit int) (map[uint][]models.Comment, map[uint]int64, error) {
+	grouped := make(map[uint][]models.Comment)
+	totals := make(map[uint]int64)
+	if len(postIDs) == 0 {
+		return grouped, totals, nil
+	}
+	db := r.db.WithContext(ctx)
+
+	var counts []struct {
+		PostID uint
+		Total  int64
+	}
+	if err := db.Model(&models.Comment{}).Select("post_id, COUNT(*) AS total").Where("post_id IN ?", postIDs).
+		Group("post_id").Scan(&counts).Error; err != nil {
+		return nil, nil, err
+	}
+	for _, count := range counts {
+		totals[count.PostID] = count.Total
+	}
+
+	//每篇文章内按创建时间倒序编号 取编号在分页范围内的评论 已删除的评论在子查询中过滤
+	numbered := db.Model(&models.Comment{}).
+		Select("*, ROW_NUMBER() OVER (PARTITION BY post_id ORDER BY created_at DESC, id DESC) AS row_num").
+		Where("post_id IN ?", postIDs)
+	var comments []models.Comment
+	if err := db.Unscoped().Table("(?) AS numbered", numbered).
+		Where("row_num > ? AND row_num <= ?", offset, offset+limit).
+		Order("post_id").Order("row_num").Find(&comments).Error; err != nil {
+		return nil, nil, err
+	}
+	for _, comment := range comments {
+		grouped[comment.PostID] = append(grouped[comment.PostID], comment)
+	}
+	return grouped, totals, nil
+}
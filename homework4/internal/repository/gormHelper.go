@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"homework4/internal/models"
+
+	"gorm.io/gorm"
+)
+
+/**
+ * @Description: 获取模型对应的表名 包含表名前缀
+ * @param db
+ * @param model
+ * @return string
+ */
+func TableName(db *gorm.DB, model interface{}) string {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return ""
+	}
+	return stmt.Schema.Table
+}
+
+/**
+ * @Description: 根据评论表重新统计文章评论数 用于批量删除后修正
+ * @param tx
+ * @param postIDs
+ * @return error
+ */
+func RecountPostComments(tx *gorm.DB, postIDs []uint) error {
+	if len(postIDs) == 0 {
+		return nil
+	}
+	return tx.Model(&models.Post{}).Where("id IN ?", postIDs).
+		UpdateColumn("comment_count", gorm.Expr(PostCommentCountExpr(tx))).Error
+}
+
+/**
+ * @Description: 根据文章表重新统计用户文章数 用于批量删除后修正
+ * @param tx
+ * @param userIDs
+ * @return error
+ */
+func RecountUserPosts(tx *gorm.DB, userIDs []uint) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+	return tx.Model(&models.User{}).Where("id IN ?", userIDs).
+		UpdateColumn("post_count", gorm.Expr(UserPostCountExpr(tx))).Error
+}
+
+// PostCommentCountExpr 文章实际评论数子查询
+func PostCommentCountExpr(db *gorm.DB) string {
+	commentTable := TableName(db, &models.Comment{})
+	postTable := TableName(db, &models.Post{})
+	return fmt.Sprintf("(SELECT COUNT(*) FROM %[1]s WHERE %[1]s.post_id = %[2]s.id AND %[1]s.deleted_at IS NULL)", commentTable, postTable)
+}
+
+// UserPostCountExpr 用户实际文章数子查询
+func UserPostCountExpr(db *gorm.DB) string {
+	postTable := TableName(db, &models.Post{})
+	userTable := TableName(db, &models.User{})
+	return fmt.Sprintf("(SELECT COUNT(*) FROM %[1]s WHERE %[1]s.user_id = %[2]s.id AND %[1]s.deleted_at IS NULL)", postTable, userTable)
+}
+
+// 将gorm的记录不存在错误转换为ErrNotFound
+func convertNotFound(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNotFound
+	}
+	return err
+}
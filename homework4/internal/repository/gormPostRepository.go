@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"homework4/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type gormPostRepository struct {
+	db *gorm.DB
+}
+
+// NewGormPostRepository 创建基于gorm的文章数据访问
+func NewGormPostRepository(db *gorm.DB) PostRepository {
+	return &gormPostRepository{db: db}
+}
+
+func (r *gormPostRepository) Create(ctx context.Context, post *models.Post) error {
+	//创建文章和用户文章数在同一事务中
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(post).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.User{}).Where("id = ?", post.UserID).
+			UpdateColumn("post_count", gorm.Expr("post_count + ?", 1)).Error
+	})
+}
+
+func (r *gormPostRepository) FindByID(ctx context.Context, id uint) (*models.Post, error) {
+	var post models.Post
+	if err := r.db.WithContext(ctx).First(&post, id).Error; err != nil {
+		return nil, convertNotFound(err)
+	}
+	return &post, nil
+}
+
+func (r *gormPostRepository) FindByIDs(ctx context.Context, ids []uint) ([]models.Post, error) {
+	var posts []models.Post
+	if len(ids) == 0 {
+		return posts, nil
+	}
+	if err := r.db.WithContext(ctx).Where("id IN ?", ids).Find(&posts).Error; err != nil {
+		return nil, err
+	}
+	return posts, nil
+}
+
+func (r *gormPostRepository) Update(ctx context.Context, id uint, updates map[string]interface{}) error {
+	return r.db.WithContext(ctx).Model(&models.Post{}).Where("id = ?", id).Updates(updates).Error
+}
+
+func (r *gormPostRepository) Delete(ctx context.Context, post *models.Post) error {
+	//删除文章和用户文章数在同一事务中
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Delete(post)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		return tx.Model(&models.User{}).Where("id = ? AND post_count > 0", post.UserID).
+			UpdateColumn("post_count", gorm.Expr("post_count - ?", 1)).Error
+	})
+}
+
+func (r *gormPostRepository) BatchDelete(ctx context.Context, userID uint, ids []uint) ([]models.Post, error) {
+	var posts []models.Post
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("id IN ? AND user_id = ?", ids, userID).Find(&posts).Error; err != nil {
+			return err
+		}
+		if len(posts) == 0 {
+			return nil
+		}
+		postIDs := make([]uint, len(posts))
+		for i, post := range posts {
+			postIDs[i] = post.ID
+		}
+		if err := tx.Where("id IN ?", postIDs).Delete(&models.Post{}).Error; err != nil {
+			return err
+		}
+		//批量删除不会逐条处理 根据文章表重新统计
+		return RecountUserPosts(tx, []uint{userID})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return posts, nil
+}
+
+func (r *gormPostRepository) List(ctx context.Context, order PostOrder, offset int, limit int) ([]models.Post, int64, error) {
+	var posts []models.Post
+	var total int64
+
+	db := r.db.WithContext(ctx)
+	if err := db.Model(&models.Post{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	query := db.Model(&models.Post{})
+	if order == PostOrderMostCommented {
+		query = query.Order("comment_count DESC").Order("id DESC")
+	} else {
+		query = query.Order("created_at DESC")
+	}
+	if err := query.Offset(offset).Limit(limit).Find(&posts).Error; err != nil {
+		return nil, 0, err
+	}
+	return posts, total, nil
+}
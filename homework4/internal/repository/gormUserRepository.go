@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+	"homework4/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// 用户公开信息的字段 不包含密码和邮箱
+var userPublicColumns = []string{"id", "username", "nickname", "post_count", "is_admin", "created_at", "updated_at"}
+
+type gormUserRepository struct {
+	db *gorm.DB
+}
+
+// NewGormUserRepository 创建基于gorm的用户数据访问
+func NewGormUserRepository(db *gorm.DB) UserRepository {
+	return &gormUserRepository{db: db}
+}
+
+func (r *gormUserRepository) Create(ctx context.Context, user *models.User) error {
+	return r.db.WithContext(ctx).Create(user).Error
+}
+
+func (r *gormUserRepository) FindByID(ctx context.Context, id uint) (*models.User, error) {
+	var user models.User
+	if err := r.db.WithContext(ctx).First(&user, id).Error; err != nil {
+		return nil, convertNotFound(err)
+	}
+	return &user, nil
+}
+
+func (r *gormUserRepository) FindByIDs(ctx context.Context, ids []uint) ([]models.User, error) {
+	var users []models.User
+	if len(ids) == 0 {
+		return users, nil
+	}
+	if err := r.db.WithContext(ctx).Select(userPublicColumns).Where("id IN ?", ids).Find(&users).Error; err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+func (r *gormUserRepository) FindByUsername(ctx context.Context, username string) (*models.User, error) {
+	var user models.User
+	if err := r.db.WithContext(ctx).Where(&models.User{Username: username}).First(&user).Error; err != nil {
+		return nil, convertNotFound(err)
+	}
+	return &user, nil
+}
+
+func (r *gormUserRepository) Update(ctx context.Context, id uint, updates map[string]interface{}) error {
+	return r.db.WithContext(ctx).Model(&models.User{}).Where("id = ?", id).Updates(updates).Error
+}
@@ -0,0 +1,391 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"homework4/internal/models"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrDuplicated 记录重复 内存实现中用户名已存在时返回
+var ErrDuplicated = errors.New("record duplicated")
+
+/**
+ * @Description: 内存数据存储 文章、评论、用户共享同一把锁 保证计数和记录一致 用于本地调试和测试
+ */
+type MemoryStore struct {
+	mu       sync.RWMutex
+	posts    map[uint]*models.Post
+	comments map[uint]*models.Comment
+	users    map[uint]*models.User
+	nextID   map[string]uint
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		posts:    make(map[uint]*models.Post),
+		comments: make(map[uint]*models.Comment),
+		users:    make(map[uint]*models.User),
+		nextID:   make(map[string]uint),
+	}
+}
+
+// Posts 内存文章数据访问
+func (m *MemoryStore) Posts() PostRepository {
+	return &memoryPostRepository{store: m}
+}
+
+// Comments 内存评论数据访问
+func (m *MemoryStore) Comments() CommentRepository {
+	return &memoryCommentRepository{store: m}
+}
+
+// Users 内存用户数据访问
+func (m *MemoryStore) Users() UserRepository {
+	return &memoryUserRepository{store: m}
+}
+
+// 生成自增ID 调用方需持有写锁
+func (m *MemoryStore) newID(table string) uint {
+	m.nextID[table]++
+	return m.nextID[table]
+}
+
+type memoryPostRepository struct {
+	store *MemoryStore
+}
+
+func (r *memoryPostRepository) Create(ctx context.Context, post *models.Post) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	post.ID = s.newID("post")
+	post.CreatedAt = now
+	post.UpdatedAt = now
+	stored := *post
+	s.posts[post.ID] = &stored
+	if user, ok := s.users[post.UserID]; ok {
+		user.PostCount++
+	}
+	return nil
+}
+
+func (r *memoryPostRepository) FindByID(ctx context.Context, id uint) (*models.Post, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	post, ok := s.posts[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	found := *post
+	return &found, nil
+}
+
+func (r *memoryPostRepository) FindByIDs(ctx context.Context, ids []uint) ([]models.Post, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	posts := make([]models.Post, 0, len(ids))
+	for _, id := range ids {
+		if post, ok := s.posts[id]; ok {
+			posts = append(posts, *post)
+		}
+	}
+	return posts, nil
+}
+
+func (r *memoryPostRepository) Update(ctx context.Context, id uint, updates map[string]interface{}) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	post, ok := s.posts[id]
+	if !ok {
+		return nil
+	}
+	if title, ok := updates["title"].(string); ok {
+		post.Title = title
+	}
+	if content, ok := updates["content"].(string); ok {
+		post.Content = content
+	}
+	post.UpdatedAt = time.Now()
+	return nil
+}
+
+func (r *memoryPostRepository) Delete(ctx context.Context, post *models.Post) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.deletePost(post.ID)
+	return nil
+}
+
+func (r *memoryPostRepository) BatchDelete(ctx context.Context, userID uint, ids []uint) ([]models.Post, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var posts []models.Post
+	for _, id := range ids {
+		post, ok := s.posts[id]
+		if !ok || post.UserID != userID {
+			continue
+		}
+		posts = append(posts, *post)
+		s.deletePost(id)
+	}
+	return posts, nil
+}
+
+func (r *memoryPostRepository) List(ctx context.Context, order PostOrder, offset int, limit int) ([]models.Post, int64, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	posts := make([]models.Post, 0, len(s.posts))
+	for _, post := range s.posts {
+		posts = append(posts, *post)
+	}
+	sort.Slice(posts, func(i, j int) bool {
+		if order == PostOrderMostCommented && posts[i].CommentCount != posts[j].CommentCount {
+			return posts[i].CommentCount > posts[j].CommentCount
+		}
+		return posts[i].ID > posts[j].ID
+	})
+	return paginate(posts, offset, limit), int64(len(posts)), nil
+}
+
+// 删除文章并减少作者文章数 调用方需持有写锁
+func (s *MemoryStore) deletePost(id uint) {
+	post, ok := s.posts[id]
+	if !ok {
+		return
+	}
+	delete(s.posts, id)
+	if user, ok := s.users[post.UserID]; ok && user.PostCount > 0 {
+		user.PostCount--
+	}
+}
+
+type memoryCommentRepository struct {
+	store *MemoryStore
+}
+
+func (r *memoryCommentRepository) Create(ctx context.Context, comment *models.Comment) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	comment.ID = s.newID("comment")
+	comment.CreatedAt = now
+	comment.UpdatedAt = now
+	stored := *comment
+	s.comments[comment.ID] = &stored
+	if post, ok := s.posts[comment.PostID]; ok {
+		post.CommentCount++
+	}
+	return nil
+}
+
+func (r *memoryCommentRepository) FindByID(ctx context.Context, id uint) (*models.Comment, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	comment, ok := s.comments[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	found := *comment
+	return &found, nil
+}
+
+func (r *memoryCommentRepository) Delete(ctx context.Context, comment *models.Comment) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.deleteComment(comment.ID)
+	return nil
+}
+
+func (r *memoryCommentRepository) BatchDelete(ctx context.Context, userID uint, ids []uint) (int64, []uint, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var deleted int64
+	var postIDs []uint
+	seen := make(map[uint]bool)
+	for _, id := range ids {
+		comment, ok := s.comments[id]
+		if !ok || comment.UserID != userID {
+			continue
+		}
+		if !seen[comment.PostID] {
+			seen[comment.PostID] = true
+			postIDs = append(postIDs, comment.PostID)
+		}
+		s.deleteComment(id)
+		deleted++
+	}
+	return deleted, postIDs, nil
+}
+
+func (r *memoryCommentRepository) ListByPost(ctx context.Context, postID uint, offset int, limit int) ([]models.Comment, int64, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var comments []models.Comment
+	for _, comment := range s.comments {
+		if comment.PostID != postID {
+			continue
+		}
+		comments = append(comments, *comment)
+	}
+	sort.Slice(comments, func(i, j int) bool {
+		return comments[i].ID > comments[j].ID
+	})
+	return paginate(comments, offset, limit), int64(len(comments)), nil
+}
+
+func (r *memoryCommentRepository) ListByPosts(ctx context.Context, postIDs []uint, offset int, limit int) (map[uint][]models.Comment, map[uint]int64, error) {
+	grouped := make(map[uint][]models.Comment)
+	totals := make(map[uint]int64)
+	for _, postID := range postIDs {
+		comments, total, err := r.ListByPost(ctx, postID, offset, limit)
+		if err != nil {
+			return nil, nil, err
+		}
+		if total == 0 {
+			continue
+		}
+		grouped[postID] = comments
+		totals[postID] = total
+	}
+	return grouped, totals, nil
+}
+
+// 删除评论并减少文章评论数 调用方需持有写锁
+func (s *MemoryStore) deleteComment(id uint) {
+	comment, ok := s.comments[id]
+	if !ok {
+		return
+	}
+	delete(s.comments, id)
+	if post, ok := s.posts[comment.PostID]; ok && post.CommentCount > 0 {
+		post.CommentCount--
+	}
+}
+
+type memoryUserRepository struct {
+	store *MemoryStore
+}
+
+func (r *memoryUserRepository) Create(ctx context.Context, user *models.User) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, existing := range s.users {
+		if existing.Username == user.Username {
+			return ErrDuplicated
+		}
+	}
+	now := time.Now()
+	user.ID = s.newID("user")
+	user.CreatedAt = now
+	user.UpdatedAt = now
+	stored := *user
+	s.users[user.ID] = &stored
+	return nil
+}
+
+func (r *memoryUserRepository) FindByID(ctx context.Context, id uint) (*models.User, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	user, ok := s.users[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	found := *user
+	return &found, nil
+}
+
+func (r *memoryUserRepository) FindByIDs(ctx context.Context, ids []uint) ([]models.User, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	users := make([]models.User, 0, len(ids))
+	for _, id := range ids {
+		if user, ok := s.users[id]; ok {
+			found := *user
+			found.Password = ""
+			found.Email = ""
+			users = append(users, found)
+		}
+	}
+	return users, nil
+}
+
+func (r *memoryUserRepository) FindByUsername(ctx context.Context, username string) (*models.User, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, user := range s.users {
+		if user.Username == username {
+			found := *user
+			return &found, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *memoryUserRepository) Update(ctx context.Context, id uint, updates map[string]interface{}) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, ok := s.users[id]
+	if !ok {
+		return nil
+	}
+	if password, ok := updates["password"].(string); ok {
+		user.Password = password
+	}
+	if nickname, ok := updates["nickname"].(string); ok {
+		user.Nickname = nickname
+	}
+	if isAdmin, ok := updates["is_admin"].(bool); ok {
+		user.IsAdmin = isAdmin
+	}
+	user.UpdatedAt = time.Now()
+	return nil
+}
+
+// 内存分页
+func paginate[T any](items []T, offset int, limit int) []T {
+	if offset >= len(items) {
+		return []T{}
+	}
+	end := offset + limit
+	if end > len(items) {
+		end = len(items)
+	}
+	return items[offset:end]
+}
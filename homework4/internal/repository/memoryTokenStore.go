@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type memoryToken struct {
+	token    string
+	expireAt time.Time
+}
+
+type memoryTokenStore struct {
+	mu     sync.Mutex
+	tokens map[uint]memoryToken
+}
+
+// NewMemoryTokenStore 创建内存令牌存储 用于本地调试和测试
+func NewMemoryTokenStore() TokenStore {
+	return &memoryTokenStore{tokens: make(map[uint]memoryToken)}
+}
+
+func (s *memoryTokenStore) Store(ctx context.Context, userID uint, token string, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tokens[userID] = memoryToken{token: token, expireAt: time.Now().Add(ttl)}
+	return nil
+}
+
+func (s *memoryTokenStore) Get(ctx context.Context, userID uint) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored, ok := s.tokens[userID]
+	if !ok {
+		return "", ErrNotFound
+	}
+	if time.Now().After(stored.expireAt) {
+		delete(s.tokens, userID)
+		return "", ErrNotFound
+	}
+	return stored.token, nil
+}
+
+func (s *memoryTokenStore) Delete(ctx context.Context, userID uint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.tokens, userID)
+	return nil
+}
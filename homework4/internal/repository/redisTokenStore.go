@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+type redisTokenStore struct {
+	rdb *redis.Client
+}
+
+// NewRedisTokenStore 创建基于redis的令牌存储 key为token:{userID}
+func NewRedisTokenStore(rdb *redis.Client) TokenStore {
+	return &redisTokenStore{rdb: rdb}
+}
+
+func (s *redisTokenStore) Store(ctx context.Context, userID uint, token string, ttl time.Duration) error {
+	return s.rdb.Set(ctx, tokenKey(userID), token, ttl).Err()
+}
+
+func (s *redisTokenStore) Get(ctx context.Context, userID uint) (string, error) {
+	token, err := s.rdb.Get(ctx, tokenKey(userID)).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", ErrNotFound
+	}
+	return token, err
+}
+
+func (s *redisTokenStore) Delete(ctx context.Context, userID uint) error {
+	return s.rdb.Del(ctx, tokenKey(userID)).Err()
+}
+
+func tokenKey(userID uint) string {
+	return fmt.Sprintf("token:%d", userID)
+}
@@ -0,0 +1,81 @@
+package repository
+
+/**
+ * @Description: 数据访问接口 文章、评论、用户service只依赖接口 由gorm或内存实现
+ * 点赞、浏览量、热门、动态、关注、收藏和计数核对依赖redis数据结构和SQL统计 直接使用gorm和redis 不在这里定义接口
+ */
+import (
+	"context"
+	"errors"
+	"homework4/internal/models"
+	"time"
+)
+
+// ErrNotFound 记录不存在
+var ErrNotFound = errors.New("record not found")
+
+// PostOrder 文章列表排序
+type PostOrder string
+
+const (
+	PostOrderLatest        PostOrder = "latest"         // 按发布时间倒序
+	PostOrderMostCommented PostOrder = "most_commented" // 按评论数倒序
+)
+
+// PostRepository 文章数据访问
+type PostRepository interface {
+	// Create 创建文章 同时增加作者的文章数
+	Create(ctx context.Context, post *models.Post) error
+	// FindByID 根据ID查询文章 不存在时返回ErrNotFound
+	FindByID(ctx context.Context, id uint) (*models.Post, error)
+	// FindByIDs 根据ID批量查询文章 已删除的文章不返回 不保证顺序
+	FindByIDs(ctx context.Context, ids []uint) ([]models.Post, error)
+	// Update 更新文章字段
+	Update(ctx context.Context, id uint, updates map[string]interface{}) error
+	// Delete 删除文章 同时减少作者的文章数
+	Delete(ctx context.Context, post *models.Post) error
+	// BatchDelete 批量删除用户自己的文章 重新统计作者的文章数 返回被删除的文章
+	BatchDelete(ctx context.Context, userID uint, ids []uint) ([]models.Post, error)
+	// List 分页查询文章
+	List(ctx context.Context, order PostOrder, offset int, limit int) ([]models.Post, int64, error)
+}
+
+// CommentRepository 评论数据访问
+type CommentRepository interface {
+	// Create 创建评论 同时增加文章的评论数
+	Create(ctx context.Context, comment *models.Comment) error
+	// FindByID 根据ID查询评论 不存在时返回ErrNotFound
+	FindByID(ctx context.Context, id uint) (*models.Comment, error)
+	// Delete 删除评论 同时减少文章的评论数
+	Delete(ctx context.Context, comment *models.Comment) error
+	// BatchDelete 批量删除用户自己的评论 重新统计涉及文章的评论数 返回删除数量和涉及的文章ID
+	BatchDelete(ctx context.Context, userID uint, ids []uint) (int64, []uint, error)
+	// ListByPost 分页查询文章的评论 按创建时间倒序 不包含评论用户信息 由UserRepository.FindByIDs查询
+	ListByPost(ctx context.Context, postID uint, offset int, limit int) ([]models.Comment, int64, error)
+	// ListByPosts 一次查询多篇文章相同分页的评论 返回按文章ID分组的评论和评论总数 没有评论的文章不在结果中
+	ListByPosts(ctx context.Context, postIDs []uint, offset int, limit int) (map[uint][]models.Comment, map[uint]int64, error)
+}
+
+// UserRepository 用户数据访问
+type UserRepository interface {
+	// Create 创建用户
+	Create(ctx context.Context, user *models.User) error
+	// FindByID 根据ID查询用户 不存在时返回ErrNotFound
+	FindByID(ctx context.Context, id uint) (*models.User, error)
+	// FindByIDs 根据ID批量查询用户公开信息 不包含密码和邮箱 不存在的用户不返回 不保证顺序
+	FindByIDs(ctx context.Context, ids []uint) ([]models.User, error)
+	// FindByUsername 根据用户名查询用户 不存在时返回ErrNotFound
+	FindByUsername(ctx context.Context, username string) (*models.User, error)
+	// Update 更新用户字段
+	Update(ctx context.Context, id uint, updates map[string]interface{}) error
+}
+
+// TokenStore 登录令牌存储 每个用户只保留最新的令牌
+type TokenStore interface {
+	// Store 保存用户令牌 超过ttl后失效
+	Store(ctx context.Context, userID uint, token string, ttl time.Duration) error
+	// Get 获取用户令牌 不存在或已失效时返回ErrNotFound
+	Get(ctx context.Context, userID uint) (string, error)
+	// Delete 删除用户令牌
+	Delete(ctx context.Context, userID uint) error
+}
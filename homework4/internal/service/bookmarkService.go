@@ -0,0 +1,437 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"homework4/internal/errcode"
+	"homework4/internal/models"
+	"homework4/internal/repository"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type BookmarkService struct {
+	db *gorm.DB
+}
+
+func NewBookmarkService(db *gorm.DB) *BookmarkService {
+	return &BookmarkService{db: db}
+}
+
+// AddBookmarkRequest 添加收藏请求
+type AddBookmarkRequest struct {
+	PostID uint `json:"postId" binding:"required" example:"1"`  // 文章ID
+	ListID uint `json:"listId" binding:"omitempty" example:"0"` // 阅读清单ID 不传为稍后阅读
+}
+
+// RemoveBookmarkRequest 取消收藏请求
+type RemoveBookmarkRequest struct {
+	PostID uint `json:"postId" binding:"required" example:"1"`  // 文章ID
+	ListID uint `json:"listId" binding:"omitempty" example:"0"` // 阅读清单ID 不传为稍后阅读
+}
+
+// ReorderBookmarkRequest 调整收藏顺序请求
+type ReorderBookmarkRequest struct {
+	ListID  uint   `json:"listId" binding:"omitempty" example:"0"`                   // 阅读清单ID 不传为稍后阅读
+	PostIDs []uint `json:"postIds" binding:"required,min=1,max=200" example:"3,1,2"` // 调整后的文章ID顺序 靠前的排在前面
+}
+
+// GetBookmarkListRequest 获取收藏列表请求
+type GetBookmarkListRequest struct {
+	ListID uint   `form:"listId" binding:"omitempty" example:"0"`               // 阅读清单ID 不传为稍后阅读
+	Cursor string `form:"cursor" binding:"omitempty" example:""`                // 游标 不传从第一条开始
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=100" example:"10"` // 每页数量
+}
+
+// CreateReadingListRequest 创建阅读清单请求
+type CreateReadingListRequest struct {
+	Name        string `json:"name" binding:"required,min=1,max=32,banned" example:"Go学习"`      // 清单名称
+	Description string `json:"description" binding:"omitempty,max=200,banned" example:"Go相关文章"` // 清单描述
+	IsPublic    bool   `json:"isPublic" example:"false"`                                        // 是否公开
+}
+
+// UpdateReadingListRequest 更新阅读清单请求
+type UpdateReadingListRequest struct {
+	ListID      uint    `json:"listId" binding:"required" example:"1"`                           // 阅读清单ID
+	Name        string  `json:"name" binding:"omitempty,min=1,max=32,banned" example:"Go进阶"`     // 清单名称
+	Description *string `json:"description" binding:"omitempty,max=200,banned" example:"Go进阶文章"` // 清单描述
+	IsPublic    *bool   `json:"isPublic" example:"true"`                                         // 是否公开
+}
+
+// DeleteReadingListRequest 删除阅读清单请求
+type DeleteReadingListRequest struct {
+	ListID uint `json:"listId" binding:"required" example:"1"` // 阅读清单ID
+}
+
+// ReadingListResponse 阅读清单响应
+type ReadingListResponse struct {
+	ID          uint   `json:"id" example:"1"`                          // 阅读清单ID
+	UserID      uint   `json:"userId" example:"1"`                      // 用户ID
+	Name        string `json:"name" example:"Go学习"`                     // 清单名称
+	Description string `json:"description" example:"Go相关文章"`            // 清单描述
+	IsPublic    bool   `json:"isPublic" example:"false"`                // 是否公开
+	CreatedAt   string `json:"createdAt" example:"2024-01-01 12:00:00"` // 创建时间
+}
+
+// BookmarkResponse 收藏响应
+type BookmarkResponse struct {
+	ID        uint   `json:"id" example:"1"`                          // 收藏ID
+	ListID    uint   `json:"listId" example:"0"`                      // 阅读清单ID
+	PostID    uint   `json:"postId" example:"1"`                      // 文章ID
+	Available bool   `json:"available" example:"true"`                // 文章是否可用 文章删除后为false
+	Title     string `json:"title" example:"我的第一篇文章"`                 // 文章标题 文章不可用时为空
+	AuthorID  uint   `json:"authorId" example:"1"`                    // 文章作者ID 文章不可用时为0
+	CreatedAt string `json:"createdAt" example:"2024-01-01 12:00:00"` // 收藏时间
+}
+
+// BookmarkPageResponse 收藏游标分页响应
+type BookmarkPageResponse struct {
+	List       []BookmarkResponse `json:"list"`                        // 收藏列表
+	NextCursor string             `json:"nextCursor" example:"MTA6NQ"` // 下一页游标 没有下一页时为空
+	HasMore    bool               `json:"hasMore" example:"false"`     // 是否还有下一页
+}
+
+/**
+ * @Description: 添加收藏 重复收藏直接返回
+ * @param ctx
+ * @param req
+ * @param userID
+ * @return error
+ */
+func (s *BookmarkService) AddBookmark(ctx context.Context, req *AddBookmarkRequest, userID uint) error {
+	if err := s.checkOwnList(ctx, req.ListID, userID); err != nil {
+		return err
+	}
+	var post models.Post
+	if err := s.db.WithContext(ctx).Select("id").First(&post, req.PostID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errcode.ErrPostNotFound
+		}
+		return err
+	}
+
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		//新收藏排在最前面
+		var maxPosition int64
+		if err := tx.Model(&models.Bookmark{}).
+			Where("user_id = ? AND reading_list_id = ?", userID, req.ListID).
+			Select("COALESCE(MAX(position), 0)").Scan(&maxPosition).Error; err != nil {
+			return err
+		}
+		bookmark := &models.Bookmark{
+			UserID:        userID,
+			ReadingListID: req.ListID,
+			PostID:        req.PostID,
+			Position:      maxPosition + 1,
+		}
+		//唯一索引冲突时不做处理 保证幂等
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(bookmark).Error
+	})
+}
+
+/**
+ * @Description: 取消收藏 未收藏时直接返回
+ * @param ctx
+ * @param req
+ * @param userID
+ * @return error
+ */
+func (s *BookmarkService) RemoveBookmark(ctx context.Context, req *RemoveBookmarkRequest, userID uint) error {
+	return s.db.WithContext(ctx).Where("user_id = ? AND reading_list_id = ? AND post_id = ?", userID, req.ListID, req.PostID).
+		Delete(&models.Bookmark{}).Error
+}
+
+/**
+ * @Description: 调整收藏顺序 只调整传入的文章 其余收藏位置不变
+ * @param ctx
+ * @param req
+ * @param userID
+ * @return error
+ */
+func (s *BookmarkService) ReorderBookmarks(ctx context.Context, req *ReorderBookmarkRequest, userID uint) error {
+	if err := s.checkOwnList(ctx, req.ListID, userID); err != nil {
+		return err
+	}
+
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var bookmarks []models.Bookmark
+		if err := tx.Where("user_id = ? AND reading_list_id = ? AND post_id IN ?", userID, req.ListID, req.PostIDs).
+			Find(&bookmarks).Error; err != nil {
+			return err
+		}
+		if len(bookmarks) != len(req.PostIDs) {
+			return errcode.ErrBookmarkMismatch
+		}
+
+		//复用这些收藏原有的位置 按请求顺序从大到小重新分配
+		positions := make([]int64, len(bookmarks))
+		bookmarkIDs := make(map[uint]uint, len(bookmarks))
+		for i, bookmark := range bookmarks {
+			positions[i] = bookmark.Position
+			bookmarkIDs[bookmark.PostID] = bookmark.ID
+		}
+		sort.Slice(positions, func(i, j int) bool { return positions[i] > positions[j] })
+
+		for i, postID := range req.PostIDs {
+			if err := tx.Model(&models.Bookmark{}).Where("id = ?", bookmarkIDs[postID]).
+				UpdateColumn("position", positions[i]).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+/**
+ * @Description: 游标分页获取自己的收藏
+ * @param ctx
+ * @param req
+ * @param userID
+ * @return (*BookmarkPageResponse, error)
+ */
+func (s *BookmarkService) GetBookmarkList(ctx context.Context, req *GetBookmarkListRequest, userID uint) (*BookmarkPageResponse, error) {
+	if err := s.checkOwnList(ctx, req.ListID, userID); err != nil {
+		return nil, err
+	}
+	return s.pageBookmarks(ctx, userID, req)
+}
+
+/**
+ * @Description: 游标分页获取阅读清单中的收藏 非公开清单只有自己可以查看
+ * @param ctx
+ * @param req
+ * @param userID 当前登录用户ID 未登录为0
+ * @return (*BookmarkPageResponse, error)
+ */
+func (s *BookmarkService) GetReadingListBookmarks(ctx context.Context, req *GetBookmarkListRequest, userID uint) (*BookmarkPageResponse, error) {
+	var list models.ReadingList
+	if err := s.db.WithContext(ctx).First(&list, req.ListID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errcode.ErrReadingListNotFound
+		}
+		return nil, err
+	}
+	if !list.IsPublic && list.UserID != userID {
+		return nil, errcode.ErrReadingListNotFound
+	}
+	return s.pageBookmarks(ctx, list.UserID, req)
+}
+
+/**
+ * @Description: 创建阅读清单
+ * @param ctx
+ * @param req
+ * @param userID
+ * @return (*ReadingListResponse, error)
+ */
+func (s *BookmarkService) CreateReadingList(ctx context.Context, req *CreateReadingListRequest, userID uint) (*ReadingListResponse, error) {
+	var count int64
+	if err := s.db.WithContext(ctx).Model(&models.ReadingList{}).Where("user_id = ? AND name = ?", userID, req.Name).
+		Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return nil, errcode.ErrReadingListNameExists
+	}
+
+	list := &models.ReadingList{
+		UserID:      userID,
+		Name:        req.Name,
+		Description: req.Description,
+		IsPublic:    req.IsPublic,
+	}
+	if err := s.db.WithContext(ctx).Create(list).Error; err != nil {
+		return nil, err
+	}
+	return toReadingListResponse(list), nil
+}
+
+/**
+ * @Description: 更新阅读清单
+ * @param ctx
+ * @param req
+ * @param userID
+ * @return (bool, error)
+ */
+func (s *BookmarkService) UpdateReadingList(ctx context.Context, req *UpdateReadingListRequest, userID uint) (bool, error) {
+	if err := s.checkOwnList(ctx, req.ListID, userID); err != nil {
+		return false, err
+	}
+
+	updates := make(map[string]interface{})
+	if req.Name != "" {
+		var count int64
+		if err := s.db.WithContext(ctx).Model(&models.ReadingList{}).Where("user_id = ? AND name = ? AND id != ?", userID, req.Name, req.ListID).
+			Count(&count).Error; err != nil {
+			return false, err
+		}
+		if count > 0 {
+			return false, errcode.ErrReadingListNameExists
+		}
+		updates["name"] = req.Name
+	}
+	if req.Description != nil {
+		updates["description"] = *req.Description
+	}
+	if req.IsPublic != nil {
+		updates["is_public"] = *req.IsPublic
+	}
+
+	if len(updates) == 0 {
+		return false, nil
+	}
+	if err := s.db.WithContext(ctx).Model(&models.ReadingList{}).Where("id = ?", req.ListID).Updates(updates).Error; err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+/**
+ * @Description: 删除阅读清单 同时删除清单中的收藏
+ * @param ctx
+ * @param req
+ * @param userID
+ * @return error
+ */
+func (s *BookmarkService) DeleteReadingList(ctx context.Context, req *DeleteReadingListRequest, userID uint) error {
+	if err := s.checkOwnList(ctx, req.ListID, userID); err != nil {
+		return err
+	}
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ? AND reading_list_id = ?", userID, req.ListID).
+			Delete(&models.Bookmark{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.ReadingList{}, req.ListID).Error
+	})
+}
+
+/**
+ * @Description: 获取自己的阅读清单
+ * @param ctx
+ * @param userID
+ * @return ([]ReadingListResponse, error)
+ */
+func (s *BookmarkService) GetMyReadingLists(ctx context.Context, userID uint) ([]ReadingListResponse, error) {
+	var lists []models.ReadingList
+	if err := s.db.WithContext(ctx).Where("user_id = ?", userID).Order("created_at DESC").Find(&lists).Error; err != nil {
+		return nil, err
+	}
+	listResponses := make([]ReadingListResponse, len(lists))
+	for i := range lists {
+		listResponses[i] = *toReadingListResponse(&lists[i])
+	}
+	return listResponses, nil
+}
+
+// 检查阅读清单是否属于当前用户 0为稍后阅读
+func (s *BookmarkService) checkOwnList(ctx context.Context, listID uint, userID uint) error {
+	if listID == 0 {
+		return nil
+	}
+	var list models.ReadingList
+	if err := s.db.WithContext(ctx).Select("id", "user_id").First(&list, listID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errcode.ErrReadingListNotFound
+		}
+		return err
+	}
+	if list.UserID != userID {
+		return errcode.ErrReadingListForbidden
+	}
+	return nil
+}
+
+// 按位置倒序游标分页 关联文章表时包含已删除的文章 已删除的文章标记为不可用
+func (s *BookmarkService) pageBookmarks(ctx context.Context, userID uint, req *GetBookmarkListRequest) (*BookmarkPageResponse, error) {
+	limit := 10
+	if req.Limit > 0 {
+		limit = req.Limit
+	}
+
+	type bookmarkRow struct {
+		models.Bookmark
+		PostTitle     *string
+		PostUserID    *uint
+		PostDeletedAt *time.Time
+	}
+
+	bookmarkTable := repository.TableName(s.db, &models.Bookmark{})
+	postTable := repository.TableName(s.db, &models.Post{})
+	query := s.db.WithContext(ctx).Model(&models.Bookmark{}).
+		Select(fmt.Sprintf("%[1]s.*, %[2]s.title AS post_title, %[2]s.user_id AS post_user_id, %[2]s.deleted_at AS post_deleted_at", bookmarkTable, postTable)).
+		Joins(fmt.Sprintf("LEFT JOIN %[2]s ON %[2]s.id = %[1]s.post_id", bookmarkTable, postTable)).
+		Where(bookmarkTable+".user_id = ? AND "+bookmarkTable+".reading_list_id = ?", userID, req.ListID)
+
+	if req.Cursor != "" {
+		position, id, err := decodeBookmarkCursor(req.Cursor)
+		if err != nil {
+			return nil, errcode.ErrInvalidCursor
+		}
+		query = query.Where(fmt.Sprintf("(%[1]s.position < ? OR (%[1]s.position = ? AND %[1]s.id < ?))", bookmarkTable),
+			position, position, id)
+	}
+
+	var rows []bookmarkRow
+	if err := query.Order(bookmarkTable + ".position DESC").Order(bookmarkTable + ".id DESC").
+		Limit(limit + 1).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	page := &BookmarkPageResponse{List: make([]BookmarkResponse, 0, limit)}
+	if len(rows) > limit {
+		rows = rows[:limit]
+		page.HasMore = true
+		last := rows[len(rows)-1]
+		page.NextCursor = encodeBookmarkCursor(last.Position, last.ID)
+	}
+	for _, row := range rows {
+		bookmark := BookmarkResponse{
+			ID:        row.ID,
+			ListID:    row.ReadingListID,
+			PostID:    row.PostID,
+			CreatedAt: row.CreatedAt.Format(time.DateTime),
+		}
+		if row.PostTitle != nil && row.PostDeletedAt == nil {
+			bookmark.Available = true
+			bookmark.Title = *row.PostTitle
+			bookmark.AuthorID = *row.PostUserID
+		}
+		page.List = append(page.List, bookmark)
+	}
+	return page, nil
+}
+
+func toReadingListResponse(list *models.ReadingList) *ReadingListResponse {
+	return &ReadingListResponse{
+		ID:          list.ID,
+		UserID:      list.UserID,
+		Name:        list.Name,
+		Description: list.Description,
+		IsPublic:    list.IsPublic,
+		CreatedAt:   list.CreatedAt.Format(time.DateTime),
+	}
+}
+
+// 游标格式为 {position}:{id} 的base64编码
+func encodeBookmarkCursor(position int64, id uint) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%d:%d", position, id)))
+}
+
+func decodeBookmarkCursor(cursor string) (int64, uint, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, 0, err
+	}
+	var position int64
+	var id uint
+	if _, err := fmt.Sscanf(string(raw), "%d:%d", &position, &id); err != nil {
+		return 0, 0, err
+	}
+	return position, id, nil
+}
@@ -1,25 +1,29 @@
 package service
 
 import (
+	"context"
 	"errors"
-	"homework4/internal/app/mysql"
+	"homework4/internal/errcode"
+	"homework4/internal/metrics"
 	"homework4/internal/models"
-
-	"gorm.io/gorm"
+	"homework4/internal/repository"
 )
 
 type CommentService struct {
-	db *gorm.DB
+	comments  repository.CommentRepository
+	posts     repository.PostRepository
+	users     repository.UserRepository
+	reactions ReactionSummarizer
 }
 
-func NewCommentService() *CommentService {
-	return &CommentService{db: mysql.DB}
+func NewCommentService(comments repository.CommentRepository, posts repository.PostRepository, users repository.UserRepository, reactions ReactionSummarizer) *CommentService {
+	return &CommentService{comments: comments, posts: posts, users: users, reactions: reactions}
 }
 
 // CreateCommentRequest 创建评论请求
 type CreateCommentRequest struct {
-	PostID  uint   `json:"postId" binding:"required" example:"1"`                     // 文章ID
-	Content string `json:"content" binding:"required,min=1,max=200" example:"很棒的文章!"` // 评论内容
+	PostID  uint   `json:"postId" binding:"required" example:"1"`                            // 文章ID
+	Content string `json:"content" binding:"required,min=1,max=200,banned" example:"很棒的文章!"` // 评论内容
 }
 
 // GetCommentListRequest 获取评论列表请求
@@ -29,6 +33,32 @@ type GetCommentListRequest struct {
 	PageSize int  `form:"pageSize" binding:"omitempty,min=1,max=100" example:"10"` // 每页数量
 }
 
+// CreatePostCommentRequest 在路径中的文章下创建评论请求
+type CreatePostCommentRequest struct {
+	Content string `json:"content" binding:"required,min=1,max=200,banned" example:"很棒的文章!"` // 评论内容
+}
+
+// GetPostCommentsRequest 获取路径中文章的评论请求
+type GetPostCommentsRequest struct {
+	Page     int `form:"page" binding:"omitempty,min=1" example:"1"`              // 页码
+	PageSize int `form:"pageSize" binding:"omitempty,min=1,max=100" example:"10"` // 每页数量
+}
+
+// CommentIDRequest 路径中的评论ID
+type CommentIDRequest struct {
+	CommentID uint `uri:"id" binding:"required" example:"1"` // 评论ID
+}
+
+// DeleteCommentRequest 删除评论请求
+type DeleteCommentRequest struct {
+	CommentID uint `json:"commentId" binding:"required" example:"1"` // 评论ID
+}
+
+// BatchDeleteCommentRequest 批量删除评论请求
+type BatchDeleteCommentRequest struct {
+	CommentIDs []uint `json:"commentIds" binding:"required,min=1,max=100" example:"1,2"` // 评论ID列表
+}
+
 // CommentResponse 评论响应
 type CommentResponse struct {
 	ID        uint   `json:"id" example:"1"`                          // 评论ID
@@ -47,19 +77,23 @@ type CommentWithUserResponse struct {
 	Nickname  string `json:"nickname" example:"测试用户"`                 // 昵称
 	Content   string `json:"content" example:"很棒的文章!"`                // 评论内容
 	CreatedAt string `json:"createdAt" example:"2024-01-01 12:00:00"` // 创建时间
+
+	LikeCount int64            `json:"likeCount" example:"10"` // 点赞数
+	Liked     bool             `json:"liked" example:"false"`  // 当前用户是否点赞 未登录为false
+	Reactions map[string]int64 `json:"reactions"`              // 各表情回应数
 }
 
 /**
  * @Description: 创建评论
+ * @param ctx
  * @param req
  * @param userID
  * @return (*CommentResponse, error)
  */
-func (s *CommentService) CreateComment(req *CreateCommentRequest, userID uint) (*CommentResponse, error) {
-	var post models.Post
-	if err := s.db.First(&post, req.PostID).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("文章不存在")
+func (s *CommentService) CreateComment(ctx context.Context, req *CreateCommentRequest, userID uint) (*CommentResponse, error) {
+	if _, err := s.posts.FindByID(ctx, req.PostID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, errcode.ErrPostNotFound
 		}
 		return nil, err
 	}
@@ -70,9 +104,10 @@ func (s *CommentService) CreateComment(req *CreateCommentRequest, userID uint) (
 		Content: req.Content,
 	}
 
-	if err := s.db.Create(comment).Error; err != nil {
+	if err := s.comments.Create(ctx, comment); err != nil {
 		return nil, err
 	}
+	metrics.CommentsCreated.Inc()
 
 	return &CommentResponse{
 		ID:        comment.ID,
@@ -83,12 +118,58 @@ func (s *CommentService) CreateComment(req *CreateCommentRequest, userID uint) (
 	}, nil
 }
 
+/**
+ * @Description: 删除评论 评论作者、文章作者和管理员可以删除
+ * @param ctx
+ * @param req
+ * @param userID
+ * @return error
+ */
+func (s *CommentService) DeleteComment(ctx context.Context, req *DeleteCommentRequest, userID uint) error {
+	comment, err := s.comments.FindByID(ctx, req.CommentID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return errcode.ErrCommentNotFound
+		}
+		return err
+	}
+
+	if comment.UserID != userID {
+		post, err := s.posts.FindByID(ctx, comment.PostID)
+		if err != nil || post.UserID != userID {
+			admin, err := isAdmin(ctx, s.users, userID)
+			if err != nil {
+				return err
+			}
+			if !admin {
+				return errcode.ErrCommentDeleteForbidden
+			}
+		}
+	}
+
+	return s.comments.Delete(ctx, comment)
+}
+
+/**
+ * @Description: 批量删除自己的评论 不属于自己或不存在的评论会被忽略
+ * @param ctx
+ * @param req
+ * @param userID
+ * @return (int64, error) 删除的评论数
+ */
+func (s *CommentService) BatchDeleteComments(ctx context.Context, req *BatchDeleteCommentRequest, userID uint) (int64, error) {
+	deleted, _, err := s.comments.BatchDelete(ctx, userID, req.CommentIDs)
+	return deleted, err
+}
+
 /**
  * @Description: 获取文章评论分页
+ * @param ctx 请求上下文 用于链路追踪
  * @param req
+ * @param userID 当前登录用户ID 未登录为0
  * @return ([]CommentWithUserResponse, int64, error)
  */
-func (s *CommentService) GetCommentList(req *GetCommentListRequest) ([]CommentWithUserResponse, int64, error) {
+func (s *CommentService) GetCommentList(ctx context.Context, req *GetCommentListRequest, userID uint) ([]CommentWithUserResponse, int64, error) {
 	page := 1
 	pageSize := 10
 
@@ -99,32 +180,125 @@ func (s *CommentService) GetCommentList(req *GetCommentListRequest) ([]CommentWi
 		pageSize = req.PageSize
 	}
 
+	offset := (page - 1) * pageSize
+	comments, total, err := s.comments.ListByPost(ctx, req.PostID, offset, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+	commentResponses, err := s.withUsers(ctx, comments, userID)
+	if err != nil {
+		return nil, 0, err
+	}
+	return commentResponses, total, nil
+}
+
+// CommentPage 一篇文章的评论分页
+type CommentPage struct {
+	List  []CommentWithUserResponse
+	Total int64
+}
+
+/**
+ * @Description: 一次获取多篇文章相同分页的评论 评论用户和表情回应也一起查询
+ * @param ctx
+ * @param postIDs
+ * @param page 页码 为0时取第1页
+ * @param pageSize 每页数量 为0时取10
+ * @param userID 当前登录用户ID 未登录为0
+ * @return (map[uint]CommentPage, error) 没有评论的文章不在结果中
+ */
+func (s *CommentService) GetCommentPages(ctx context.Context, postIDs []uint, page int, pageSize int, userID uint) (map[uint]CommentPage, error) {
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = 10
+	}
+
+	grouped, totals, err := s.comments.ListByPosts(ctx, postIDs, (page-1)*pageSize, pageSize)
+	if err != nil {
+		return nil, err
+	}
 	var comments []models.Comment
-	var total int64
+	for _, postID := range postIDs {
+		comments = append(comments, grouped[postID]...)
+	}
+	commentResponses, err := s.withUsers(ctx, comments, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	pages := make(map[uint]CommentPage, len(totals))
+	for postID, total := range totals {
+		pages[postID] = CommentPage{Total: total, List: []CommentWithUserResponse{}}
+	}
+	for _, comment := range commentResponses {
+		commentPage := pages[comment.PostID]
+		commentPage.List = append(commentPage.List, comment)
+		pages[comment.PostID] = commentPage
+	}
+	return pages, nil
+}
 
-	query := s.db.Model(&models.Comment{}).Where("post_id = ?", req.PostID)
+/**
+ * @Description: 获取单条评论
+ * @param ctx
+ * @param commentID
+ * @param userID 当前登录用户ID 未登录为0
+ * @return (*CommentWithUserResponse, error)
+ */
+func (s *CommentService) GetComment(ctx context.Context, commentID uint, userID uint) (*CommentWithUserResponse, error) {
+	comment, err := s.comments.FindByID(ctx, commentID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, errcode.ErrCommentNotFound
+		}
+		return nil, err
+	}
+	commentResponses, err := s.withUsers(ctx, []models.Comment{*comment}, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &commentResponses[0], nil
+}
 
-	if err := query.Count(&total).Error; err != nil {
-		return nil, 0, err
+// 查询评论用户和表情回应 转换为评论响应
+func (s *CommentService) withUsers(ctx context.Context, comments []models.Comment, userID uint) ([]CommentWithUserResponse, error) {
+	likeCounts := make(map[uint]uint64, len(comments))
+	userIDs := make([]uint, 0, len(comments))
+	for _, comment := range comments {
+		likeCounts[comment.ID] = comment.LikeCount
+		userIDs = append(userIDs, comment.UserID)
 	}
+	summaries := s.reactions.GetSummaries(ctx, models.ReactionTargetComment, likeCounts, userID)
 
-	offset := (page - 1) * pageSize
-	if err := query.Preload("User").Order("created_at DESC").Offset(offset).Limit(pageSize).Find(&comments).Error; err != nil {
-		return nil, 0, err
+	//评论用户信息走用户缓存 已删除的用户名和昵称为空
+	users, err := s.users.FindByIDs(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	userMap := make(map[uint]models.User, len(users))
+	for _, user := range users {
+		userMap[user.ID] = user
 	}
 
 	commentResponses := make([]CommentWithUserResponse, len(comments))
 	for i, comment := range comments {
+		summary := summaries[comment.ID]
+		user := userMap[comment.UserID]
 		commentResponses[i] = CommentWithUserResponse{
 			ID:        comment.ID,
 			PostID:    comment.PostID,
 			UserID:    comment.UserID,
-			Username:  comment.User.Username,
-			Nickname:  comment.User.Nickname,
+			Username:  user.Username,
+			Nickname:  user.Nickname,
 			Content:   comment.Content,
 			CreatedAt: comment.CreatedAt.Format("2006-01-02 15:04:05"),
+			LikeCount: summary.LikeCount,
+			Liked:     summary.Liked,
+			Reactions: summary.Reactions,
 		}
 	}
 
-	return commentResponses, total, nil
+	return commentResponses, nil
 }
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"homework4/internal/models"
+	"homework4/internal/repository"
+	"reflect"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func newCommentTestService(t *testing.T) (*CommentService, *gorm.DB) {
+	t.Helper()
+	db, rdb, _ := newTestStores(t)
+	reactions := NewReactionService(db, rdb, &recordingCounterCache{})
+	return NewCommentService(repository.NewGormCommentRepository(db), repository.NewGormPostRepository(db),
+		repository.NewGormUserRepository(db), reactions), db
+}
+
+// 按顺序创建评论 后创建的评论时间更晚
+func createTestComments(t *testing.T, db *gorm.DB, postID uint, count int) []models.Comment {
+	t.Helper()
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	comments := make([]models.Comment, count)
+	for i := range comments {
+		comments[i] = models.Comment{UserID: 1, PostID: postID, Content: "评论"}
+		comments[i].CreatedAt = base.Add(time.Duration(i) * time.Minute)
+		if err := db.Create(&comments[i]).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	return comments
+}
+
+func commentIDs(comments []CommentWithUserResponse) []uint {
+	ids := make([]uint, len(comments))
+	for i, comment := range comments {
+		ids[i] = comment.ID
+	}
+	return ids
+}
+
+func TestGetCommentPagesMatchesCommentList(t *testing.T) {
+	s, db := newCommentTestService(t)
+	ctx := context.Background()
+	first := createTestPost(t, db, 0)
+	second := createTestPost(t, db, 0)
+	empty := createTestPost(t, db, 0)
+	created := createTestComments(t, db, first.ID, 4)
+	createTestComments(t, db, second.ID, 1)
+	//已删除的评论不计入
+	if err := db.Delete(&created[3]).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	postIDs := []uint{first.ID, second.ID, empty.ID}
+	for _, page := range []int{1, 2, 3} {
+		pages, err := s.GetCommentPages(ctx, postIDs, page, 2, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := pages[empty.ID]; ok {
+			t.Errorf("page %d 没有评论的文章在结果中", page)
+		}
+		for _, postID := range []uint{first.ID, second.ID} {
+			list, total, err := s.GetCommentList(ctx, &GetCommentListRequest{PostID: postID, Page: page, PageSize: 2}, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := pages[postID]
+			if got.Total != total {
+				t.Errorf("page %d post %d total = %d, want %d", page, postID, got.Total, total)
+			}
+			if !reflect.DeepEqual(commentIDs(got.List), commentIDs(list)) {
+				t.Errorf("page %d post %d ids = %v, want %v", page, postID, commentIDs(got.List), commentIDs(list))
+			}
+		}
+	}
+
+	pages, err := s.GetCommentPages(ctx, postIDs, 1, 2, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := commentIDs(pages[first.ID].List), []uint{created[2].ID, created[1].ID}; !reflect.DeepEqual(got, want) {
+		t.Errorf("第一篇文章第1页 = %v, want %v", got, want)
+	}
+	if pages[first.ID].Total != 3 {
+		t.Errorf("第一篇文章总数 = %d, want 3", pages[first.ID].Total)
+	}
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"homework4/internal/models"
+	"homework4/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+const (
+	CounterPostCommentCount = "post.comment_count" // 文章评论数
+	CounterUserPostCount    = "user.post_count"    // 用户文章数
+)
+
+type CounterService struct {
+	db     *gorm.DB
+	caches CounterCache // 修正计数后删除文章和用户缓存
+}
+
+func NewCounterService(db *gorm.DB, caches CounterCache) *CounterService {
+	return &CounterService{db: db, caches: caches}
+}
+
+// CounterDrift 冗余计数和实际数量不一致的记录
+type CounterDrift struct {
+	Counter string `json:"counter"` // 计数字段
+	ID      uint   `json:"id"`      // 记录ID
+	Stored  int64  `json:"stored"`  // 冗余计数
+	Actual  int64  `json:"actual"`  // 实际数量
+}
+
+/**
+ * @Description: 检查文章评论数和用户文章数是否和实际数量一致
+ * @param ctx
+ * @param fix 为true时修正不一致的计数
+ * @return ([]CounterDrift, error) 不一致的记录
+ */
+func (s *CounterService) ReconcileCounters(ctx context.Context, fix bool) ([]CounterDrift, error) {
+	db := s.db.WithContext(ctx)
+
+	postDrifts, err := s.findDrifts(db, CounterPostCommentCount, &models.Post{}, "comment_count", repository.PostCommentCountExpr(db))
+	if err != nil {
+		return nil, err
+	}
+	userDrifts, err := s.findDrifts(db, CounterUserPostCount, &models.User{}, "post_count", repository.UserPostCountExpr(db))
+	if err != nil {
+		return nil, err
+	}
+	drifts := append(postDrifts, userDrifts...)
+
+	if !fix || len(drifts) == 0 {
+		return drifts, nil
+	}
+
+	var postIDs, userIDs []uint
+	for _, drift := range drifts {
+		if drift.Counter == CounterPostCommentCount {
+			postIDs = append(postIDs, drift.ID)
+		} else {
+			userIDs = append(userIDs, drift.ID)
+		}
+	}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := repository.RecountPostComments(tx, postIDs); err != nil {
+			return err
+		}
+		return repository.RecountUserPosts(tx, userIDs)
+	})
+	if err != nil {
+		return drifts, err
+	}
+	//评论数变化影响按评论数排序的文章列表 文章详情和列表缓存整体失效
+	if len(postIDs) > 0 {
+		s.caches.FlushPosts(ctx)
+	}
+	s.caches.InvalidateUsers(ctx, userIDs...)
+	return drifts, nil
+}
+
+// 查询冗余计数和子查询结果不一致的记录
+func (s *CounterService) findDrifts(db *gorm.DB, counter string, model interface{}, column string, actualExpr string) ([]CounterDrift, error) {
+	type driftRow struct {
+		ID     uint
+		Stored int64
+		Actual int64
+	}
+	var rows []driftRow
+	if err := db.Model(model).
+		Select(fmt.Sprintf("id, %s AS stored, %s AS actual", column, actualExpr)).
+		Where(fmt.Sprintf("%s <> %s", column, actualExpr)).
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	drifts := make([]CounterDrift, len(rows))
+	for i, row := range rows {
+		drifts[i] = CounterDrift{Counter: counter, ID: row.ID, Stored: row.Stored, Actual: row.Actual}
+	}
+	return drifts, nil
+}
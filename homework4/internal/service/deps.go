@@ -0,0 +1,52 @@
+package service
+
+/**
+ * @Description: service之间的依赖接口 由直接使用gorm和redis的service实现 文章、评论、用户service只通过这些接口使用它们 测试时替换为空实现
+ */
+import (
+	"context"
+	"homework4/internal/models"
+)
+
+// ReactionSummarizer 批量获取点赞和表情回应汇总
+type ReactionSummarizer interface {
+	GetSummaries(ctx context.Context, targetType string, likeCounts map[uint]uint64, userID uint) map[uint]ReactionSummary
+}
+
+// FeedPublisher 首页动态时间线维护
+type FeedPublisher interface {
+	Mode() string
+	FeedPostIDs(ctx context.Context, userID uint, cursor uint, limit int) ([]uint, error)
+	OnPostCreated(ctx context.Context, post *models.Post)
+	OnPostDeleted(ctx context.Context, post *models.Post)
+}
+
+// ViewRecorder 文章浏览记录
+type ViewRecorder interface {
+	RecordView(ctx context.Context, postID uint, visitor string)
+}
+
+// HotRanker 热门文章排行
+type HotRanker interface {
+	HotPostIDs(ctx context.Context, offset int, limit int) ([]uint, int64, error)
+	RemovePost(ctx context.Context, postID uint)
+}
+
+// FollowCounter 关注关系查询
+type FollowCounter interface {
+	CountFollows(ctx context.Context, userID uint) (int64, int64, error)
+	IsFollowing(ctx context.Context, followerID uint, followeeID uint) (bool, error)
+}
+
+// TokenIssuer 登录令牌签发和吊销
+type TokenIssuer interface {
+	IssueToken(ctx context.Context, userID uint, username string, nickname string) (string, error)
+	RevokeToken(ctx context.Context, userID uint) error
+}
+
+// CounterCache 直接更新数据库中的计数后删除相关缓存
+type CounterCache interface {
+	InvalidatePosts(ctx context.Context, postIDs ...uint)
+	InvalidateUsers(ctx context.Context, userIDs ...uint)
+	FlushPosts(ctx context.Context)
+}
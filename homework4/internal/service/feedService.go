@@ -0,0 +1,363 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"homework4/config"
+	"homework4/internal/models"
+	"homework4/pkg/logger"
+	"strconv"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+const (
+	feedTimelineKeyPrefix = "feed:timeline:"    // 写扩散时间线 zset key前缀 feed:timeline:{userID} score和member都为文章ID
+	feedFanOutQueueKey    = "feed:fanout:queue" // 写扩散任务队列 list 从左边入队 右边出队
+	feedFanOutBatch       = 500                 // 写扩散每批写入的粉丝数
+	feedBackfillSize      = 50                  // 关注时回填被关注者最近的文章数
+
+	feedFanOutAdd    = "add"    // 写入粉丝时间线
+	feedFanOutRemove = "remove" // 从粉丝时间线移除
+)
+
+// 只写入已经建立的时间线并裁剪长度 未建立的在读取时从数据库重建 写入不续期 没有过期时间的时间线补上过期时间
+var feedPushScript = goredis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return 0
+end
+redis.call('ZADD', KEYS[1], ARGV[1], ARGV[1])
+redis.call('ZREMRANGEBYRANK', KEYS[1], 0, -tonumber(ARGV[2]) - 1)
+if redis.call('TTL', KEYS[1]) == -1 then
+	redis.call('EXPIRE', KEYS[1], ARGV[3])
+end
+return 1
+`)
+
+// 写扩散任务 每次执行一批粉丝 未执行完的记录游标后重新入队
+type fanOutTask struct {
+	Action   string `json:"action"`   // add-写入 remove-移除
+	PostID   uint   `json:"postId"`   // 文章ID
+	UserID   uint   `json:"userId"`   // 作者ID
+	Cursor   uint   `json:"cursor"`   // 已执行的最大粉丝ID
+	Attempts int    `json:"attempts"` // 当前批次失败的次数
+}
+
+type FeedService struct {
+	db  *gorm.DB
+	rdb *goredis.Client
+	cfg config.FeedConfig
+}
+
+func NewFeedService(db *gorm.DB, rdb *goredis.Client, cfg config.FeedConfig) *FeedService {
+	return &FeedService{db: db, rdb: rdb, cfg: cfg}
+}
+
+// GetFeedRequest 获取首页动态请求
+type GetFeedRequest struct {
+	Cursor uint `form:"cursor" binding:"omitempty" example:"0"`               // 游标 上一页最后一篇文章ID 不传从最新开始
+	Limit  int  `form:"limit" binding:"omitempty,min=1,max=100" example:"10"` // 每页数量
+}
+
+// FeedResponse 首页动态响应
+type FeedResponse struct {
+	List       []PostResponse `json:"list"`                    // 文章列表
+	NextCursor uint           `json:"nextCursor" example:"10"` // 下一页游标 没有下一页时为0
+	HasMore    bool           `json:"hasMore" example:"false"` // 是否还有下一页
+	Mode       string         `json:"mode" example:"read"`     // 当前首页动态模式 read-读扩散 write-写扩散
+}
+
+/**
+ * @Description: 获取关注用户的文章ID 按文章ID倒序 根据配置使用读扩散或写扩散
+ * @param ctx
+ * @param userID
+ * @param cursor 上一页最后一篇文章ID 0为从最新开始
+ * @param limit
+ * @return ([]uint, error)
+ */
+func (s *FeedService) FeedPostIDs(ctx context.Context, userID uint, cursor uint, limit int) ([]uint, error) {
+	if s.cfg.Mode == config.FeedModeWrite {
+		ids, err := s.readTimeline(ctx, userID, cursor, limit)
+		if err == nil {
+			return ids, nil
+		}
+		//redis不可用时退化为读扩散
+		logger.FromContext(ctx).Error("读取首页时间线失败 使用读扩散", logger.WrapMeta(err, logger.NewMeta("userID", userID))...)
+	}
+	return s.queryFolloweePostIDs(ctx, userID, cursor, limit)
+}
+
+/**
+ * @Description: 文章发布后添加写入粉丝时间线的任务 由后台任务执行 仅写扩散模式生效
+ * @param ctx
+ * @param post
+ */
+func (s *FeedService) OnPostCreated(ctx context.Context, post *models.Post) {
+	if s.cfg.Mode != config.FeedModeWrite {
+		return
+	}
+	s.enqueueFanOut(ctx, fanOutTask{Action: feedFanOutAdd, PostID: post.ID, UserID: post.UserID})
+}
+
+/**
+ * @Description: 文章删除后添加从粉丝时间线移除的任务 由后台任务执行 仅写扩散模式生效 移除前读取会过滤已删除的文章
+ * @param ctx
+ * @param post
+ */
+func (s *FeedService) OnPostDeleted(ctx context.Context, post *models.Post) {
+	if s.cfg.Mode != config.FeedModeWrite {
+		return
+	}
+	s.enqueueFanOut(ctx, fanOutTask{Action: feedFanOutRemove, PostID: post.ID, UserID: post.UserID})
+}
+
+/**
+ * @Description: 执行写扩散任务 每个任务执行一批粉丝 失败的批次重新入队 超过重试次数后丢弃
+ * @param ctx
+ * @param limit 最多执行的批次数
+ * @return (int, error) 执行的批次数
+ */
+func (s *FeedService) ProcessFanOut(ctx context.Context, limit int) (int, error) {
+	processed := 0
+	for processed < limit {
+		data, err := s.rdb.RPop(ctx, feedFanOutQueueKey).Bytes()
+		if errors.Is(err, goredis.Nil) {
+			return processed, nil
+		}
+		if err != nil {
+			return processed, err
+		}
+		processed++
+
+		var task fanOutTask
+		if err := json.Unmarshal(data, &task); err != nil {
+			logger.FromContext(ctx).Error("写扩散任务格式错误 丢弃", logger.WrapMeta(err, logger.NewMeta("task", string(data)))...)
+			continue
+		}
+		done, err := s.fanOutBatch(ctx, &task)
+		if err != nil {
+			task.Attempts++
+			meta := logger.WrapMeta(err, logger.NewMeta("postID", task.PostID), logger.NewMeta("action", task.Action), logger.NewMeta("attempts", task.Attempts))
+			if task.Attempts > s.cfg.FanOutRetries {
+				//粉丝的时间线过期后从数据库重建 丢弃的变化在重建后补上
+				logger.FromContext(ctx).Error("写扩散任务超过重试次数 丢弃", meta...)
+				continue
+			}
+			logger.FromContext(ctx).Warn("写扩散任务失败 重新入队", meta...)
+			s.enqueueFanOut(ctx, task)
+			continue
+		}
+		if !done {
+			task.Attempts = 0
+			s.enqueueFanOut(ctx, task)
+		}
+	}
+	return processed, nil
+}
+
+// 执行一批粉丝 返回是否已经执行完所有粉丝
+func (s *FeedService) fanOutBatch(ctx context.Context, task *fanOutTask) (bool, error) {
+	var followerIDs []uint
+	if err := s.db.WithContext(ctx).Model(&models.Follow{}).Where("followee_id = ? AND follower_id > ?", task.UserID, task.Cursor).
+		Order("follower_id").Limit(feedFanOutBatch).Pluck("follower_id", &followerIDs).Error; err != nil {
+		return false, err
+	}
+	if len(followerIDs) == 0 {
+		return true, nil
+	}
+
+	pipe := s.rdb.Pipeline()
+	for _, followerID := range followerIDs {
+		if task.Action == feedFanOutRemove {
+			pipe.ZRem(ctx, feedTimelineKey(followerID), task.PostID)
+		} else {
+			feedPushScript.Eval(ctx, pipe, []string{feedTimelineKey(followerID)}, task.PostID, s.cfg.MaxLength, s.cfg.TimelineTTL)
+		}
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return false, err
+	}
+	task.Cursor = followerIDs[len(followerIDs)-1]
+	return len(followerIDs) < feedFanOutBatch, nil
+}
+
+// 写扩散任务入队 失败时只记录日志 粉丝的时间线过期后从数据库重建
+func (s *FeedService) enqueueFanOut(ctx context.Context, task fanOutTask) {
+	//数据已经提交 客户端断开也要入队
+	ctx = context.WithoutCancel(ctx)
+	data, err := json.Marshal(task)
+	if err == nil {
+		err = s.rdb.LPush(ctx, feedFanOutQueueKey, data).Err()
+	}
+	if err != nil {
+		logger.FromContext(ctx).Error("写扩散任务入队失败", logger.WrapMeta(err, logger.NewMeta("postID", task.PostID), logger.NewMeta("action", task.Action))...)
+	}
+}
+
+/**
+ * @Description: 关注后回填被关注者最近的文章 仅写扩散模式生效
+ * @param ctx
+ * @param followerID
+ * @param followeeID
+ */
+func (s *FeedService) OnFollow(ctx context.Context, followerID uint, followeeID uint) {
+	if s.cfg.Mode != config.FeedModeWrite {
+		return
+	}
+	//数据已经提交 客户端断开也要写完时间线
+	ctx = context.WithoutCancel(ctx)
+	key := feedTimelineKey(followerID)
+	exists, err := s.rdb.Exists(ctx, key).Result()
+	if err != nil || exists == 0 {
+		//时间线未建立 读取时会从数据库重建
+		return
+	}
+
+	var postIDs []uint
+	if err := s.db.WithContext(ctx).Model(&models.Post{}).Where("user_id = ?", followeeID).
+		Order("id DESC").Limit(feedBackfillSize).Pluck("id", &postIDs).Error; err != nil {
+		logger.FromContext(ctx).Error("查询被关注者文章失败", logger.WrapMeta(err, logger.NewMeta("userID", followeeID))...)
+		return
+	}
+	if len(postIDs) == 0 {
+		return
+	}
+	members := make([]goredis.Z, len(postIDs))
+	for i, postID := range postIDs {
+		members[i] = goredis.Z{Score: float64(postID), Member: postID}
+	}
+	pipe := s.rdb.Pipeline()
+	pipe.ZAdd(ctx, key, members...)
+	pipe.ZRemRangeByRank(ctx, key, 0, int64(-s.cfg.MaxLength-1))
+	if _, err := pipe.Exec(ctx); err != nil {
+		logger.FromContext(ctx).Error("回填时间线失败", logger.WrapMeta(err, logger.NewMeta("userID", followerID))...)
+	}
+}
+
+/**
+ * @Description: 取消关注后从时间线移除被关注者的文章 仅写扩散模式生效
+ * @param ctx
+ * @param followerID
+ * @param followeeID
+ */
+func (s *FeedService) OnUnfollow(ctx context.Context, followerID uint, followeeID uint) {
+	if s.cfg.Mode != config.FeedModeWrite {
+		return
+	}
+	//数据已经提交 客户端断开也要写完时间线
+	ctx = context.WithoutCancel(ctx)
+	key := feedTimelineKey(followerID)
+	members, err := s.rdb.ZRange(ctx, key, 0, -1).Result()
+	if err != nil || len(members) == 0 {
+		return
+	}
+
+	var postIDs []uint
+	if err := s.db.WithContext(ctx).Unscoped().Model(&models.Post{}).Where("user_id = ? AND id IN ?", followeeID, members).
+		Pluck("id", &postIDs).Error; err != nil {
+		//移除失败时删除时间线 读取时重建
+		logger.FromContext(ctx).Error("查询被关注者文章失败", logger.WrapMeta(err, logger.NewMeta("userID", followeeID))...)
+		s.rdb.Del(ctx, key)
+		return
+	}
+	if len(postIDs) == 0 {
+		return
+	}
+	removeMembers := make([]interface{}, len(postIDs))
+	for i, postID := range postIDs {
+		removeMembers[i] = postID
+	}
+	if err := s.rdb.ZRem(ctx, key, removeMembers...).Err(); err != nil {
+		logger.FromContext(ctx).Error("移除时间线文章失败", logger.WrapMeta(err, logger.NewMeta("userID", followerID))...)
+	}
+}
+
+// 读扩散 从数据库查询关注用户的文章ID
+func (s *FeedService) queryFolloweePostIDs(ctx context.Context, userID uint, cursor uint, limit int) ([]uint, error) {
+	followees := s.db.WithContext(ctx).Model(&models.Follow{}).Select("followee_id").Where("follower_id = ?", userID)
+	query := s.db.WithContext(ctx).Model(&models.Post{}).Where("user_id IN (?)", followees)
+	if cursor > 0 {
+		query = query.Where("id < ?", cursor)
+	}
+	var postIDs []uint
+	if err := query.Order("id DESC").Limit(limit).Pluck("id", &postIDs).Error; err != nil {
+		return nil, err
+	}
+	return postIDs, nil
+}
+
+// 写扩散 从redis时间线读取文章ID 时间线不存在时从数据库重建
+func (s *FeedService) readTimeline(ctx context.Context, userID uint, cursor uint, limit int) ([]uint, error) {
+	key := feedTimelineKey(userID)
+	exists, err := s.rdb.Exists(ctx, key).Result()
+	if err != nil {
+		return nil, err
+	}
+	if exists == 0 {
+		if err := s.rebuildTimeline(ctx, userID); err != nil {
+			return nil, err
+		}
+	} else if err := s.rdb.Expire(ctx, key, s.timelineTTL()).Err(); err != nil {
+		//读取时续期 不活跃用户的时间线过期后释放内存
+		return nil, err
+	}
+
+	max := "+inf"
+	if cursor > 0 {
+		max = "(" + strconv.FormatUint(uint64(cursor), 10)
+	}
+	//排除占位成员0
+	members, err := s.rdb.ZRevRangeByScore(ctx, key, &goredis.ZRangeBy{
+		Min:   "(0",
+		Max:   max,
+		Count: int64(limit),
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+	postIDs := make([]uint, 0, len(members))
+	for _, member := range members {
+		postID, err := strconv.ParseUint(member, 10, 64)
+		if err != nil {
+			continue
+		}
+		postIDs = append(postIDs, uint(postID))
+	}
+	return postIDs, nil
+}
+
+// 从数据库重建时间线 没有文章时写入占位成员0 防止每次都查询数据库
+func (s *FeedService) rebuildTimeline(ctx context.Context, userID uint) error {
+	postIDs, err := s.queryFolloweePostIDs(ctx, userID, 0, s.cfg.MaxLength)
+	if err != nil {
+		return err
+	}
+	members := make([]goredis.Z, 0, len(postIDs)+1)
+	members = append(members, goredis.Z{Score: 0, Member: 0})
+	for _, postID := range postIDs {
+		members = append(members, goredis.Z{Score: float64(postID), Member: postID})
+	}
+	key := feedTimelineKey(userID)
+	pipe := s.rdb.TxPipeline()
+	pipe.ZAdd(ctx, key, members...)
+	pipe.Expire(ctx, key, s.timelineTTL())
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// Mode 当前首页动态模式
+func (s *FeedService) Mode() string {
+	return s.cfg.Mode
+}
+
+func (s *FeedService) timelineTTL() time.Duration {
+	return time.Duration(s.cfg.TimelineTTL) * time.Second
+}
+
+func feedTimelineKey(userID uint) string {
+	return fmt.Sprintf("%s%d", feedTimelineKeyPrefix, userID)
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"homework4/config"
+	"homework4/internal/models"
+	"homework4/pkg/logger"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// 写扩散模式的首页动态 作者1有粉丝2和3
+func newFeedTestService(t *testing.T) (*FeedService, *gorm.DB, *miniredis.Miniredis) {
+	t.Helper()
+	logger.AppLog = zap.NewNop()
+	db, rdb, mr := newTestStores(t)
+	for _, followerID := range []uint{2, 3} {
+		if err := db.Create(&models.Follow{FollowerID: followerID, FolloweeID: 1}).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	cfg := config.FeedConfig{Mode: config.FeedModeWrite, MaxLength: 10, TimelineTTL: 3600, FanOutInterval: 1, FanOutRetries: 1}
+	return NewFeedService(db, rdb, cfg), db, mr
+}
+
+func TestFanOutRunsInBackground(t *testing.T) {
+	ctx := context.Background()
+	s, db, mr := newFeedTestService(t)
+	//粉丝2已经建立时间线 粉丝3没有
+	if _, err := s.FeedPostIDs(ctx, 2, 0, 10); err != nil {
+		t.Fatal(err)
+	}
+	if ttl := mr.TTL(feedTimelineKey(2)); ttl != time.Hour {
+		t.Errorf("时间线过期时间 = %v, want 1h", ttl)
+	}
+
+	post := createTestPost(t, db, 0)
+	s.OnPostCreated(ctx, post)
+	if members, _ := mr.ZMembers(feedTimelineKey(2)); len(members) != 1 {
+		t.Fatalf("发布时写入了时间线 members = %v", members)
+	}
+
+	processed, err := s.ProcessFanOut(ctx, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if processed != 1 {
+		t.Errorf("processed = %d, want 1", processed)
+	}
+	ids, err := s.FeedPostIDs(ctx, 2, 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 1 || ids[0] != post.ID {
+		t.Errorf("首页动态 = %v, want [%d]", ids, post.ID)
+	}
+	if mr.Exists(feedTimelineKey(3)) {
+		t.Error("写扩散建立了未读取过的时间线")
+	}
+}
+
+func TestFanOutRetriesThenDrops(t *testing.T) {
+	ctx := context.Background()
+	s, db, mr := newFeedTestService(t)
+	s.OnPostCreated(ctx, createTestPost(t, db, 0))
+	//查询粉丝失败
+	if err := db.Migrator().DropTable(&models.Follow{}); err != nil {
+		t.Fatal(err)
+	}
+
+	for attempt := 1; attempt <= 2; attempt++ {
+		if _, err := s.ProcessFanOut(ctx, 1); err != nil {
+			t.Fatal(err)
+		}
+		queued, _ := mr.List(feedFanOutQueueKey)
+		//重试1次 第2次失败后丢弃
+		if want := 2 - attempt; len(queued) != want {
+			t.Fatalf("第%d次执行后队列长度 = %d, want %d", attempt, len(queued), want)
+		}
+	}
+}
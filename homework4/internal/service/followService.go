@@ -0,0 +1,186 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"homework4/internal/errcode"
+	"homework4/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type FollowService struct {
+	db          *gorm.DB
+	feedService *FeedService
+}
+
+func NewFollowService(db *gorm.DB, feedService *FeedService) *FollowService {
+	return &FollowService{db: db, feedService: feedService}
+}
+
+// FollowRequest 关注/取消关注请求
+type FollowRequest struct {
+	UserID uint `json:"userId" binding:"required" example:"2"` // 被关注的用户ID
+}
+
+// GetFollowListRequest 获取粉丝/关注列表请求
+type GetFollowListRequest struct {
+	UserID   uint `form:"userId" binding:"required" example:"1"`                   // 用户ID
+	Page     int  `form:"page" binding:"omitempty,min=1" example:"1"`              // 页码
+	PageSize int  `form:"pageSize" binding:"omitempty,min=1,max=100" example:"10"` // 每页数量
+}
+
+// FollowUserResponse 粉丝/关注用户响应
+type FollowUserResponse struct {
+	UserID     uint   `json:"userId" example:"2"`                       // 用户ID
+	Username   string `json:"username" example:"testuser"`              // 用户名
+	Nickname   string `json:"nickname" example:"测试用户"`                  // 昵称
+	FollowedAt string `json:"followedAt" example:"2024-01-01 12:00:00"` // 关注时间
+}
+
+/**
+ * @Description: 关注用户 重复关注直接返回
+ * @param ctx
+ * @param req
+ * @param userID
+ * @return error
+ */
+func (s *FollowService) Follow(ctx context.Context, req *FollowRequest, userID uint) error {
+	if req.UserID == userID {
+		return errcode.ErrCannotFollowSelf
+	}
+	var followee models.User
+	if err := s.db.WithContext(ctx).Select("id").First(&followee, req.UserID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errcode.ErrUserNotFound
+		}
+		return err
+	}
+
+	follow := &models.Follow{FollowerID: userID, FolloweeID: req.UserID}
+	//唯一索引冲突时不做处理 保证幂等
+	result := s.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(follow)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		s.feedService.OnFollow(ctx, userID, req.UserID)
+	}
+	return nil
+}
+
+/**
+ * @Description: 取消关注 未关注时直接返回
+ * @param ctx
+ * @param req
+ * @param userID
+ * @return error
+ */
+func (s *FollowService) Unfollow(ctx context.Context, req *FollowRequest, userID uint) error {
+	result := s.db.WithContext(ctx).Where("follower_id = ? AND followee_id = ?", userID, req.UserID).Delete(&models.Follow{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		s.feedService.OnUnfollow(ctx, userID, req.UserID)
+	}
+	return nil
+}
+
+/**
+ * @Description: 获取粉丝分页
+ * @param ctx
+ * @param req
+ * @return ([]FollowUserResponse, int64, error)
+ */
+func (s *FollowService) GetFollowers(ctx context.Context, req *GetFollowListRequest) ([]FollowUserResponse, int64, error) {
+	return s.pageFollows(ctx, "followee_id", "Follower", req)
+}
+
+/**
+ * @Description: 获取关注分页
+ * @param ctx
+ * @param req
+ * @return ([]FollowUserResponse, int64, error)
+ */
+func (s *FollowService) GetFollowing(ctx context.Context, req *GetFollowListRequest) ([]FollowUserResponse, int64, error) {
+	return s.pageFollows(ctx, "follower_id", "Followee", req)
+}
+
+/**
+ * @Description: 统计粉丝数和关注数
+ * @param ctx
+ * @param userID
+ * @return (int64, int64, error) 粉丝数 关注数
+ */
+func (s *FollowService) CountFollows(ctx context.Context, userID uint) (int64, int64, error) {
+	var followerCount, followingCount int64
+	if err := s.db.WithContext(ctx).Model(&models.Follow{}).Where("followee_id = ?", userID).Count(&followerCount).Error; err != nil {
+		return 0, 0, err
+	}
+	if err := s.db.WithContext(ctx).Model(&models.Follow{}).Where("follower_id = ?", userID).Count(&followingCount).Error; err != nil {
+		return 0, 0, err
+	}
+	return followerCount, followingCount, nil
+}
+
+/**
+ * @Description: 判断是否已关注
+ * @param ctx
+ * @param followerID
+ * @param followeeID
+ * @return (bool, error)
+ */
+func (s *FollowService) IsFollowing(ctx context.Context, followerID uint, followeeID uint) (bool, error) {
+	var count int64
+	if err := s.db.WithContext(ctx).Model(&models.Follow{}).Where("follower_id = ? AND followee_id = ?", followerID, followeeID).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// 按关注时间倒序分页 column为查询条件列 preload为需要返回的用户关联
+func (s *FollowService) pageFollows(ctx context.Context, column string, preload string, req *GetFollowListRequest) ([]FollowUserResponse, int64, error) {
+	page := 1
+	pageSize := 10
+
+	if req.Page > 0 {
+		page = req.Page
+	}
+	if req.PageSize > 0 {
+		pageSize = req.PageSize
+	}
+
+	var follows []models.Follow
+	var total int64
+
+	query := s.db.WithContext(ctx).Model(&models.Follow{}).Where(column+" = ?", req.UserID)
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	if err := query.Preload(preload).Order("created_at DESC").Offset(offset).Limit(pageSize).Find(&follows).Error; err != nil {
+		return nil, 0, err
+	}
+
+	userResponses := make([]FollowUserResponse, len(follows))
+	for i, follow := range follows {
+		user := follow.Followee
+		if preload == "Follower" {
+			user = follow.Follower
+		}
+		userResponses[i] = FollowUserResponse{
+			UserID:     user.ID,
+			Username:   user.Username,
+			Nickname:   user.Nickname,
+			FollowedAt: follow.CreatedAt.Format(time.DateTime),
+		}
+	}
+
+	return userResponses, total, nil
+}
@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"homework4/config"
+	"homework4/internal/models"
+	"math"
+	"strconv"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+const (
+	hotRankKey = "post:hot" // 热门文章排行zset score为热度 member为文章ID
+)
+
+type HotService struct {
+	db  *gorm.DB
+	rdb *goredis.Client
+	cfg config.HotConfig
+}
+
+func NewHotService(db *gorm.DB, rdb *goredis.Client, cfg config.HotConfig) *HotService {
+	return &HotService{db: db, rdb: rdb, cfg: cfg}
+}
+
+/**
+ * @Description: 重新计算热门排行 热度随发布时间衰减 只计算最近windowDays天发布的文章
+ * @param ctx
+ * @return (int, error) 参与排行的文章数
+ */
+func (s *HotService) RefreshRanking(ctx context.Context) (int, error) {
+	type postStat struct {
+		ID           uint
+		CreatedAt    time.Time
+		ViewCount    uint64
+		LikeCount    uint64
+		CommentCount uint64
+	}
+
+	hotCfg := s.cfg
+	since := time.Now().AddDate(0, 0, -hotCfg.WindowDays)
+	var stats []postStat
+	if err := s.db.WithContext(ctx).Model(&models.Post{}).
+		Select("id, created_at, view_count, like_count, comment_count").
+		Where("created_at >= ?", since).
+		Scan(&stats).Error; err != nil {
+		return 0, err
+	}
+
+	if len(stats) == 0 {
+		return 0, s.rdb.Del(ctx, hotRankKey).Err()
+	}
+
+	now := time.Now()
+	members := make([]goredis.Z, len(stats))
+	for i, stat := range stats {
+		weighted := float64(stat.ViewCount)*hotCfg.ViewWeight +
+			float64(stat.LikeCount)*hotCfg.LikeWeight +
+			float64(stat.CommentCount)*hotCfg.CommentWeight
+		hours := now.Sub(stat.CreatedAt).Hours()
+		if hours < 0 {
+			hours = 0
+		}
+		members[i] = goredis.Z{
+			Score:  weighted / math.Pow(hours+2, hotCfg.Gravity),
+			Member: stat.ID,
+		}
+	}
+
+	//先写入临时key再替换 避免刷新过程中读到不完整的排行
+	tmpKey := fmt.Sprintf("%s:tmp:%d", hotRankKey, now.UnixNano())
+	pipe := s.rdb.TxPipeline()
+	pipe.ZAdd(ctx, tmpKey, members...)
+	pipe.Rename(ctx, tmpKey, hotRankKey)
+	if _, err := pipe.Exec(ctx); err != nil {
+		s.rdb.Del(ctx, tmpKey)
+		return 0, err
+	}
+	return len(stats), nil
+}
+
+/**
+ * @Description: 分页获取热门文章ID 排行不存在时先计算一次
+ * @param ctx
+ * @param offset
+ * @param limit
+ * @return ([]uint, int64, error) 文章ID 参与排行的文章总数
+ */
+func (s *HotService) HotPostIDs(ctx context.Context, offset int, limit int) ([]uint, int64, error) {
+	exists, err := s.rdb.Exists(ctx, hotRankKey).Result()
+	if err != nil {
+		return nil, 0, err
+	}
+	if exists == 0 {
+		if _, err := s.RefreshRanking(ctx); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	pipe := s.rdb.Pipeline()
+	totalCmd := pipe.ZCard(ctx, hotRankKey)
+	membersCmd := pipe.ZRevRange(ctx, hotRankKey, int64(offset), int64(offset+limit-1))
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, 0, err
+	}
+
+	postIDs := make([]uint, 0, len(membersCmd.Val()))
+	for _, member := range membersCmd.Val() {
+		postID, err := strconv.ParseUint(member, 10, 64)
+		if err != nil {
+			continue
+		}
+		postIDs = append(postIDs, uint(postID))
+	}
+	return postIDs, totalCmd.Val(), nil
+}
+
+/**
+ * @Description: 从热门排行中移除文章
+ * @param ctx
+ * @param postID
+ */
+func (s *HotService) RemovePost(ctx context.Context, postID uint) {
+	s.rdb.ZRem(ctx, hotRankKey, postID)
+}
@@ -1,34 +1,53 @@
 package service
 
 import (
+	"context"
 	"errors"
-	"homework4/internal/app/mysql"
+	"homework4/internal/errcode"
+	"homework4/internal/metrics"
 	"homework4/internal/models"
-
+	"homework4/internal/repository"
+	"sort"
 	"time"
-
-	"gorm.io/gorm"
 )
 
 type PostService struct {
-	db *gorm.DB
+	posts     repository.PostRepository
+	users     repository.UserRepository
+	reactions ReactionSummarizer
+	feed      FeedPublisher
+	views     ViewRecorder
+	hot       HotRanker
 }
 
-func NewPostService() *PostService {
-	return &PostService{db: mysql.DB}
+func NewPostService(posts repository.PostRepository, users repository.UserRepository, reactions ReactionSummarizer, feed FeedPublisher, views ViewRecorder, hot HotRanker) *PostService {
+	return &PostService{
+		posts:     posts,
+		users:     users,
+		reactions: reactions,
+		feed:      feed,
+		views:     views,
+		hot:       hot,
+	}
 }
 
+const (
+	PostSortLatest        = "latest"         // 按发布时间倒序
+	PostSortHot           = "hot"            // 按热度倒序
+	PostSortMostCommented = "most_commented" // 按评论数倒序
+)
+
 // CreatePostRequest 创建文章请求
 type CreatePostRequest struct {
-	Title   string `json:"title" binding:"required,min=1,max=20" example:"我的第一篇文章"`      // 标题
-	Content string `json:"content" binding:"required,min=1,max=200" example:"这是文章内容..."` // 内容
+	Title   string `json:"title" binding:"required,min=1,max=20,banned" example:"我的第一篇文章"`      // 标题
+	Content string `json:"content" binding:"required,min=1,max=200,banned" example:"这是文章内容..."` // 内容
 }
 
 // UpdatePostRequest 更新文章请求
 type UpdatePostRequest struct {
-	PostID  uint   `json:"postId" binding:"required" example:"1"`                         // 文章ID
-	Title   string `json:"title" binding:"omitempty,min=1,max=20" example:"更新后的标题"`       // 标题
-	Content string `json:"content" binding:"omitempty,min=1,max=200" example:"更新后的内容..."` // 内容
+	PostID  uint   `json:"postId" binding:"required" example:"1"`                                // 文章ID
+	Title   string `json:"title" binding:"omitempty,min=1,max=20,banned" example:"更新后的标题"`       // 标题
+	Content string `json:"content" binding:"omitempty,min=1,max=200,banned" example:"更新后的内容..."` // 内容
 }
 
 // DeletePostRequest 删除文章请求
@@ -36,12 +55,40 @@ type DeletePostRequest struct {
 	PostID uint `json:"postId" binding:"required" example:"1"` // 文章ID
 }
 
+// BatchDeletePostRequest 批量删除文章请求
+type BatchDeletePostRequest struct {
+	PostIDs []uint `json:"postIds" binding:"required,min=1,max=100" example:"1,2"` // 文章ID列表
+}
+
 // GetPostListRequest 获取文章列表请求
 type GetPostListRequest struct {
+	Page     int    `form:"page" binding:"omitempty,min=1" example:"1"`                                // 页码
+	PageSize int    `form:"pageSize" binding:"omitempty,min=1,max=100" example:"10"`                   // 每页数量
+	Sort     string `form:"sort" binding:"omitempty,oneof=latest hot most_commented" example:"latest"` // 排序 latest-最新 hot-最热 most_commented-评论最多
+}
+
+// GetHotPostListRequest 获取热门文章请求
+type GetHotPostListRequest struct {
 	Page     int `form:"page" binding:"omitempty,min=1" example:"1"`              // 页码
 	PageSize int `form:"pageSize" binding:"omitempty,min=1,max=100" example:"10"` // 每页数量
 }
 
+// GetPostDetailRequest 获取文章详情请求
+type GetPostDetailRequest struct {
+	PostID uint `form:"postId" binding:"required" example:"1"` // 文章ID
+}
+
+// PostIDRequest 路径中的文章ID
+type PostIDRequest struct {
+	PostID uint `uri:"id" binding:"required" example:"1"` // 文章ID
+}
+
+// PatchPostRequest 部分更新文章请求 只更新传入的字段
+type PatchPostRequest struct {
+	Title   string `json:"title" binding:"omitempty,min=1,max=20,banned" example:"更新后的标题"`       // 标题
+	Content string `json:"content" binding:"omitempty,min=1,max=200,banned" example:"更新后的内容..."` // 内容
+}
+
 // PostResponse 文章响应
 type PostResponse struct {
 	ID        uint   `json:"id" example:"1"`                          // 文章ID
@@ -50,24 +97,36 @@ type PostResponse struct {
 	Content   string `json:"content" example:"这是文章内容..."`             // 内容
 	CreatedAt string `json:"createdAt" example:"2024-01-01 12:00:00"` // 创建时间
 	UpdatedAt string `json:"updatedAt" example:"2024-01-01 12:00:00"` // 更新时间
+	ViewCount uint64 `json:"viewCount" example:"100"`                 // 浏览量
+
+	CommentCount uint32 `json:"commentCount" example:"5"`   // 评论数
+	HasComments  bool   `json:"hasComments" example:"true"` // 是否有评论
+
+	LikeCount int64            `json:"likeCount" example:"10"` // 点赞数
+	Liked     bool             `json:"liked" example:"false"`  // 当前用户是否点赞 未登录为false
+	Reactions map[string]int64 `json:"reactions"`              // 各表情回应数
 }
 
 /**
  * @Description: 创建文章
+ * @param ctx
  * @param req
  * @param userID
  * @return (*PostResponse, error)
  */
-func (s *PostService) CreatePost(req *CreatePostRequest, userID uint) (*PostResponse, error) {
+func (s *PostService) CreatePost(ctx context.Context, req *CreatePostRequest, userID uint) (*PostResponse, error) {
 	post := &models.Post{
 		UserID:  userID,
 		Title:   req.Title,
 		Content: req.Content,
 	}
 
-	if err := s.db.Create(post).Error; err != nil {
+	if err := s.posts.Create(ctx, post); err != nil {
 		return nil, err
 	}
+	metrics.PostsCreated.Inc()
+	//写入粉丝的首页时间线
+	s.feed.OnPostCreated(ctx, post)
 
 	return &PostResponse{
 		ID:        post.ID,
@@ -76,26 +135,25 @@ func (s *PostService) CreatePost(req *CreatePostRequest, userID uint) (*PostResp
 		Content:   post.Content,
 		CreatedAt: post.CreatedAt.Format(time.DateTime),
 		UpdatedAt: post.UpdatedAt.Format(time.DateTime),
+		Reactions: map[string]int64{},
 	}, nil
 }
 
 /**
  * @Description: 更新文章
+ * @param ctx
  * @param req
  * @param userID
  * @return (bool, error)
  */
-func (s *PostService) UpdatePost(req *UpdatePostRequest, userID uint) (bool, error) {
-	var post models.Post
-	if err := s.db.First(&post, req.PostID).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return false, errors.New("文章不存在")
-		}
+func (s *PostService) UpdatePost(ctx context.Context, req *UpdatePostRequest, userID uint) (bool, error) {
+	post, err := s.findPost(ctx, req.PostID)
+	if err != nil {
 		return false, err
 	}
 
 	if post.UserID != userID {
-		return false, errors.New("无权修改此文章")
+		return false, errcode.ErrPostUpdateForbidden
 	}
 
 	updates := make(map[string]interface{})
@@ -109,44 +167,74 @@ func (s *PostService) UpdatePost(req *UpdatePostRequest, userID uint) (bool, err
 	if len(updates) == 0 {
 		return false, nil
 	}
-	if err := s.db.Model(&post).Updates(updates).Error; err != nil {
+	if err := s.posts.Update(ctx, post.ID, updates); err != nil {
 		return false, err
 	}
 	return true, nil
 }
 
 /**
- * @Description: 删除文章
+ * @Description: 删除文章 文章作者和管理员可以删除
+ * @param ctx
  * @param req
  * @param userID
  * @return error
  */
-func (s *PostService) DeletePost(req *DeletePostRequest, userID uint) error {
-	var post models.Post
-	if err := s.db.First(&post, req.PostID).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return errors.New("文章不存在")
-		}
+func (s *PostService) DeletePost(ctx context.Context, req *DeletePostRequest, userID uint) error {
+	post, err := s.findPost(ctx, req.PostID)
+	if err != nil {
 		return err
 	}
 
+	//管理员可以删除其他用户的文章
 	if post.UserID != userID {
-		return errors.New("无权删除此文章")
+		admin, err := isAdmin(ctx, s.users, userID)
+		if err != nil {
+			return err
+		}
+		if !admin {
+			return errcode.ErrPostDeleteForbidden
+		}
 	}
 
-	if err := s.db.Delete(&post).Error; err != nil {
+	if err := s.posts.Delete(ctx, post); err != nil {
 		return err
 	}
+	//从粉丝的首页时间线和热门排行移除
+	s.feed.OnPostDeleted(ctx, post)
+	s.hot.RemovePost(ctx, post.ID)
 
 	return nil
 }
 
+/**
+ * @Description: 批量删除自己的文章 不属于自己或不存在的文章会被忽略
+ * @param ctx
+ * @param req
+ * @param userID
+ * @return (int64, error) 删除的文章数
+ */
+func (s *PostService) BatchDeletePosts(ctx context.Context, req *BatchDeletePostRequest, userID uint) (int64, error) {
+	posts, err := s.posts.BatchDelete(ctx, userID, req.PostIDs)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range posts {
+		s.feed.OnPostDeleted(ctx, &posts[i])
+		s.hot.RemovePost(ctx, posts[i].ID)
+	}
+	return int64(len(posts)), nil
+}
+
 /**
  * @Description: 获取文章分页
+ * @param ctx
  * @param req
+ * @param userID 当前登录用户ID 未登录为0
  * @return ([]PostResponse, int64, error)
  */
-func (s *PostService) GetPostList(req *GetPostListRequest) ([]PostResponse, int64, error) {
+func (s *PostService) GetPostList(ctx context.Context, req *GetPostListRequest, userID uint) ([]PostResponse, int64, error) {
 	page := 1
 	pageSize := 10
 
@@ -157,29 +245,197 @@ func (s *PostService) GetPostList(req *GetPostListRequest) ([]PostResponse, int6
 		pageSize = req.PageSize
 	}
 
-	var posts []models.Post
-	var total int64
+	if req.Sort == PostSortHot {
+		return s.GetHotPostList(ctx, &GetHotPostListRequest{Page: page, PageSize: pageSize}, userID)
+	}
 
-	if err := s.db.Model(&models.Post{}).Count(&total).Error; err != nil {
-		return nil, 0, err
+	order := repository.PostOrderLatest
+	if req.Sort == PostSortMostCommented {
+		order = repository.PostOrderMostCommented
 	}
 
 	offset := (page - 1) * pageSize
-	if err := s.db.Order("created_at DESC").Offset(offset).Limit(pageSize).Find(&posts).Error; err != nil {
+	posts, total, err := s.posts.List(ctx, order, offset, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return s.toPostResponses(ctx, posts, userID), total, nil
+}
+
+/**
+ * @Description: 获取热门文章分页 按时间衰减后的浏览量、点赞数和评论数综合排序
+ * @param ctx
+ * @param req
+ * @param userID 当前登录用户ID 未登录为0
+ * @return ([]PostResponse, int64, error)
+ */
+func (s *PostService) GetHotPostList(ctx context.Context, req *GetHotPostListRequest, userID uint) ([]PostResponse, int64, error) {
+	page := 1
+	pageSize := 10
+
+	if req.Page > 0 {
+		page = req.Page
+	}
+	if req.PageSize > 0 {
+		pageSize = req.PageSize
+	}
+
+	postIDs, total, err := s.hot.HotPostIDs(ctx, (page-1)*pageSize, pageSize)
+	if err != nil {
 		return nil, 0, err
 	}
+	if len(postIDs) == 0 {
+		return []PostResponse{}, total, nil
+	}
+
+	posts, err := s.posts.FindByIDs(ctx, postIDs)
+	if err != nil {
+		return nil, 0, err
+	}
+	//按排行顺序返回 已删除的文章被过滤
+	postMap := make(map[uint]models.Post, len(posts))
+	for _, post := range posts {
+		postMap[post.ID] = post
+	}
+	sorted := make([]models.Post, 0, len(posts))
+	for _, postID := range postIDs {
+		if post, ok := postMap[postID]; ok {
+			sorted = append(sorted, post)
+		}
+	}
+
+	return s.toPostResponses(ctx, sorted, userID), total, nil
+}
+
+/**
+ * @Description: 获取文章详情 同时记录浏览量
+ * @param ctx
+ * @param req
+ * @param userID 当前登录用户ID 未登录为0
+ * @param visitor 访客标识 用于浏览量去重
+ * @return (*PostResponse, error)
+ */
+func (s *PostService) GetPostDetail(ctx context.Context, req *GetPostDetailRequest, userID uint, visitor string) (*PostResponse, error) {
+	post, err := s.findPost(ctx, req.PostID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.views.RecordView(ctx, post.ID, visitor)
+
+	postResponses := s.toPostResponses(ctx, []models.Post{*post}, userID)
+	return &postResponses[0], nil
+}
+
+/**
+ * @Description: 获取文章 不记录浏览量
+ * @param ctx
+ * @param postID
+ * @param userID 当前登录用户ID 未登录为0
+ * @return (*PostResponse, error)
+ */
+func (s *PostService) GetPost(ctx context.Context, postID uint, userID uint) (*PostResponse, error) {
+	post, err := s.findPost(ctx, postID)
+	if err != nil {
+		return nil, err
+	}
+	postResponses := s.toPostResponses(ctx, []models.Post{*post}, userID)
+	return &postResponses[0], nil
+}
+
+/**
+ * @Description: 批量获取文章 不记录浏览量 已删除的文章不返回 不保证顺序
+ * @param ctx
+ * @param postIDs
+ * @param userID 当前登录用户ID 未登录为0
+ * @return ([]PostResponse, error)
+ */
+func (s *PostService) GetPostsByIDs(ctx context.Context, postIDs []uint, userID uint) ([]PostResponse, error) {
+	posts, err := s.posts.FindByIDs(ctx, postIDs)
+	if err != nil {
+		return nil, err
+	}
+	return s.toPostResponses(ctx, posts, userID), nil
+}
+
+/**
+ * @Description: 获取关注用户的文章 游标分页
+ * @param ctx
+ * @param req
+ * @param userID
+ * @return (*FeedResponse, error)
+ */
+func (s *PostService) GetFeed(ctx context.Context, req *GetFeedRequest, userID uint) (*FeedResponse, error) {
+	limit := 10
+	if req.Limit > 0 {
+		limit = req.Limit
+	}
+
+	postIDs, err := s.feed.FeedPostIDs(ctx, userID, req.Cursor, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	feed := &FeedResponse{List: []PostResponse{}, Mode: s.feed.Mode()}
+	if len(postIDs) > limit {
+		postIDs = postIDs[:limit]
+		feed.HasMore = true
+		feed.NextCursor = postIDs[len(postIDs)-1]
+	}
+	if len(postIDs) == 0 {
+		return feed, nil
+	}
+
+	//已删除的文章不会被查出 时间线中残留的会被过滤
+	posts, err := s.posts.FindByIDs(ctx, postIDs)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(posts, func(i, j int) bool {
+		return posts[i].ID > posts[j].ID
+	})
+	feed.List = s.toPostResponses(ctx, posts, userID)
+	return feed, nil
+}
+
+// 转换为文章响应 包含点赞信息
+func (s *PostService) toPostResponses(ctx context.Context, posts []models.Post, userID uint) []PostResponse {
+	likeCounts := make(map[uint]uint64, len(posts))
+	for _, post := range posts {
+		likeCounts[post.ID] = post.LikeCount
+	}
+	summaries := s.reactions.GetSummaries(ctx, models.ReactionTargetPost, likeCounts, userID)
 
 	postResponses := make([]PostResponse, len(posts))
 	for i, post := range posts {
+		summary := summaries[post.ID]
 		postResponses[i] = PostResponse{
-			ID:        post.ID,
-			UserID:    post.UserID,
-			Title:     post.Title,
-			Content:   post.Content,
-			CreatedAt: post.CreatedAt.Format("2006-01-02 15:04:05"),
-			UpdatedAt: post.UpdatedAt.Format("2006-01-02 15:04:05"),
+			ID:           post.ID,
+			UserID:       post.UserID,
+			Title:        post.Title,
+			Content:      post.Content,
+			CreatedAt:    post.CreatedAt.Format("2006-01-02 15:04:05"),
+			UpdatedAt:    post.UpdatedAt.Format("2006-01-02 15:04:05"),
+			ViewCount:    post.ViewCount,
+			CommentCount: post.CommentCount,
+			HasComments:  post.CommentCount > 0,
+			LikeCount:    summary.LikeCount,
+			Liked:        summary.Liked,
+			Reactions:    summary.Reactions,
 		}
 	}
+	return postResponses
+}
 
-	return postResponses, total, nil
+// 查询文章 不存在时返回文章不存在
+func (s *PostService) findPost(ctx context.Context, postID uint) (*models.Post, error) {
+	post, err := s.posts.FindByID(ctx, postID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, errcode.ErrPostNotFound
+		}
+		return nil, err
+	}
+	return post, nil
 }
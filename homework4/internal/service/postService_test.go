@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"homework4/internal/errcode"
+	"homework4/internal/models"
+	"homework4/internal/repository"
+	"testing"
+)
+
+// 依赖的redis功能使用空实现 只记录从时间线和热门排行移除的文章
+type stubPostDeps struct {
+	removed []uint
+}
+
+func (d *stubPostDeps) GetSummaries(ctx context.Context, targetType string, likeCounts map[uint]uint64, userID uint) map[uint]ReactionSummary {
+	return map[uint]ReactionSummary{}
+}
+
+func (d *stubPostDeps) Mode() string { return "" }
+
+func (d *stubPostDeps) FeedPostIDs(ctx context.Context, userID uint, cursor uint, limit int) ([]uint, error) {
+	return nil, nil
+}
+
+func (d *stubPostDeps) OnPostCreated(ctx context.Context, post *models.Post) {}
+
+func (d *stubPostDeps) OnPostDeleted(ctx context.Context, post *models.Post) {}
+
+func (d *stubPostDeps) RecordView(ctx context.Context, postID uint, visitor string) {}
+
+func (d *stubPostDeps) HotPostIDs(ctx context.Context, offset int, limit int) ([]uint, int64, error) {
+	return nil, 0, nil
+}
+
+func (d *stubPostDeps) RemovePost(ctx context.Context, postID uint) {
+	d.removed = append(d.removed, postID)
+}
+
+// 使用内存数据访问创建文章和评论service 并创建作者、其他用户和管理员
+func newMemoryServices(t *testing.T) (*PostService, *CommentService, *stubPostDeps, []*models.User) {
+	t.Helper()
+	store := repository.NewMemoryStore()
+	deps := &stubPostDeps{}
+	posts := NewPostService(store.Posts(), store.Users(), deps, deps, deps, deps)
+	comments := NewCommentService(store.Comments(), store.Posts(), store.Users(), deps)
+
+	users := []*models.User{
+		{Username: "author", Nickname: "作者", Password: "x"},
+		{Username: "other", Nickname: "其他用户", Password: "x"},
+		{Username: "admin", Nickname: "管理员", Password: "x", IsAdmin: true},
+	}
+	for _, user := range users {
+		if err := store.Users().Create(context.Background(), user); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return posts, comments, deps, users
+}
+
+func TestDeletePostByAuthorOrAdmin(t *testing.T) {
+	ctx := context.Background()
+	posts, _, deps, users := newMemoryServices(t)
+	author, other, admin := users[0], users[1], users[2]
+
+	created, err := posts.CreatePost(ctx, &CreatePostRequest{Title: "标题", Content: "内容"}, author.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := &DeletePostRequest{PostID: created.ID}
+	if err := posts.DeletePost(ctx, req, other.ID); !errors.Is(err, errcode.ErrPostDeleteForbidden) {
+		t.Fatalf("其他用户删除文章 err = %v, want %v", err, errcode.ErrPostDeleteForbidden)
+	}
+	if err := posts.DeletePost(ctx, req, admin.ID); err != nil {
+		t.Fatalf("管理员删除文章失败: %v", err)
+	}
+	if _, err := posts.GetPost(ctx, created.ID, 0); !errors.Is(err, errcode.ErrPostNotFound) {
+		t.Errorf("删除后查询文章 err = %v, want %v", err, errcode.ErrPostNotFound)
+	}
+	if len(deps.removed) != 1 || deps.removed[0] != created.ID {
+		t.Errorf("热门排行移除的文章 = %v, want [%d]", deps.removed, created.ID)
+	}
+}
+
+func TestCommentCountFollowsComments(t *testing.T) {
+	ctx := context.Background()
+	posts, comments, _, users := newMemoryServices(t)
+	author, other := users[0], users[1]
+
+	created, err := posts.CreatePost(ctx, &CreatePostRequest{Title: "标题", Content: "内容"}, author.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var commentIDs []uint
+	for _, userID := range []uint{author.ID, other.ID} {
+		comment, err := comments.CreateComment(ctx, &CreateCommentRequest{PostID: created.ID, Content: "评论"}, userID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		commentIDs = append(commentIDs, comment.ID)
+	}
+	post, err := posts.GetPost(ctx, created.ID, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if post.CommentCount != 2 || !post.HasComments {
+		t.Fatalf("commentCount = %d hasComments = %v, want 2 true", post.CommentCount, post.HasComments)
+	}
+
+	//批量删除只删除自己的评论
+	deleted, err := comments.BatchDeleteComments(ctx, &BatchDeleteCommentRequest{CommentIDs: commentIDs}, other.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deleted != 1 {
+		t.Errorf("deleted = %d, want 1", deleted)
+	}
+	post, err = posts.GetPost(ctx, created.ID, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if post.CommentCount != 1 {
+		t.Errorf("commentCount = %d, want 1", post.CommentCount)
+	}
+}
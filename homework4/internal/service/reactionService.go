@@ -0,0 +1,493 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"homework4/internal/errcode"
+	"homework4/internal/models"
+	"homework4/internal/repository"
+	"homework4/pkg/logger"
+	"strconv"
+	"strings"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	reactionCountKeyPrefix     = "reaction:count:"             // 计数hash key前缀 reaction:count:{targetType}:{targetID} field为表情
+	reactionDirtyKey           = "reaction:dirty"              // 点赞数有变动待刷入数据库的目标集合 成员为{targetType}:{targetID}
+	reactionReconcileLockKey   = "reaction:reconcile:lock"     // 重建计数的锁 值为本次重建的标识
+	reactionReconcileTmpPrefix = "reaction:reconcile:tmp:"     // 重建中的计数 reaction:reconcile:tmp:{标识}:{targetType}:{targetID}
+	reactionJournalPrefix      = "reaction:reconcile:journal:" // 重建期间的计数变化 reaction:reconcile:journal:{标识}:{targetType}:{targetID}
+	reactionReconcileLockTTL   = 10 * time.Minute              // 重建计数的锁超时时间
+	reactionFlushBatch         = 500                           // 每次刷入数据库的最大目标数
+	reactionSwapBatch          = 100                           // 每次替换的计数key数
+)
+
+// 修改计数 计数不存在时先用数据库中的点赞数初始化 重建期间同时记录到本次重建的变化中
+// KEYS[1]计数key KEYS[2]待刷入集合 KEYS[3]重建锁
+// ARGV[1]表情 ARGV[2]变化量 ARGV[3]目标{targetType}:{targetID} ARGV[4]为1时标记待刷入 ARGV[5]初始点赞数 为空时不初始化
+// ARGV[6]重建期间的变化key前缀 ARGV[7]变化key的过期秒数
+var reactionIncrScript = goredis.NewScript(`
+if ARGV[5] ~= '' then
+	redis.call('HSETNX', KEYS[1], 'like', ARGV[5])
+end
+redis.call('HINCRBY', KEYS[1], ARGV[1], ARGV[2])
+if ARGV[4] == '1' then
+	redis.call('SADD', KEYS[2], ARGV[3])
+end
+local token = redis.call('GET', KEYS[3])
+if token then
+	local journal = ARGV[6] .. token .. ':' .. ARGV[3]
+	redis.call('HINCRBY', journal, ARGV[1], ARGV[2])
+	redis.call('EXPIRE', journal, ARGV[7])
+end
+return 1
+`)
+
+// 用重建的计数替换当前计数 再加上重建期间的变化 每个key的替换和累加在一个脚本中执行
+// KEYS依次为计数key、重建中的计数key、重建期间的变化key
+var reactionSwapScript = goredis.NewScript(`
+for i = 1, #KEYS, 3 do
+	if redis.call('EXISTS', KEYS[i + 1]) == 1 then
+		redis.call('RENAME', KEYS[i + 1], KEYS[i])
+	else
+		redis.call('DEL', KEYS[i])
+	end
+	local changes = redis.call('HGETALL', KEYS[i + 2])
+	for j = 1, #changes, 2 do
+		redis.call('HINCRBY', KEYS[i], changes[j], changes[j + 1])
+	end
+	redis.call('DEL', KEYS[i + 2])
+end
+return 1
+`)
+
+// 只释放属于本次重建的锁
+var reactionUnlockScript = goredis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+type ReactionService struct {
+	db     *gorm.DB
+	rdb    *goredis.Client
+	caches CounterCache // 更新点赞数后删除文章详情缓存
+}
+
+func NewReactionService(db *gorm.DB, rdb *goredis.Client, caches CounterCache) *ReactionService {
+	return &ReactionService{db: db, rdb: rdb, caches: caches}
+}
+
+// ReactionRequest 添加/取消表情回应请求
+type ReactionRequest struct {
+	TargetType string `json:"targetType" binding:"required,oneof=post comment" example:"post"`             // 目标类型 post-文章 comment-评论
+	TargetID   uint   `json:"targetId" binding:"required" example:"1"`                                     // 目标ID
+	Emoji      string `json:"emoji" binding:"omitempty,oneof=like love haha wow sad angry" example:"like"` // 表情 不传默认为like
+}
+
+// ReactionResponse 表情回应响应
+type ReactionResponse struct {
+	TargetType string           `json:"targetType" example:"post"` // 目标类型
+	TargetID   uint             `json:"targetId" example:"1"`      // 目标ID
+	Emoji      string           `json:"emoji" example:"like"`      // 表情
+	Reacted    bool             `json:"reacted" example:"true"`    // 当前用户是否已回应该表情
+	LikeCount  int64            `json:"likeCount" example:"10"`    // 点赞数
+	Reactions  map[string]int64 `json:"reactions"`                 // 各表情回应数
+}
+
+// ReactionSummary 列表中单个目标的回应汇总
+type ReactionSummary struct {
+	LikeCount int64            // 点赞数
+	Liked     bool             // 当前用户是否点赞
+	Reactions map[string]int64 // 各表情回应数
+}
+
+/**
+ * @Description: 添加表情回应 重复添加不会重复计数
+ * @param ctx
+ * @param req
+ * @param userID
+ * @return (*ReactionResponse, error)
+ */
+func (s *ReactionService) AddReaction(ctx context.Context, req *ReactionRequest, userID uint) (*ReactionResponse, error) {
+	emoji := normalizeEmoji(req.Emoji)
+	if err := s.checkTarget(ctx, req.TargetType, req.TargetID); err != nil {
+		return nil, err
+	}
+
+	reaction := &models.Reaction{
+		UserID:     userID,
+		TargetType: req.TargetType,
+		TargetID:   req.TargetID,
+		Emoji:      emoji,
+	}
+	//唯一索引冲突时不做处理 保证幂等
+	result := s.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(reaction)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected > 0 {
+		s.incrCounter(ctx, req.TargetType, req.TargetID, emoji, 1)
+	}
+
+	return s.buildResponse(ctx, req.TargetType, req.TargetID, emoji, true)
+}
+
+/**
+ * @Description: 取消表情回应 未回应过时直接返回 目标已删除时也可以取消
+ * @param ctx
+ * @param req
+ * @param userID
+ * @return (*ReactionResponse, error)
+ */
+func (s *ReactionService) RemoveReaction(ctx context.Context, req *ReactionRequest, userID uint) (*ReactionResponse, error) {
+	emoji := normalizeEmoji(req.Emoji)
+	//不检查目标是否存在 文章或评论删除后也可以取消回应
+	result := s.db.WithContext(ctx).Where("user_id = ? AND target_type = ? AND target_id = ? AND emoji = ?",
+		userID, req.TargetType, req.TargetID, emoji).Delete(&models.Reaction{})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected > 0 {
+		s.incrCounter(ctx, req.TargetType, req.TargetID, emoji, -1)
+	}
+
+	return s.buildResponse(ctx, req.TargetType, req.TargetID, emoji, false)
+}
+
+/**
+ * @Description: 批量获取目标的回应汇总 redis中没有计数时使用数据库中的点赞数
+ * @param ctx
+ * @param targetType 目标类型
+ * @param likeCounts 目标ID和数据库中的点赞数
+ * @param userID 当前用户ID 未登录为0
+ * @return map[uint]ReactionSummary
+ */
+func (s *ReactionService) GetSummaries(ctx context.Context, targetType string, likeCounts map[uint]uint64, userID uint) map[uint]ReactionSummary {
+	summaries := make(map[uint]ReactionSummary, len(likeCounts))
+	if len(likeCounts) == 0 {
+		return summaries
+	}
+
+	ids := make([]uint, 0, len(likeCounts))
+	for id := range likeCounts {
+		ids = append(ids, id)
+	}
+
+	cmds := make(map[uint]*goredis.MapStringStringCmd, len(ids))
+	pipe := s.rdb.Pipeline()
+	for _, id := range ids {
+		cmds[id] = pipe.HGetAll(ctx, reactionCountKey(targetType, id))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		logger.FromContext(ctx).Error("批量获取回应计数失败", logger.WrapMeta(err, logger.NewMeta("targetType", targetType))...)
+	}
+
+	for _, id := range ids {
+		summary := ReactionSummary{LikeCount: int64(likeCounts[id]), Reactions: map[string]int64{}}
+		if values, err := cmds[id].Result(); err == nil && len(values) > 0 {
+			summary.Reactions = parseCounts(values)
+			summary.LikeCount = summary.Reactions[models.ReactionLike]
+		} else if summary.LikeCount > 0 {
+			summary.Reactions[models.ReactionLike] = summary.LikeCount
+		}
+		summaries[id] = summary
+	}
+
+	if userID == 0 {
+		return summaries
+	}
+	var likedIDs []uint
+	if err := s.db.WithContext(ctx).Model(&models.Reaction{}).
+		Where("user_id = ? AND target_type = ? AND emoji = ? AND target_id IN ?", userID, targetType, models.ReactionLike, ids).
+		Pluck("target_id", &likedIDs).Error; err != nil {
+		logger.FromContext(ctx).Error("查询用户点赞状态失败", logger.WrapMeta(err, logger.NewMeta("userID", userID))...)
+		return summaries
+	}
+	for _, id := range likedIDs {
+		summary := summaries[id]
+		summary.Liked = true
+		summaries[id] = summary
+	}
+	return summaries
+}
+
+/**
+ * @Description: 根据回应表重新统计有变动的目标的点赞数 不使用redis中的计数 重复刷入结果相同
+ * @param ctx
+ * @return (int, error) 刷入的目标数
+ */
+func (s *ReactionService) FlushCounters(ctx context.Context) (int, error) {
+	flushed := 0
+	for {
+		members, err := s.rdb.SPopN(ctx, reactionDirtyKey, reactionFlushBatch).Result()
+		if err != nil {
+			return flushed, err
+		}
+		if len(members) == 0 {
+			return flushed, nil
+		}
+		var postIDs []uint
+		for _, member := range members {
+			targetType, targetID, ok := parseTarget(member)
+			if !ok {
+				continue
+			}
+			if err := s.recountLikes(s.db.WithContext(ctx).Where("id = ?", targetID), targetType); err != nil {
+				//刷入失败重新标记 等待下次刷入
+				s.rdb.SAdd(ctx, reactionDirtyKey, member)
+				s.caches.InvalidatePosts(ctx, postIDs...)
+				return flushed, err
+			}
+			if targetType == models.ReactionTargetPost {
+				postIDs = append(postIDs, targetID)
+			}
+			flushed++
+		}
+		//评论没有缓存 只删除文章详情缓存
+		s.caches.InvalidatePosts(ctx, postIDs...)
+	}
+}
+
+/**
+ * @Description: 根据回应表重建redis计数和数据库点赞数 多个实例同时执行时只有获取到锁的实例重建
+ * 重建期间的计数变化另外记录 替换计数时再累加 重建不会覆盖重建期间的点赞
+ * @param ctx
+ * @return (bool, error) 是否执行了重建 其它实例正在重建时为false
+ */
+func (s *ReactionService) ReconcileCounters(ctx context.Context) (bool, error) {
+	token, err := randomToken()
+	if err != nil {
+		return false, err
+	}
+	locked, err := s.rdb.SetNX(ctx, reactionReconcileLockKey, token, reactionReconcileLockTTL).Result()
+	if err != nil || !locked {
+		return false, err
+	}
+	defer func() {
+		//先释放锁 之后不会再记录本次重建的变化 再删除没有被替换的key的变化
+		ctx := context.WithoutCancel(ctx)
+		reactionUnlockScript.Run(ctx, s.rdb, []string{reactionReconcileLockKey}, token)
+		s.deleteKeys(ctx, reactionJournalPrefix+token+":*")
+		s.deleteKeys(ctx, reactionReconcileTmpPrefix+token+":*")
+	}()
+
+	type reactionCount struct {
+		TargetType string
+		TargetID   uint
+		Emoji      string
+		Total      int64
+	}
+	var rows []reactionCount
+	if err := s.db.WithContext(ctx).Model(&models.Reaction{}).
+		Select("target_type, target_id, emoji, COUNT(*) AS total").
+		Group("target_type, target_id, emoji").
+		Scan(&rows).Error; err != nil {
+		return false, err
+	}
+
+	//重建的计数先写入临时key
+	targets := make(map[string]bool)
+	pipe := s.rdb.Pipeline()
+	for _, row := range rows {
+		target := reactionMember(row.TargetType, row.TargetID)
+		targets[target] = true
+		tmpKey := reactionReconcileTmpPrefix + token + ":" + target
+		pipe.HSet(ctx, tmpKey, row.Emoji, row.Total)
+		pipe.Expire(ctx, tmpKey, reactionReconcileLockTTL)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return false, err
+	}
+
+	//回应表中已不存在的计数也需要替换(删除)
+	iter := s.rdb.Scan(ctx, 0, reactionCountKeyPrefix+"*", 1000).Iterator()
+	for iter.Next(ctx) {
+		targets[strings.TrimPrefix(iter.Val(), reactionCountKeyPrefix)] = true
+	}
+	if err := iter.Err(); err != nil {
+		return false, err
+	}
+
+	keys := make([]string, 0, reactionSwapBatch*3)
+	for target := range targets {
+		keys = append(keys, reactionCountKeyPrefix+target, reactionReconcileTmpPrefix+token+":"+target, reactionJournalPrefix+token+":"+target)
+		if len(keys) == cap(keys) {
+			if err := reactionSwapScript.Run(ctx, s.rdb, keys).Err(); err != nil {
+				return false, err
+			}
+			keys = keys[:0]
+		}
+	}
+	if len(keys) > 0 {
+		if err := reactionSwapScript.Run(ctx, s.rdb, keys).Err(); err != nil {
+			return false, err
+		}
+	}
+
+	//重建数据库中的点赞数 和刷入一样按回应表统计 与刷入同时执行结果也相同
+	for _, targetType := range []string{models.ReactionTargetPost, models.ReactionTargetComment} {
+		if err := s.recountLikes(s.db.WithContext(ctx).Session(&gorm.Session{AllowGlobalUpdate: true}), targetType); err != nil {
+			return false, err
+		}
+	}
+	s.caches.FlushPosts(ctx)
+	return true, nil
+}
+
+// 根据回应表统计点赞数 db中为要更新的目标的条件
+func (s *ReactionService) recountLikes(db *gorm.DB, targetType string) error {
+	model := targetModel(targetType)
+	likeCount := s.db.Model(&models.Reaction{}).Select("COUNT(*)").
+		Where("target_type = ? AND emoji = ? AND target_id = ?", targetType, models.ReactionLike,
+			gorm.Expr(repository.TableName(s.db, model)+".id"))
+	return db.Model(model).UpdateColumn("like_count", likeCount).Error
+}
+
+// 删除匹配的key
+func (s *ReactionService) deleteKeys(ctx context.Context, pattern string) {
+	iter := s.rdb.Scan(ctx, 0, pattern, 1000).Iterator()
+	for iter.Next(ctx) {
+		s.rdb.Del(ctx, iter.Val())
+	}
+}
+
+// 检查回应的目标是否存在
+func (s *ReactionService) checkTarget(ctx context.Context, targetType string, targetID uint) error {
+	err := s.db.WithContext(ctx).Select("id").First(targetModel(targetType), targetID).Error
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		if targetType == models.ReactionTargetComment {
+			return errcode.ErrCommentNotFound
+		}
+		return errcode.ErrPostNotFound
+	}
+	return err
+}
+
+// 修改redis计数 点赞时标记待刷入 回应已经写入数据库 不随请求取消 失败时只记录日志 由定时重建修正
+// redis数据丢失后计数不存在 点赞数先用数据库中的值初始化 不会从1开始
+func (s *ReactionService) incrCounter(ctx context.Context, targetType string, targetID uint, emoji string, delta int64) {
+	ctx = context.WithoutCancel(ctx)
+	key := reactionCountKey(targetType, targetID)
+	//数据库中只保存点赞数
+	markDirty, seed := "", ""
+	if emoji == models.ReactionLike {
+		markDirty = "1"
+	}
+	exists, err := s.rdb.Exists(ctx, key).Result()
+	if err == nil && exists == 0 {
+		seed, err = s.likeCountSeed(ctx, targetType, targetID)
+	}
+	if err == nil {
+		err = reactionIncrScript.Run(ctx, s.rdb, []string{key, reactionDirtyKey, reactionReconcileLockKey},
+			emoji, delta, reactionMember(targetType, targetID), markDirty, seed,
+			reactionJournalPrefix, int(reactionReconcileLockTTL.Seconds())).Err()
+	}
+	if err != nil {
+		logger.FromContext(ctx).Error("更新回应计数失败", logger.WrapMeta(err,
+			logger.NewMeta("targetType", targetType),
+			logger.NewMeta("targetID", targetID),
+			logger.NewMeta("emoji", emoji),
+		)...)
+	}
+}
+
+// 数据库中的点赞数 用于初始化redis计数 目标已删除时不初始化
+func (s *ReactionService) likeCountSeed(ctx context.Context, targetType string, targetID uint) (string, error) {
+	var likeCounts []uint64
+	if err := s.db.WithContext(ctx).Model(targetModel(targetType)).Where("id = ?", targetID).
+		Pluck("like_count", &likeCounts).Error; err != nil {
+		return "", err
+	}
+	if len(likeCounts) == 0 {
+		return "", nil
+	}
+	return strconv.FormatUint(likeCounts[0], 10), nil
+}
+
+// 构建回应响应
+func (s *ReactionService) buildResponse(ctx context.Context, targetType string, targetID uint, emoji string, reacted bool) (*ReactionResponse, error) {
+	values, err := s.rdb.HGetAll(ctx, reactionCountKey(targetType, targetID)).Result()
+	if err != nil {
+		return nil, err
+	}
+	reactions := parseCounts(values)
+	return &ReactionResponse{
+		TargetType: targetType,
+		TargetID:   targetID,
+		Emoji:      emoji,
+		Reacted:    reacted,
+		LikeCount:  reactions[models.ReactionLike],
+		Reactions:  reactions,
+	}, nil
+}
+
+// 重建计数的标识 区分不同实例的重建
+func randomToken() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func normalizeEmoji(emoji string) string {
+	if emoji == "" {
+		return models.ReactionLike
+	}
+	return emoji
+}
+
+func reactionCountKey(targetType string, targetID uint) string {
+	return fmt.Sprintf("%s%s:%d", reactionCountKeyPrefix, targetType, targetID)
+}
+
+// 待刷入集合成员 {targetType}:{targetID}
+func reactionMember(targetType string, targetID uint) string {
+	return fmt.Sprintf("%s:%d", targetType, targetID)
+}
+
+// 解析待刷入集合成员 {targetType}:{targetID}
+func parseTarget(member string) (string, uint, bool) {
+	targetType, idStr, ok := strings.Cut(member, ":")
+	if !ok {
+		return "", 0, false
+	}
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		return "", 0, false
+	}
+	return targetType, uint(id), true
+}
+
+// 解析redis计数 过滤掉小于等于0的表情
+func parseCounts(values map[string]string) map[string]int64 {
+	counts := make(map[string]int64, len(values))
+	for emoji, value := range values {
+		count, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || count <= 0 {
+			continue
+		}
+		counts[emoji] = count
+	}
+	return counts
+}
+
+func targetModel(targetType string) interface{} {
+	if targetType == models.ReactionTargetComment {
+		return &models.Comment{}
+	}
+	return &models.Post{}
+}
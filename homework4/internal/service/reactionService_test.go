@@ -0,0 +1,227 @@
+package service
+
+import (
+	"context"
+	"homework4/config"
+	"homework4/internal/app/database"
+	"homework4/internal/models"
+	"net"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	goredis "github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// 记录删除的文章缓存
+type recordingCounterCache struct {
+	posts   []uint
+	flushed int
+}
+
+func (c *recordingCounterCache) InvalidatePosts(ctx context.Context, postIDs ...uint) {
+	c.posts = append(c.posts, postIDs...)
+}
+
+func (c *recordingCounterCache) InvalidateUsers(ctx context.Context, userIDs ...uint) {}
+
+func (c *recordingCounterCache) FlushPosts(ctx context.Context) { c.flushed++ }
+
+// 执行迁移后的sqlite内存数据库和miniredis
+func newTestStores(t *testing.T) (*gorm.DB, *goredis.Client, *miniredis.Miniredis) {
+	t.Helper()
+	cfg := config.DatabaseConfig{Driver: config.DriverSQLite, DBName: ":memory:"}
+	db, err := database.Open(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	migrator, err := database.NewMigrator(db, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := migrator.Up(context.Background(), 0); err != nil {
+		t.Fatal(err)
+	}
+	mr := miniredis.RunT(t)
+	rdb := goredis.NewClient(&goredis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { rdb.Close() })
+	return db, rdb, mr
+}
+
+func newReactionTestService(t *testing.T) (*ReactionService, *gorm.DB, *miniredis.Miniredis) {
+	t.Helper()
+	db, rdb, mr := newTestStores(t)
+	return NewReactionService(db, rdb, &recordingCounterCache{}), db, mr
+}
+
+func createTestPost(t *testing.T, db *gorm.DB, likeCount uint64) *models.Post {
+	t.Helper()
+	post := &models.Post{UserID: 1, Title: "标题", Content: "内容", LikeCount: likeCount}
+	if err := db.Create(post).Error; err != nil {
+		t.Fatal(err)
+	}
+	return post
+}
+
+func likePost(t *testing.T, s *ReactionService, postID uint, userID uint) *ReactionResponse {
+	t.Helper()
+	resp, err := s.AddReaction(context.Background(), &ReactionRequest{TargetType: models.ReactionTargetPost, TargetID: postID}, userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp
+}
+
+func postLikeCount(t *testing.T, db *gorm.DB, postID uint) uint64 {
+	t.Helper()
+	var post models.Post
+	if err := db.First(&post, postID).Error; err != nil {
+		t.Fatal(err)
+	}
+	return post.LikeCount
+}
+
+func TestLikeSeedsCounterFromDatabaseAfterRedisLoss(t *testing.T) {
+	s, db, _ := newReactionTestService(t)
+	//redis中没有计数 数据库中已有5个点赞
+	post := createTestPost(t, db, 5)
+
+	if resp := likePost(t, s, post.ID, 1); resp.LikeCount != 6 {
+		t.Errorf("likeCount = %d, want 6", resp.LikeCount)
+	}
+	summaries := s.GetSummaries(context.Background(), models.ReactionTargetPost, map[uint]uint64{post.ID: 5}, 0)
+	if got := summaries[post.ID].LikeCount; got != 6 {
+		t.Errorf("summary likeCount = %d, want 6", got)
+	}
+}
+
+func TestFlushRecountsLikesFromReactions(t *testing.T) {
+	s, db, mr := newReactionTestService(t)
+	post := createTestPost(t, db, 0)
+	for userID := uint(1); userID <= 3; userID++ {
+		likePost(t, s, post.ID, userID)
+	}
+	//redis计数丢失后重新从数据库初始化 刷入仍按回应表统计
+	mr.Del(reactionCountKey(models.ReactionTargetPost, post.ID))
+	likePost(t, s, post.ID, 4)
+
+	for i := 0; i < 2; i++ {
+		if _, err := s.FlushCounters(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		if got := postLikeCount(t, db, post.ID); got != 4 {
+			t.Fatalf("第%d次刷入后 like_count = %d, want 4", i+1, got)
+		}
+	}
+	//更新点赞数后删除文章详情缓存
+	if caches := s.caches.(*recordingCounterCache); len(caches.posts) == 0 || caches.posts[0] != post.ID {
+		t.Errorf("删除的文章缓存 = %v, want [%d]", caches.posts, post.ID)
+	}
+}
+
+func TestRemoveReactionFromDeletedPost(t *testing.T) {
+	s, db, _ := newReactionTestService(t)
+	post := createTestPost(t, db, 0)
+	likePost(t, s, post.ID, 1)
+	if err := db.Delete(post).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := s.RemoveReaction(context.Background(), &ReactionRequest{TargetType: models.ReactionTargetPost, TargetID: post.ID}, 1)
+	if err != nil {
+		t.Fatalf("取消已删除文章的点赞失败: %v", err)
+	}
+	if resp.LikeCount != 0 {
+		t.Errorf("likeCount = %d, want 0", resp.LikeCount)
+	}
+	var remaining int64
+	db.Model(&models.Reaction{}).Where("target_id = ?", post.ID).Count(&remaining)
+	if remaining != 0 {
+		t.Errorf("剩余回应 = %d, want 0", remaining)
+	}
+}
+
+func TestReconcileKeepsReactionsDuringRebuild(t *testing.T) {
+	s, db, mr := newReactionTestService(t)
+	post := createTestPost(t, db, 0)
+	stale := createTestPost(t, db, 0)
+	likePost(t, s, post.ID, 1)
+	//回应表中没有的计数在重建时删除
+	mr.HSet(reactionCountKey(models.ReactionTargetPost, stale.ID), models.ReactionLike, "7")
+
+	//统计回应表之后 替换计数之前有新的点赞 重建在统计后扫描已有的计数key
+	hook := &likeOnScanHook{like: func() { likePost(t, s, post.ID, 2) }}
+	s.rdb.AddHook(hook)
+
+	reconciled, err := s.ReconcileCounters(context.Background())
+	if err != nil || !reconciled {
+		t.Fatalf("reconciled = %v err = %v", reconciled, err)
+	}
+	if !hook.injected {
+		t.Fatal("没有在重建期间点赞")
+	}
+	if got := mr.HGet(reactionCountKey(models.ReactionTargetPost, post.ID), models.ReactionLike); got != "2" {
+		t.Errorf("redis like = %q, want 2", got)
+	}
+	if mr.Exists(reactionCountKey(models.ReactionTargetPost, stale.ID)) {
+		t.Error("回应表中没有的计数没有删除")
+	}
+	if got := postLikeCount(t, db, post.ID); got != 2 {
+		t.Errorf("like_count = %d, want 2", got)
+	}
+	if s.caches.(*recordingCounterCache).flushed != 1 {
+		t.Error("重建点赞数后没有清除文章缓存")
+	}
+	//重建后刷入结果不变
+	if _, err := s.FlushCounters(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := postLikeCount(t, db, post.ID); got != 2 {
+		t.Errorf("刷入后 like_count = %d, want 2", got)
+	}
+	if keys := mr.Keys(); len(keys) != 1 {
+		t.Errorf("重建后剩余的key = %v, want 只有计数", keys)
+	}
+}
+
+func TestReconcileSkipsWhenLocked(t *testing.T) {
+	s, _, mr := newReactionTestService(t)
+	mr.Set(reactionReconcileLockKey, "other")
+
+	reconciled, err := s.ReconcileCounters(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reconciled {
+		t.Error("其它实例持有锁时不应重建")
+	}
+	if got, _ := mr.Get(reactionReconcileLockKey); got != "other" {
+		t.Errorf("锁被修改为 %q", got)
+	}
+}
+
+// 第一次执行SCAN时点赞一次
+type likeOnScanHook struct {
+	like     func()
+	injected bool
+}
+
+func (h *likeOnScanHook) DialHook(next goredis.DialHook) goredis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return next(ctx, network, addr)
+	}
+}
+
+func (h *likeOnScanHook) ProcessHook(next goredis.ProcessHook) goredis.ProcessHook {
+	return func(ctx context.Context, cmd goredis.Cmder) error {
+		if cmd.Name() == "scan" && !h.injected {
+			h.injected = true
+			h.like()
+		}
+		return next(ctx, cmd)
+	}
+}
+
+func (h *likeOnScanHook) ProcessPipelineHook(next goredis.ProcessPipelineHook) goredis.ProcessPipelineHook {
+	return next
+}
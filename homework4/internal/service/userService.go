@@ -1,29 +1,34 @@
 package service
 
 import (
+	"context"
 	"errors"
-	"homework4/internal/app/mysql"
-	"homework4/internal/middleware/auth"
+	"homework4/internal/errcode"
+	"homework4/internal/metrics"
 	"homework4/internal/models"
+	"homework4/internal/repository"
+	"strconv"
+	"time"
 
 	"golang.org/x/crypto/bcrypt"
-	"gorm.io/gorm"
 )
 
 type UserService struct {
-	db *gorm.DB
+	users   repository.UserRepository
+	follows FollowCounter
+	tokens  TokenIssuer
 }
 
-func NewUserService() *UserService {
-	return &UserService{db: mysql.DB}
+func NewUserService(users repository.UserRepository, follows FollowCounter, tokens TokenIssuer) *UserService {
+	return &UserService{users: users, follows: follows, tokens: tokens}
 }
 
 // RegisterRequest 用户注册请求
 type RegisterRequest struct {
-	Username string `json:"username" binding:"required,min=3,max=20" example:"testuser"` // 用户名 必传，3-20个字符
-	Nickname string `json:"nickname" binding:"required,min=1,max=64" example:"测试用户"`     // 昵称 必传，1-64个字符
-	Password string `json:"password" binding:"required,min=6" example:"123456"`          // 密码 必传，至少6个字符
-	Email    string `json:"email" binding:"omitempty,email" example:"test@example.com"`  // 邮箱，可选，格式校验
+	Username string `json:"username" binding:"required,min=3,max=20,username" example:"testuser"` // 用户名 必传，3-20个字母、数字或下划线
+	Nickname string `json:"nickname" binding:"required,min=1,max=64,banned" example:"测试用户"`       // 昵称 必传，1-64个字符
+	Password string `json:"password" binding:"required,min=6,password" example:"abc123"`          // 密码 必传，至少6个字符，同时包含字母和数字
+	Email    string `json:"email" binding:"omitempty,email" example:"test@example.com"`           // 邮箱，可选，格式校验
 }
 
 // LoginRequest 用户登录请求
@@ -40,17 +45,59 @@ type LoginResponse struct {
 	Nickname string `json:"nickname" example:"测试用户"`                                 // 昵称
 }
 
+// GetProfileRequest 获取用户公开资料请求
+type GetProfileRequest struct {
+	UserID uint `form:"userId" binding:"required" example:"1"` // 用户ID
+}
+
+// ProfileResponse 用户公开资料响应
+type ProfileResponse struct {
+	UserID         uint   `json:"userId" example:"1"`                      // 用户ID
+	Username       string `json:"username" example:"testuser"`             // 用户名
+	Nickname       string `json:"nickname" example:"测试用户"`                 // 昵称
+	FollowerCount  int64  `json:"followerCount" example:"10"`              // 粉丝数
+	FollowingCount int64  `json:"followingCount" example:"5"`              // 关注数
+	Followed       bool   `json:"followed" example:"false"`                // 当前用户是否已关注 未登录为false
+	CreatedAt      string `json:"createdAt" example:"2024-01-01 12:00:00"` // 注册时间
+}
+
+// UserBriefResponse 用户公开信息
+type UserBriefResponse struct {
+	ID        uint   `json:"id" example:"1"`                          // 用户ID
+	Username  string `json:"username" example:"testuser"`             // 用户名
+	Nickname  string `json:"nickname" example:"测试用户"`                 // 昵称
+	PostCount uint32 `json:"postCount" example:"3"`                   // 文章数
+	CreatedAt string `json:"createdAt" example:"2024-01-01 12:00:00"` // 注册时间
+}
+
 /**
  * @Description: 注册用户
+ * @param ctx
  * @param req
  * @return (*models.User, error)
  */
-func (s *UserService) Register(req *RegisterRequest) (*models.User, error) {
-	var existingUser models.User
+func (s *UserService) Register(ctx context.Context, req *RegisterRequest) (*models.User, error) {
+	user, err := s.CreateUser(ctx, req, false)
+	if err != nil {
+		return nil, err
+	}
+	metrics.Registrations.Inc()
+	return user, nil
+}
+
+/**
+ * @Description: 创建用户 管理员只能通过管理命令创建
+ * @param ctx
+ * @param req
+ * @param admin 是否管理员
+ * @return (*models.User, error)
+ */
+func (s *UserService) CreateUser(ctx context.Context, req *RegisterRequest, admin bool) (*models.User, error) {
 	//判断用户名是否已经存在
-	result := s.db.Where(&models.User{Username: req.Username}).First(&existingUser)
-	if result.Error == nil {
-		return nil, errors.New("用户名已存在")
+	if _, err := s.users.FindByUsername(ctx, req.Username); err == nil {
+		return nil, errcode.ErrUsernameExists
+	} else if !errors.Is(err, repository.ErrNotFound) {
+		return nil, err
 	}
 	//加密明文密码
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
@@ -63,9 +110,10 @@ func (s *UserService) Register(req *RegisterRequest) (*models.User, error) {
 		Nickname: req.Nickname,
 		Password: string(hashedPassword),
 		Email:    req.Email,
+		IsAdmin:  admin,
 	}
 	//创建用户
-	if err := s.db.Create(user).Error; err != nil {
+	if err := s.users.Create(ctx, user); err != nil {
 		return nil, err
 	}
 	return user, nil
@@ -73,27 +121,33 @@ func (s *UserService) Register(req *RegisterRequest) (*models.User, error) {
 
 /**
  * @Description: 用户登录
+ * @param ctx
  * @param req
  * @return (*LoginResponse, error)
  */
-func (s *UserService) Login(req *LoginRequest) (*LoginResponse, error) {
-	var user models.User
+func (s *UserService) Login(ctx context.Context, req *LoginRequest) (*LoginResponse, error) {
 	//根据用户名查询用户
-	result := s.db.Where(&models.User{Username: req.Username}).First(&user)
-	if result.Error != nil {
-		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
-			return nil, errors.New("用户名或密码错误")
+	user, err := s.users.FindByUsername(ctx, req.Username)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			metrics.Logins.WithLabelValues(metrics.LoginFailure).Inc()
+			return nil, errcode.ErrLoginFailed
 		}
-		return nil, result.Error
+		return nil, err
 	}
 
 	//密码对比
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
-		return nil, errors.New("用户名或密码错误")
+		metrics.Logins.WithLabelValues(metrics.LoginFailure).Inc()
+		return nil, errcode.ErrLoginFailed
 	}
 
 	//生成token
-	token := auth.GenerateToken(user.ID, user.Username, user.Nickname)
+	token, err := s.tokens.IssueToken(ctx, user.ID, user.Username, user.Nickname)
+	if err != nil {
+		return nil, err
+	}
+	metrics.Logins.WithLabelValues(metrics.LoginSuccess).Inc()
 
 	//返回token信息和用户信息
 	return &LoginResponse{
@@ -103,3 +157,142 @@ func (s *UserService) Login(req *LoginRequest) (*LoginResponse, error) {
 		Nickname: user.Nickname,
 	}, nil
 }
+
+/**
+ * @Description: 获取用户公开资料
+ * @param ctx
+ * @param req
+ * @param viewerID 当前登录用户ID 未登录为0
+ * @return (*ProfileResponse, error)
+ */
+func (s *UserService) GetProfile(ctx context.Context, req *GetProfileRequest, viewerID uint) (*ProfileResponse, error) {
+	user, err := s.users.FindByID(ctx, req.UserID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, errcode.ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	followerCount, followingCount, err := s.follows.CountFollows(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	followed := false
+	if viewerID > 0 && viewerID != user.ID {
+		if followed, err = s.follows.IsFollowing(ctx, viewerID, user.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	return &ProfileResponse{
+		UserID:         user.ID,
+		Username:       user.Username,
+		Nickname:       user.Nickname,
+		FollowerCount:  followerCount,
+		FollowingCount: followingCount,
+		Followed:       followed,
+		CreatedAt:      user.CreatedAt.Format(time.DateTime),
+	}, nil
+}
+
+/**
+ * @Description: 重置用户密码 同时吊销登录令牌 用户需要重新登录
+ * @param ctx
+ * @param username
+ * @param password 新的明文密码
+ * @return error
+ */
+func (s *UserService) ResetPassword(ctx context.Context, username string, password string) error {
+	user, err := s.FindUser(ctx, username)
+	if err != nil {
+		return err
+	}
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	if err := s.users.Update(ctx, user.ID, map[string]interface{}{"password": string(hashedPassword)}); err != nil {
+		return err
+	}
+	return s.tokens.RevokeToken(ctx, user.ID)
+}
+
+/**
+ * @Description: 吊销用户的登录令牌
+ * @param ctx
+ * @param userID
+ * @return error
+ */
+func (s *UserService) RevokeToken(ctx context.Context, userID uint) error {
+	return s.tokens.RevokeToken(ctx, userID)
+}
+
+/**
+ * @Description: 根据用户ID或用户名查询用户 纯数字时先按ID查询
+ * @param ctx
+ * @param idOrUsername
+ * @return (*models.User, error)
+ */
+func (s *UserService) FindUser(ctx context.Context, idOrUsername string) (*models.User, error) {
+	if id, err := strconv.ParseUint(idOrUsername, 10, 64); err == nil {
+		user, err := s.users.FindByID(ctx, uint(id))
+		if err == nil {
+			return user, nil
+		}
+		if !errors.Is(err, repository.ErrNotFound) {
+			return nil, err
+		}
+	}
+	user, err := s.users.FindByUsername(ctx, idOrUsername)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, errcode.ErrUserNotFound
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+/**
+ * @Description: 批量获取用户公开信息 走用户缓存 不存在的用户不返回
+ * @param ctx
+ * @param userIDs
+ * @return ([]UserBriefResponse, error)
+ */
+func (s *UserService) GetUsersByIDs(ctx context.Context, userIDs []uint) ([]UserBriefResponse, error) {
+	users, err := s.users.FindByIDs(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	briefs := make([]UserBriefResponse, len(users))
+	for i, user := range users {
+		briefs[i] = UserBriefResponse{
+			ID:        user.ID,
+			Username:  user.Username,
+			Nickname:  user.Nickname,
+			PostCount: user.PostCount,
+			CreatedAt: user.CreatedAt.Format(time.DateTime),
+		}
+	}
+	return briefs, nil
+}
+
+/**
+ * @Description: 是否管理员 管理员由管理命令创建 可以删除其他用户的文章和评论
+ * @param ctx
+ * @param users
+ * @param userID
+ * @return (bool, error) 用户不存在时为false
+ */
+func isAdmin(ctx context.Context, users repository.UserRepository, userID uint) (bool, error) {
+	user, err := users.FindByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return user.IsAdmin, nil
+}
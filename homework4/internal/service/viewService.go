@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"homework4/internal/models"
+	"homework4/pkg/logger"
+	"strconv"
+	"strings"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	viewHLLKeyPrefix = "post:view:hll:"  // 每篇文章每天的访客HyperLogLog post:view:hll:{postID}:{yyyyMMdd}
+	viewDirtyKey     = "post:view:dirty" // 浏览量有变动待刷入数据库的集合 成员为{postID}:{yyyyMMdd}
+	viewDayLayout    = "20060102"
+	viewKeyTTL       = 48 * time.Hour // 当天的访客数据保留两天 保证跨天后还能刷入
+	viewFlushBatch   = 500            // 每次刷入数据库的最大数量
+)
+
+type ViewService struct {
+	db     *gorm.DB
+	rdb    *goredis.Client
+	caches CounterCache // 刷入浏览量后删除文章详情缓存
+}
+
+func NewViewService(db *gorm.DB, rdb *goredis.Client, caches CounterCache) *ViewService {
+	return &ViewService{db: db, rdb: rdb, caches: caches}
+}
+
+/**
+ * @Description: 记录文章浏览 同一访客每天只计一次 失败时只记录日志
+ * @param ctx
+ * @param postID
+ * @param visitor 访客标识 登录用户为u:{userID} 未登录为ip:{ip}
+ */
+func (s *ViewService) RecordView(ctx context.Context, postID uint, visitor string) {
+	day := time.Now().Format(viewDayLayout)
+	key := viewHLLKey(postID, day)
+
+	pipe := s.rdb.TxPipeline()
+	added := pipe.PFAdd(ctx, key, visitor)
+	pipe.Expire(ctx, key, viewKeyTTL)
+	if _, err := pipe.Exec(ctx); err != nil {
+		logger.FromContext(ctx).Error("记录文章浏览失败", logger.WrapMeta(err, logger.NewMeta("postID", postID))...)
+		return
+	}
+	//访客去重后有新增才需要刷入
+	if added.Val() == 1 {
+		s.rdb.SAdd(ctx, viewDirtyKey, viewMember(postID, day))
+	}
+}
+
+/**
+ * @Description: 将redis中新增的浏览量刷入数据库
+ * @param ctx
+ * @return (int, error) 刷入的文章数
+ */
+func (s *ViewService) FlushViews(ctx context.Context) (int, error) {
+	flushed := 0
+	for {
+		members, err := s.rdb.SPopN(ctx, viewDirtyKey, viewFlushBatch).Result()
+		if err != nil {
+			return flushed, err
+		}
+		if len(members) == 0 {
+			return flushed, s.cleanFlushRecords(ctx)
+		}
+		var postIDs []uint
+		for _, member := range members {
+			postID, day, ok := parseViewMember(member)
+			if !ok {
+				continue
+			}
+			updated, err := s.flushView(ctx, postID, day)
+			if err != nil {
+				//刷入失败重新标记 等待下次刷入
+				s.rdb.SAdd(ctx, viewDirtyKey, member)
+				s.caches.InvalidatePosts(ctx, postIDs...)
+				return flushed, err
+			}
+			if updated {
+				postIDs = append(postIDs, postID)
+			}
+			flushed++
+		}
+		//每批刷入后删除浏览量有变化的文章详情缓存 热门文章的缓存最多每个刷入间隔失效一次
+		s.caches.InvalidatePosts(ctx, postIDs...)
+	}
+}
+
+// 刷入单篇文章某天的浏览量 已刷入的数量和浏览量在同一事务中更新 只累加和上次刷入的差值 重复刷入不会多计
+// 返回是否更新了文章的浏览量
+func (s *ViewService) flushView(ctx context.Context, postID uint, day string) (bool, error) {
+	count, err := s.rdb.PFCount(ctx, viewHLLKey(postID, day)).Result()
+	if err != nil {
+		return false, err
+	}
+	conflict, updated := false, false
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		record := &models.PostViewFlush{PostID: postID, Day: day}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(record).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("post_id = ? AND day = ?", postID, day).Take(record).Error; err != nil {
+			return err
+		}
+		delta := count - record.FlushedCount
+		if delta <= 0 {
+			return nil
+		}
+		//只在已刷入数量没有被其它实例修改时更新
+		result := tx.Model(&models.PostViewFlush{}).
+			Where("post_id = ? AND day = ? AND flushed_count = ?", postID, day, record.FlushedCount).
+			UpdateColumn("flushed_count", count)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			conflict = true
+			return nil
+		}
+		updated = true
+		return tx.Model(&models.Post{}).Where("id = ?", postID).
+			UpdateColumn("view_count", gorm.Expr("view_count + ?", delta)).Error
+	})
+	if err != nil {
+		return false, err
+	}
+	//其它实例同时刷入了这篇文章 重新标记 下次按最新的访客数再比较
+	if conflict {
+		s.rdb.SAdd(ctx, viewDirtyKey, viewMember(postID, day))
+	}
+	return updated, nil
+}
+
+// 删除过期的刷入记录 访客数据过期后不会再刷入这一天
+func (s *ViewService) cleanFlushRecords(ctx context.Context) error {
+	expiredDay := time.Now().Add(-viewKeyTTL).Format(viewDayLayout)
+	return s.db.WithContext(ctx).Where("day < ?", expiredDay).Delete(&models.PostViewFlush{}).Error
+}
+
+func viewHLLKey(postID uint, day string) string {
+	return fmt.Sprintf("%s%d:%s", viewHLLKeyPrefix, postID, day)
+}
+
+// 待刷入集合成员 {postID}:{yyyyMMdd}
+func viewMember(postID uint, day string) string {
+	return fmt.Sprintf("%d:%s", postID, day)
+}
+
+// 解析待刷入集合成员 {postID}:{yyyyMMdd}
+func parseViewMember(member string) (uint, string, bool) {
+	idStr, day, ok := strings.Cut(member, ":")
+	if !ok {
+		return 0, "", false
+	}
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		return 0, "", false
+	}
+	return uint(id), day, true
+}
@@ -0,0 +1,71 @@
+package tracing
+
+/**
+ * @Description: OpenTelemetry链路追踪 HTTP请求、gorm语句和redis命令各生成一个span 使用W3C trace context传播
+ */
+import (
+	"context"
+	"fmt"
+	"homework4/config"
+	"homework4/pkg/logger"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+const (
+	ExporterNone   = "none"   // 不导出 仍然解析和传播请求头中的trace context
+	ExporterStdout = "stdout" // 输出到标准输出 用于本地调试
+	ExporterOTLP   = "otlp"   // 通过OTLP/HTTP导出到collector
+)
+
+/**
+ * @Description: 初始化全局TracerProvider和传播器
+ * @param cfg
+ * @return (func(context.Context) error, error) 退出时调用 导出剩余的span
+ */
+func Init(cfg config.TracingConfig) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch cfg.Exporter {
+	case ExporterNone, "":
+		return func(context.Context) error { return nil }, nil
+	case ExporterStdout:
+		exporter, err = stdouttrace.New()
+	case ExporterOTLP:
+		opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(cfg.Endpoint)}
+		if cfg.Insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		exporter, err = otlptracehttp.New(context.Background(), opts...)
+	default:
+		return nil, fmt.Errorf("不支持的链路追踪导出方式: %s", cfg.Exporter)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(attribute.String("service.name", cfg.ServiceName)))
+	if err != nil {
+		return nil, err
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		//上游已采样的请求跟随上游 否则按比例采样
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	logger.AppLog.Info("链路追踪初始化成功", logger.WrapMeta(nil,
+		logger.NewMeta("exporter", cfg.Exporter),
+		logger.NewMeta("sampleRatio", cfg.SampleRatio),
+	)...)
+	return provider.Shutdown, nil
+}
@@ -0,0 +1,93 @@
+package validation
+
+/**
+ * @Description: 参数错误转换为字段级的错误详情 提示按语言本地化
+ */
+import (
+	"encoding/json"
+	"errors"
+	"homework4/internal/errcode"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// FieldError 单个字段的错误 也可以在controller中手动返回
+type FieldError struct {
+	Field   string `json:"field" example:"username"`             // 字段名 与请求中的json或查询参数名一致
+	Rule    string `json:"rule" example:"min"`                   // 不满足的校验规则
+	Param   string `json:"param,omitempty" example:"3"`          // 校验规则的参数
+	Message string `json:"message" example:"username长度不能少于3个字符"` // 本地化的提示
+
+	kind reflect.Kind // 字段类型 决定min、max等规则的提示
+}
+
+func (e *FieldError) Error() string {
+	return e.Field + " " + e.Rule
+}
+
+/**
+ * @Description: 创建字段错误 用于没有经过validator的校验
+ * @param field 字段名
+ * @param rule 校验规则 与validator的规则名一致时使用相同的提示
+ * @param param 规则参数
+ * @param kind 字段类型 决定min、max等规则的提示
+ * @return *FieldError
+ */
+func NewFieldError(field string, rule string, param string, kind reflect.Kind) *FieldError {
+	return &FieldError{Field: field, Rule: rule, Param: param, kind: kind}
+}
+
+/**
+ * @Description: 把参数绑定和校验错误转换为字段错误列表 无法识别的错误返回nil
+ * @param err ShouldBind返回的错误或*FieldError
+ * @param lang
+ * @return []FieldError
+ */
+func Details(err error, lang errcode.Lang) []FieldError {
+	var details []FieldError
+
+	var fieldErrs validator.ValidationErrors
+	var fieldErr *FieldError
+	var typeErr *json.UnmarshalTypeError
+	var syntaxErr *json.SyntaxError
+	switch {
+	case errors.As(err, &fieldErrs):
+		details = make([]FieldError, 0, len(fieldErrs))
+		for _, e := range fieldErrs {
+			details = append(details, FieldError{Field: e.Field(), Rule: e.Tag(), Param: e.Param(), kind: e.Kind()})
+		}
+	case errors.As(err, &fieldErr):
+		details = []FieldError{*fieldErr}
+	case errors.As(err, &typeErr):
+		details = []FieldError{{Field: typeErr.Field, Rule: RuleType, Param: typeErr.Type.String()}}
+	case errors.As(err, &syntaxErr), errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		details = []FieldError{{Field: FieldBody, Rule: RuleJSON}}
+	default:
+		return nil
+	}
+
+	for i := range details {
+		details[i].Message = message(&details[i], lang)
+	}
+	return details
+}
+
+// 按规则和字段类型生成提示
+func message(e *FieldError, lang errcode.Lang) string {
+	templates, ok := messages[e.Rule]
+	if !ok {
+		templates = messages[ruleDefault]
+	}
+	template := templates.pick(e.kind)[lang]
+	if template == "" {
+		template = templates.pick(e.kind)[errcode.LangZH]
+	}
+	param := e.Param
+	if e.Rule == "oneof" {
+		param = strings.ReplaceAll(param, " ", ", ")
+	}
+	return strings.NewReplacer("{field}", e.Field, "{param}", param, "{rule}", e.Rule).Replace(template)
+}
@@ -0,0 +1,65 @@
+package validation
+
+/**
+ * @Description: 校验规则的提示模板 {field}为字段名 {param}为规则参数 {rule}为规则名
+ */
+import (
+	"homework4/internal/errcode"
+	"reflect"
+)
+
+const (
+	RuleType    = "type"    // 字段类型不正确
+	RuleJSON    = "json"    // 请求体不是合法的JSON
+	RuleSchema  = "schema"  // 不符合接口文档 param为校验器的原始提示
+	ruleDefault = "default" // 没有单独提示的规则
+	FieldBody   = "body"    // 请求体整体出错时的字段名
+)
+
+type localized map[errcode.Lang]string
+
+// 同一规则按字段类型区分提示 字符串比较长度 数组比较个数 数字比较大小
+type ruleMessages struct {
+	text   localized // 字符串
+	items  localized // 数组 为空时使用text
+	number localized // 数字 为空时使用text
+}
+
+func (m ruleMessages) pick(kind reflect.Kind) localized {
+	switch kind {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		if m.items != nil {
+			return m.items
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		if m.number != nil {
+			return m.number
+		}
+	}
+	return m.text
+}
+
+var messages = map[string]ruleMessages{
+	"required": {text: localized{errcode.LangZH: "{field}不能为空", errcode.LangEN: "{field} is required"}},
+	"min": {
+		text:   localized{errcode.LangZH: "{field}长度不能少于{param}个字符", errcode.LangEN: "{field} must be at least {param} characters"},
+		items:  localized{errcode.LangZH: "{field}至少包含{param}项", errcode.LangEN: "{field} must contain at least {param} items"},
+		number: localized{errcode.LangZH: "{field}不能小于{param}", errcode.LangEN: "{field} must be at least {param}"},
+	},
+	"max": {
+		text:   localized{errcode.LangZH: "{field}长度不能超过{param}个字符", errcode.LangEN: "{field} must be at most {param} characters"},
+		items:  localized{errcode.LangZH: "{field}最多包含{param}项", errcode.LangEN: "{field} must contain at most {param} items"},
+		number: localized{errcode.LangZH: "{field}不能大于{param}", errcode.LangEN: "{field} must be at most {param}"},
+	},
+	"email":      {text: localized{errcode.LangZH: "{field}不是合法的邮箱地址", errcode.LangEN: "{field} must be a valid email address"}},
+	"oneof":      {text: localized{errcode.LangZH: "{field}只能是{param}之一", errcode.LangEN: "{field} must be one of {param}"}},
+	RuleUsername: {text: localized{errcode.LangZH: "{field}只能包含字母、数字和下划线", errcode.LangEN: "{field} may only contain letters, digits and underscores"}},
+	RulePassword: {text: localized{errcode.LangZH: "{field}必须同时包含字母和数字", errcode.LangEN: "{field} must contain both letters and digits"}},
+	RuleBanned:   {text: localized{errcode.LangZH: "{field}包含违禁词", errcode.LangEN: "{field} contains banned words"}},
+	RuleType:     {text: localized{errcode.LangZH: "{field}类型不正确 应为{param}", errcode.LangEN: "{field} must be of type {param}"}},
+	RuleJSON:     {text: localized{errcode.LangZH: "请求体不是合法的JSON", errcode.LangEN: "Request body is not valid JSON"}},
+	RuleSchema:   {text: localized{errcode.LangZH: "{field}不符合接口文档: {param}", errcode.LangEN: "{field} does not match the API specification: {param}"}},
+	ruleDefault:  {text: localized{errcode.LangZH: "{field}不满足校验规则{rule}", errcode.LangEN: "{field} failed the {rule} validation"}},
+}
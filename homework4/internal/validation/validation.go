@@ -0,0 +1,115 @@
+package validation
+
+/**
+ * @Description: 请求参数校验 注册gin使用的自定义校验规则 错误中的字段名使用json或form标签
+ * 违禁词由validation配置设置 配置重新加载后立即生效
+ */
+import (
+	"homework4/config"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"unicode"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+const (
+	RuleUsername = "username" // 用户名只能包含字母、数字和下划线
+	RulePassword = "password" // 密码必须同时包含字母和数字
+	RuleBanned   = "banned"   // 不能包含违禁词
+)
+
+var (
+	usernamePattern = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+	bannedWords  atomic.Pointer[[]string]
+	registerOnce sync.Once
+)
+
+/**
+ * @Description: 注册自定义校验规则并设置违禁词 重复调用只更新违禁词
+ * @param cfg
+ */
+func Setup(cfg config.ValidationConfig) {
+	registerOnce.Do(func() {
+		v, ok := binding.Validator.Engine().(*validator.Validate)
+		if !ok {
+			return
+		}
+		v.RegisterTagNameFunc(fieldName)
+		//规则名固定 注册失败说明代码有误
+		for rule, fn := range map[string]validator.Func{
+			RuleUsername: validateUsername,
+			RulePassword: validatePassword,
+			RuleBanned:   validateBanned,
+		} {
+			if err := v.RegisterValidation(rule, fn); err != nil {
+				panic(err)
+			}
+		}
+	})
+	Update(cfg)
+}
+
+/**
+ * @Description: 替换违禁词
+ * @param cfg
+ */
+func Update(cfg config.ValidationConfig) {
+	words := make([]string, 0, len(cfg.BannedWords))
+	for _, word := range cfg.BannedWords {
+		if word = strings.ToLower(strings.TrimSpace(word)); word != "" {
+			words = append(words, word)
+		}
+	}
+	bannedWords.Store(&words)
+}
+
+// 错误中的字段名 优先使用json标签 查询参数使用form标签 路径参数使用uri标签
+func fieldName(field reflect.StructField) string {
+	for _, tag := range []string{"json", "form", "uri"} {
+		name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+		if name == "-" {
+			return ""
+		}
+		if name != "" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+func validateUsername(fl validator.FieldLevel) bool {
+	return usernamePattern.MatchString(fl.Field().String())
+}
+
+func validatePassword(fl validator.FieldLevel) bool {
+	var hasLetter, hasDigit bool
+	for _, r := range fl.Field().String() {
+		switch {
+		case unicode.IsLetter(r):
+			hasLetter = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		}
+	}
+	return hasLetter && hasDigit
+}
+
+func validateBanned(fl validator.FieldLevel) bool {
+	words := bannedWords.Load()
+	if words == nil {
+		return true
+	}
+	value := strings.ToLower(fl.Field().String())
+	for _, word := range *words {
+		if strings.Contains(value, word) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,9 @@
+package migrations
+
+/**
+ * @Description: 数据库迁移文件 按驱动分目录 文件名为{版本}_{名称}.up.sql和{版本}_{名称}.down.sql
+ */
+import "embed"
+
+//go:embed mysql/*.sql postgres/*.sql sqlite/*.sql
+var FS embed.FS
@@ -0,0 +1,40 @@
+-- 初始表结构 和基线版本AutoMigrate生成的结构一致 已由AutoMigrate创建的表会跳过 之后的字段和索引由后续迁移修改
+CREATE TABLE IF NOT EXISTS `table_user` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `username` varchar(20) NOT NULL COMMENT '登录账号',
+  `nickname` varchar(64) NOT NULL COMMENT '昵称',
+  `password` varchar(64) NOT NULL COMMENT '登录密码(加密后的)',
+  `email` varchar(128) DEFAULT NULL COMMENT '邮箱',
+  `created_at` datetime(3) DEFAULT NULL,
+  `updated_at` datetime(3) DEFAULT NULL,
+  `deleted_at` bigint unsigned DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_username_deleted_at` (`username`, `deleted_at`)
+);
+
+CREATE TABLE IF NOT EXISTS `table_post` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `created_at` datetime(3) DEFAULT NULL,
+  `updated_at` datetime(3) DEFAULT NULL,
+  `deleted_at` datetime(3) DEFAULT NULL,
+  `user_id` bigint unsigned NOT NULL COMMENT '用户ID',
+  `title` varchar(20) NOT NULL COMMENT '标题',
+  `content` varchar(200) NOT NULL COMMENT '内容',
+  PRIMARY KEY (`id`),
+  KEY `idx_user_id` (`user_id`),
+  KEY `idx_table_post_deleted_at` (`deleted_at`)
+);
+
+CREATE TABLE IF NOT EXISTS `table_comment` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `created_at` datetime(3) DEFAULT NULL,
+  `updated_at` datetime(3) DEFAULT NULL,
+  `deleted_at` datetime(3) DEFAULT NULL,
+  `user_id` bigint unsigned NOT NULL COMMENT '用户ID',
+  `post_id` bigint unsigned NOT NULL COMMENT '文章ID',
+  `content` varchar(200) NOT NULL COMMENT '内容',
+  PRIMARY KEY (`id`),
+  KEY `idx_user_id` (`user_id`),
+  KEY `idx_post_id` (`post_id`),
+  KEY `idx_table_comment_deleted_at` (`deleted_at`)
+);
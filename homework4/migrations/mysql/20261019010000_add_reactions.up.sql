@@ -0,0 +1,14 @@
+-- 表情回应 文章和评论的点赞数 新表中没有回应 点赞数默认0即可
+CREATE TABLE `table_reaction` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `user_id` bigint unsigned NOT NULL COMMENT '用户ID',
+  `target_type` varchar(16) NOT NULL COMMENT '目标类型 post-文章 comment-评论',
+  `target_id` bigint unsigned NOT NULL COMMENT '目标ID',
+  `emoji` varchar(16) NOT NULL COMMENT '表情 like-点赞',
+  `created_at` datetime(3) DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_reaction_user_target` (`user_id`, `target_type`, `target_id`, `emoji`),
+  KEY `idx_reaction_target` (`target_type`, `target_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='表情回应表';
+ALTER TABLE `table_post` ADD COLUMN `like_count` bigint unsigned NOT NULL DEFAULT 0 COMMENT '点赞数';
+ALTER TABLE `table_comment` ADD COLUMN `like_count` bigint unsigned NOT NULL DEFAULT 0 COMMENT '点赞数';
@@ -0,0 +1,26 @@
+-- 阅读清单和收藏
+CREATE TABLE `table_reading_list` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `created_at` datetime(3) DEFAULT NULL,
+  `updated_at` datetime(3) DEFAULT NULL,
+  `deleted_at` datetime(3) DEFAULT NULL,
+  `user_id` bigint unsigned NOT NULL COMMENT '用户ID',
+  `name` varchar(32) NOT NULL COMMENT '清单名称',
+  `description` varchar(200) DEFAULT NULL COMMENT '清单描述',
+  `is_public` tinyint(1) NOT NULL DEFAULT 0 COMMENT '是否公开',
+  PRIMARY KEY (`id`),
+  KEY `idx_reading_list_user_id` (`user_id`),
+  KEY `idx_table_reading_list_deleted_at` (`deleted_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='阅读清单表';
+
+CREATE TABLE `table_bookmark` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `user_id` bigint unsigned NOT NULL COMMENT '用户ID',
+  `reading_list_id` bigint unsigned NOT NULL DEFAULT 0 COMMENT '阅读清单ID 0-稍后阅读',
+  `post_id` bigint unsigned NOT NULL COMMENT '文章ID',
+  `position` bigint NOT NULL DEFAULT 0 COMMENT '排序位置 越大越靠前',
+  `created_at` datetime(3) DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_bookmark_user_list_post` (`user_id`, `reading_list_id`, `post_id`),
+  KEY `idx_bookmark_post_id` (`post_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='收藏表';
@@ -0,0 +1,10 @@
+-- 用户关注
+CREATE TABLE `table_follow` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `follower_id` bigint unsigned NOT NULL COMMENT '关注者用户ID',
+  `followee_id` bigint unsigned NOT NULL COMMENT '被关注者用户ID',
+  `created_at` datetime(3) DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_follower_followee` (`follower_id`, `followee_id`),
+  KEY `idx_follow_followee_id` (`followee_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='关注表';
@@ -0,0 +1,6 @@
+ALTER TABLE `table_comment` COMMENT='';
+ALTER TABLE `table_post` COMMENT='';
+ALTER TABLE `table_user` COMMENT='';
+ALTER TABLE `table_user` MODIFY COLUMN `deleted_at` bigint unsigned DEFAULT NULL;
+ALTER TABLE `table_comment` RENAME INDEX `idx_comment_user_id` TO `idx_user_id`, RENAME INDEX `idx_comment_post_id` TO `idx_post_id`;
+ALTER TABLE `table_post` RENAME INDEX `idx_post_user_id` TO `idx_user_id`;
@@ -0,0 +1,10 @@
+-- 索引名加上表名前缀 postgres和sqlite的索引名在整个库中唯一 三种数据库使用相同的索引名
+-- 基线版本的idx_user_id、idx_post_id在这里改名 手动按新名称建过索引的库需要先删除重复的索引
+ALTER TABLE `table_post` RENAME INDEX `idx_user_id` TO `idx_post_user_id`;
+ALTER TABLE `table_comment` RENAME INDEX `idx_user_id` TO `idx_comment_user_id`, RENAME INDEX `idx_post_id` TO `idx_comment_post_id`;
+-- 基线版本的deleted_at允许NULL 改为和模型一致的NOT NULL DEFAULT 0 NULL不参与唯一索引会导致用户名可以重复
+UPDATE `table_user` SET `deleted_at` = 0 WHERE `deleted_at` IS NULL;
+ALTER TABLE `table_user` MODIFY COLUMN `deleted_at` bigint unsigned NOT NULL DEFAULT 0;
+ALTER TABLE `table_user` COMMENT='用户表';
+ALTER TABLE `table_post` COMMENT='文章表';
+ALTER TABLE `table_comment` COMMENT='评论表';
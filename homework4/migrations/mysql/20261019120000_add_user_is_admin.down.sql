@@ -0,0 +1 @@
+ALTER TABLE `table_user` DROP COLUMN `is_admin`;
@@ -0,0 +1 @@
+ALTER TABLE `table_user` ADD COLUMN `is_admin` tinyint(1) NOT NULL DEFAULT 0 COMMENT '是否管理员';
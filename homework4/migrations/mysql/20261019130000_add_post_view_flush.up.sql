@@ -0,0 +1,8 @@
+-- 每篇文章每天已刷入数据库的浏览量 和view_count在同一事务中更新 重复刷入不会多计
+CREATE TABLE `table_post_view_flush` (
+  `post_id` bigint unsigned NOT NULL COMMENT '文章ID',
+  `day` varchar(8) NOT NULL COMMENT '日期 yyyyMMdd',
+  `flushed_count` bigint NOT NULL DEFAULT 0 COMMENT '已刷入的浏览量',
+  PRIMARY KEY (`post_id`, `day`),
+  KEY `idx_post_view_flush_day` (`day`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='浏览量刷入记录表';
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS "table_comment";
+DROP TABLE IF EXISTS "table_post";
+DROP TABLE IF EXISTS "table_user";
@@ -0,0 +1,51 @@
+-- 初始表结构 和基线版本的用户、文章、评论表一致 已存在的表会跳过 之后的字段由后续迁移添加
+-- postgres的索引名在整个库中唯一 这里直接使用带表名前缀的索引名
+CREATE TABLE IF NOT EXISTS "table_user" (
+  "id" bigserial PRIMARY KEY,
+  "username" varchar(20) NOT NULL,
+  "nickname" varchar(64) NOT NULL,
+  "password" varchar(64) NOT NULL,
+  "email" varchar(128),
+  "created_at" timestamptz,
+  "updated_at" timestamptz,
+  "deleted_at" bigint NOT NULL DEFAULT 0
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_username_deleted_at" ON "table_user" ("username", "deleted_at");
+COMMENT ON TABLE "table_user" IS '用户表';
+COMMENT ON COLUMN "table_user"."username" IS '登录账号';
+COMMENT ON COLUMN "table_user"."nickname" IS '昵称';
+COMMENT ON COLUMN "table_user"."password" IS '登录密码(加密后的)';
+COMMENT ON COLUMN "table_user"."email" IS '邮箱';
+
+CREATE TABLE IF NOT EXISTS "table_post" (
+  "id" bigserial PRIMARY KEY,
+  "created_at" timestamptz,
+  "updated_at" timestamptz,
+  "deleted_at" timestamptz,
+  "user_id" bigint NOT NULL,
+  "title" varchar(20) NOT NULL,
+  "content" varchar(200) NOT NULL
+);
+CREATE INDEX IF NOT EXISTS "idx_post_user_id" ON "table_post" ("user_id");
+CREATE INDEX IF NOT EXISTS "idx_table_post_deleted_at" ON "table_post" ("deleted_at");
+COMMENT ON TABLE "table_post" IS '文章表';
+COMMENT ON COLUMN "table_post"."user_id" IS '用户ID';
+COMMENT ON COLUMN "table_post"."title" IS '标题';
+COMMENT ON COLUMN "table_post"."content" IS '内容';
+
+CREATE TABLE IF NOT EXISTS "table_comment" (
+  "id" bigserial PRIMARY KEY,
+  "created_at" timestamptz,
+  "updated_at" timestamptz,
+  "deleted_at" timestamptz,
+  "user_id" bigint NOT NULL,
+  "post_id" bigint NOT NULL,
+  "content" varchar(200) NOT NULL
+);
+CREATE INDEX IF NOT EXISTS "idx_comment_user_id" ON "table_comment" ("user_id");
+CREATE INDEX IF NOT EXISTS "idx_comment_post_id" ON "table_comment" ("post_id");
+CREATE INDEX IF NOT EXISTS "idx_table_comment_deleted_at" ON "table_comment" ("deleted_at");
+COMMENT ON TABLE "table_comment" IS '评论表';
+COMMENT ON COLUMN "table_comment"."user_id" IS '用户ID';
+COMMENT ON COLUMN "table_comment"."post_id" IS '文章ID';
+COMMENT ON COLUMN "table_comment"."content" IS '内容';
@@ -0,0 +1,3 @@
+ALTER TABLE "table_comment" DROP COLUMN "like_count";
+ALTER TABLE "table_post" DROP COLUMN "like_count";
+DROP TABLE IF EXISTS "table_reaction";
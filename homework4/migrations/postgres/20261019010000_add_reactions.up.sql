@@ -0,0 +1,20 @@
+-- 表情回应 文章和评论的点赞数 新表中没有回应 点赞数默认0即可
+CREATE TABLE "table_reaction" (
+  "id" bigserial PRIMARY KEY,
+  "user_id" bigint NOT NULL,
+  "target_type" varchar(16) NOT NULL,
+  "target_id" bigint NOT NULL,
+  "emoji" varchar(16) NOT NULL,
+  "created_at" timestamptz
+);
+CREATE UNIQUE INDEX "idx_reaction_user_target" ON "table_reaction" ("user_id", "target_type", "target_id", "emoji");
+CREATE INDEX "idx_reaction_target" ON "table_reaction" ("target_type", "target_id");
+COMMENT ON TABLE "table_reaction" IS '表情回应表';
+COMMENT ON COLUMN "table_reaction"."user_id" IS '用户ID';
+COMMENT ON COLUMN "table_reaction"."target_type" IS '目标类型 post-文章 comment-评论';
+COMMENT ON COLUMN "table_reaction"."target_id" IS '目标ID';
+COMMENT ON COLUMN "table_reaction"."emoji" IS '表情 like-点赞';
+ALTER TABLE "table_post" ADD COLUMN "like_count" bigint NOT NULL DEFAULT 0;
+COMMENT ON COLUMN "table_post"."like_count" IS '点赞数';
+ALTER TABLE "table_comment" ADD COLUMN "like_count" bigint NOT NULL DEFAULT 0;
+COMMENT ON COLUMN "table_comment"."like_count" IS '点赞数';
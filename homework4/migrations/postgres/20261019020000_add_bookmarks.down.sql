@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS "table_bookmark";
+DROP TABLE IF EXISTS "table_reading_list";
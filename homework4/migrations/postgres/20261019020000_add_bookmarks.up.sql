@@ -0,0 +1,34 @@
+-- 阅读清单和收藏
+CREATE TABLE "table_reading_list" (
+  "id" bigserial PRIMARY KEY,
+  "created_at" timestamptz,
+  "updated_at" timestamptz,
+  "deleted_at" timestamptz,
+  "user_id" bigint NOT NULL,
+  "name" varchar(32) NOT NULL,
+  "description" varchar(200),
+  "is_public" boolean NOT NULL DEFAULT false
+);
+CREATE INDEX "idx_reading_list_user_id" ON "table_reading_list" ("user_id");
+CREATE INDEX "idx_table_reading_list_deleted_at" ON "table_reading_list" ("deleted_at");
+COMMENT ON TABLE "table_reading_list" IS '阅读清单表';
+COMMENT ON COLUMN "table_reading_list"."user_id" IS '用户ID';
+COMMENT ON COLUMN "table_reading_list"."name" IS '清单名称';
+COMMENT ON COLUMN "table_reading_list"."description" IS '清单描述';
+COMMENT ON COLUMN "table_reading_list"."is_public" IS '是否公开';
+
+CREATE TABLE "table_bookmark" (
+  "id" bigserial PRIMARY KEY,
+  "user_id" bigint NOT NULL,
+  "reading_list_id" bigint NOT NULL DEFAULT 0,
+  "post_id" bigint NOT NULL,
+  "position" bigint NOT NULL DEFAULT 0,
+  "created_at" timestamptz
+);
+CREATE UNIQUE INDEX "idx_bookmark_user_list_post" ON "table_bookmark" ("user_id", "reading_list_id", "post_id");
+CREATE INDEX "idx_bookmark_post_id" ON "table_bookmark" ("post_id");
+COMMENT ON TABLE "table_bookmark" IS '收藏表';
+COMMENT ON COLUMN "table_bookmark"."user_id" IS '用户ID';
+COMMENT ON COLUMN "table_bookmark"."reading_list_id" IS '阅读清单ID 0-稍后阅读';
+COMMENT ON COLUMN "table_bookmark"."post_id" IS '文章ID';
+COMMENT ON COLUMN "table_bookmark"."position" IS '排序位置 越大越靠前';
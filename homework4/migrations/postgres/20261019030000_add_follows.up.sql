@@ -0,0 +1,12 @@
+-- 用户关注
+CREATE TABLE "table_follow" (
+  "id" bigserial PRIMARY KEY,
+  "follower_id" bigint NOT NULL,
+  "followee_id" bigint NOT NULL,
+  "created_at" timestamptz
+);
+CREATE UNIQUE INDEX "idx_follower_followee" ON "table_follow" ("follower_id", "followee_id");
+CREATE INDEX "idx_follow_followee_id" ON "table_follow" ("followee_id");
+COMMENT ON TABLE "table_follow" IS '关注表';
+COMMENT ON COLUMN "table_follow"."follower_id" IS '关注者用户ID';
+COMMENT ON COLUMN "table_follow"."followee_id" IS '被关注者用户ID';
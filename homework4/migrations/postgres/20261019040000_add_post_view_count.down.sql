@@ -0,0 +1 @@
+ALTER TABLE "table_post" DROP COLUMN "view_count";
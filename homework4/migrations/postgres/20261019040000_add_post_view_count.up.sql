@@ -0,0 +1,3 @@
+-- 文章浏览量 之前没有记录浏览 从0开始
+ALTER TABLE "table_post" ADD COLUMN "view_count" bigint NOT NULL DEFAULT 0;
+COMMENT ON COLUMN "table_post"."view_count" IS '浏览量';
@@ -0,0 +1,2 @@
+ALTER TABLE "table_user" DROP COLUMN "post_count";
+ALTER TABLE "table_post" DROP COLUMN "comment_count";
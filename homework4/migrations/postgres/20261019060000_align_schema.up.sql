@@ -0,0 +1 @@
+-- mysql在这里把基线版本的索引改名并修改deleted_at 表注释 postgres的初始迁移已经和模型一致 不需要修改
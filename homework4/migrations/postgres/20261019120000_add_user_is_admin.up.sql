@@ -0,0 +1,2 @@
+ALTER TABLE "table_user" ADD COLUMN "is_admin" boolean NOT NULL DEFAULT false;
+COMMENT ON COLUMN "table_user"."is_admin" IS '是否管理员';
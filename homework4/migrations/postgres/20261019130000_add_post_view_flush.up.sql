@@ -0,0 +1,12 @@
+-- 每篇文章每天已刷入数据库的浏览量 和view_count在同一事务中更新 重复刷入不会多计
+CREATE TABLE "table_post_view_flush" (
+  "post_id" bigint NOT NULL,
+  "day" varchar(8) NOT NULL,
+  "flushed_count" bigint NOT NULL DEFAULT 0,
+  PRIMARY KEY ("post_id", "day")
+);
+CREATE INDEX "idx_post_view_flush_day" ON "table_post_view_flush" ("day");
+COMMENT ON TABLE "table_post_view_flush" IS '浏览量刷入记录表';
+COMMENT ON COLUMN "table_post_view_flush"."post_id" IS '文章ID';
+COMMENT ON COLUMN "table_post_view_flush"."day" IS '日期 yyyyMMdd';
+COMMENT ON COLUMN "table_post_view_flush"."flushed_count" IS '已刷入的浏览量';
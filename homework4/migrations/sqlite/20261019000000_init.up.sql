@@ -0,0 +1,13 @@
+-- 初始表结构 和基线版本的用户、文章、评论表一致 已存在的表会跳过 之后的字段由后续迁移添加
+-- sqlite的索引名在整个库中唯一 这里直接使用带表名前缀的索引名 sqlite不支持表和字段注释
+CREATE TABLE IF NOT EXISTS `table_user` (`id` integer PRIMARY KEY AUTOINCREMENT,`username` text NOT NULL,`nickname` text NOT NULL,`password` text NOT NULL,`email` text,`created_at` datetime,`updated_at` datetime,`deleted_at` integer NOT NULL DEFAULT 0);
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_username_deleted_at` ON `table_user`(`username`,`deleted_at`);
+
+CREATE TABLE IF NOT EXISTS `table_post` (`id` integer PRIMARY KEY AUTOINCREMENT,`created_at` datetime,`updated_at` datetime,`deleted_at` datetime,`user_id` integer NOT NULL,`title` text NOT NULL,`content` text NOT NULL);
+CREATE INDEX IF NOT EXISTS `idx_post_user_id` ON `table_post`(`user_id`);
+CREATE INDEX IF NOT EXISTS `idx_table_post_deleted_at` ON `table_post`(`deleted_at`);
+
+CREATE TABLE IF NOT EXISTS `table_comment` (`id` integer PRIMARY KEY AUTOINCREMENT,`created_at` datetime,`updated_at` datetime,`deleted_at` datetime,`user_id` integer NOT NULL,`post_id` integer NOT NULL,`content` text NOT NULL);
+CREATE INDEX IF NOT EXISTS `idx_comment_user_id` ON `table_comment`(`user_id`);
+CREATE INDEX IF NOT EXISTS `idx_comment_post_id` ON `table_comment`(`post_id`);
+CREATE INDEX IF NOT EXISTS `idx_table_comment_deleted_at` ON `table_comment`(`deleted_at`);
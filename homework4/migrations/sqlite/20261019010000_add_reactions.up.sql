@@ -0,0 +1,6 @@
+-- 表情回应 文章和评论的点赞数 新表中没有回应 点赞数默认0即可
+CREATE TABLE `table_reaction` (`id` integer PRIMARY KEY AUTOINCREMENT,`user_id` integer NOT NULL,`target_type` text NOT NULL,`target_id` integer NOT NULL,`emoji` text NOT NULL,`created_at` datetime);
+CREATE UNIQUE INDEX `idx_reaction_user_target` ON `table_reaction`(`user_id`,`target_type`,`target_id`,`emoji`);
+CREATE INDEX `idx_reaction_target` ON `table_reaction`(`target_type`,`target_id`);
+ALTER TABLE `table_post` ADD COLUMN `like_count` integer NOT NULL DEFAULT 0;
+ALTER TABLE `table_comment` ADD COLUMN `like_count` integer NOT NULL DEFAULT 0;
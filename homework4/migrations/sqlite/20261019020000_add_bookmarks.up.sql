@@ -0,0 +1,8 @@
+-- 阅读清单和收藏
+CREATE TABLE `table_reading_list` (`id` integer PRIMARY KEY AUTOINCREMENT,`created_at` datetime,`updated_at` datetime,`deleted_at` datetime,`user_id` integer NOT NULL,`name` text NOT NULL,`description` text,`is_public` numeric NOT NULL DEFAULT false);
+CREATE INDEX `idx_reading_list_user_id` ON `table_reading_list`(`user_id`);
+CREATE INDEX `idx_table_reading_list_deleted_at` ON `table_reading_list`(`deleted_at`);
+
+CREATE TABLE `table_bookmark` (`id` integer PRIMARY KEY AUTOINCREMENT,`user_id` integer NOT NULL,`reading_list_id` integer NOT NULL DEFAULT 0,`post_id` integer NOT NULL,`position` integer NOT NULL DEFAULT 0,`created_at` datetime);
+CREATE UNIQUE INDEX `idx_bookmark_user_list_post` ON `table_bookmark`(`user_id`,`reading_list_id`,`post_id`);
+CREATE INDEX `idx_bookmark_post_id` ON `table_bookmark`(`post_id`);
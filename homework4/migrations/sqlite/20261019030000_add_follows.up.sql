@@ -0,0 +1,4 @@
+-- 用户关注
+CREATE TABLE `table_follow` (`id` integer PRIMARY KEY AUTOINCREMENT,`follower_id` integer NOT NULL,`followee_id` integer NOT NULL,`created_at` datetime);
+CREATE UNIQUE INDEX `idx_follower_followee` ON `table_follow`(`follower_id`,`followee_id`);
+CREATE INDEX `idx_follow_followee_id` ON `table_follow`(`followee_id`);
@@ -0,0 +1,2 @@
+-- 文章浏览量 之前没有记录浏览 从0开始
+ALTER TABLE `table_post` ADD COLUMN `view_count` integer NOT NULL DEFAULT 0;
@@ -0,0 +1,6 @@
+-- 文章评论数和用户文章数
+ALTER TABLE `table_post` ADD COLUMN `comment_count` integer NOT NULL DEFAULT 0;
+ALTER TABLE `table_user` ADD COLUMN `post_count` integer NOT NULL DEFAULT 0;
+-- 按已有数据回填 之后由评论和文章的增删维护
+UPDATE `table_post` SET `comment_count` = (SELECT COUNT(*) FROM `table_comment` WHERE `table_comment`.`post_id` = `table_post`.`id` AND `table_comment`.`deleted_at` IS NULL);
+UPDATE `table_user` SET `post_count` = (SELECT COUNT(*) FROM `table_post` WHERE `table_post`.`user_id` = `table_user`.`id` AND `table_post`.`deleted_at` IS NULL);
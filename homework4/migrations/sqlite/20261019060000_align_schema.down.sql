@@ -0,0 +1 @@
+-- 对应的up迁移没有修改
@@ -0,0 +1 @@
+ALTER TABLE `table_user` ADD COLUMN `is_admin` numeric NOT NULL DEFAULT false;
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS `table_post_view_flush`;
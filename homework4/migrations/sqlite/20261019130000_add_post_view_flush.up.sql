@@ -0,0 +1,3 @@
+-- 每篇文章每天已刷入数据库的浏览量 和view_count在同一事务中更新 重复刷入不会多计
+CREATE TABLE `table_post_view_flush` (`post_id` integer NOT NULL,`day` text NOT NULL,`flushed_count` integer NOT NULL DEFAULT 0,PRIMARY KEY (`post_id`,`day`));
+CREATE INDEX `idx_post_view_flush_day` ON `table_post_view_flush`(`day`);
@@ -1,11 +1,13 @@
 package logger
 
 import (
+	"context"
 	"io"
 	"os"
 	"path/filepath"
 	"time"
 
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 	"gopkg.in/natefinch/lumberjack.v2"
@@ -28,6 +30,7 @@ type option struct {
 	file           io.Writer
 	timeLayout     string
 	disableConsole bool
+	atomicLevel    *zap.AtomicLevel // 不为空时使用可动态修改的日志级别
 }
 
 // WithDebugLevel 设置日志级别为Debug，只输出Debug及以上级别的日志
@@ -115,10 +118,30 @@ func WithDisableConsole() Option {
 	}
 }
 
+// WithAtomicLevel 使用可动态修改的日志级别 初始级别为其它选项设置的级别
+func WithAtomicLevel(level zap.AtomicLevel) Option {
+	return func(opt *option) {
+		opt.atomicLevel = &level
+	}
+}
+
 var AppLog *zap.Logger
 
+// AppLevel AppLog的日志级别 运行时可通过SetLevel修改
+var AppLevel = zap.NewAtomicLevelAt(DefaultLevel)
+
 func InitAppLog(opts ...Option) {
-	AppLog, _ = NewJSONLogger(opts...)
+	AppLog, _ = NewJSONLogger(append([]Option{WithAtomicLevel(AppLevel)}, opts...)...)
+}
+
+// SetLevel 修改AppLog的日志级别 支持debug/info/warn/error
+func SetLevel(level string) error {
+	var lvl zapcore.Level
+	if err := lvl.UnmarshalText([]byte(level)); err != nil {
+		return err
+	}
+	AppLevel.SetLevel(lvl)
+	return nil
 }
 
 // NewJSONLogger 返回一个使用JSON编码器的zap日志记录器
@@ -152,14 +175,20 @@ func NewJSONLogger(opts ...Option) (*zap.Logger, error) {
 
 	jsonEncoder := zapcore.NewJSONEncoder(encoderConfig)
 
+	var enabler zapcore.LevelEnabler = opt.level
+	if opt.atomicLevel != nil {
+		opt.atomicLevel.SetLevel(opt.level)
+		enabler = opt.atomicLevel
+	}
+
 	// lowPriority 用于 info\debug\warn 级别
 	lowPriority := zap.LevelEnablerFunc(func(lvl zapcore.Level) bool {
-		return lvl >= opt.level && lvl < zapcore.ErrorLevel
+		return enabler.Enabled(lvl) && lvl < zapcore.ErrorLevel
 	})
 
 	// highPriority 用于 error\panic\fatal 级别
 	highPriority := zap.LevelEnablerFunc(func(lvl zapcore.Level) bool {
-		return lvl >= opt.level && lvl >= zapcore.ErrorLevel
+		return enabler.Enabled(lvl) && lvl >= zapcore.ErrorLevel
 	})
 
 	stdout := zapcore.Lock(os.Stdout) // 加锁以保证并发安全
@@ -184,9 +213,7 @@ func NewJSONLogger(opts ...Option) (*zap.Logger, error) {
 		core = zapcore.NewTee(core,
 			zapcore.NewCore(jsonEncoder,
 				zapcore.AddSync(opt.file),
-				zap.LevelEnablerFunc(func(lvl zapcore.Level) bool {
-					return lvl >= opt.level
-				}),
+				enabler,
 			),
 		)
 	}
@@ -250,3 +277,50 @@ func WrapMeta(err error, metas ...Meta) (fields []zap.Field) {
 
 	return
 }
+
+type contextKey struct{}
+
+/**
+ * @Description: 将请求级别的日志记录器放入上下文 之后通过FromContext取出
+ * @param ctx
+ * @param logger
+ * @return context.Context
+ */
+func NewContext(ctx context.Context, logger *zap.Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, logger)
+}
+
+/**
+ * @Description: 在上下文的日志记录器上追加字段 例如登录后追加user_id
+ * @param ctx
+ * @param fields
+ * @return context.Context
+ */
+func With(ctx context.Context, fields ...zap.Field) context.Context {
+	return NewContext(ctx, base(ctx).With(fields...))
+}
+
+// 上下文中的日志记录器 没有时使用AppLog
+func base(ctx context.Context) *zap.Logger {
+	if logger, ok := ctx.Value(contextKey{}).(*zap.Logger); ok {
+		return logger
+	}
+	return AppLog
+}
+
+/**
+ * @Description: 请求上下文中的日志记录器 带上request_id等请求字段 有链路追踪时带上trace_id和span_id
+ * @param ctx
+ * @return *zap.Logger
+ */
+func FromContext(ctx context.Context) *zap.Logger {
+	logger := base(ctx)
+	spanCtx := trace.SpanContextFromContext(ctx)
+	if !spanCtx.IsValid() {
+		return logger
+	}
+	return logger.With(
+		zap.String("trace_id", spanCtx.TraceID().String()),
+		zap.String("span_id", spanCtx.SpanID().String()),
+	)
+}